@@ -2,98 +2,450 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
-	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+
+	// Secret JWT default, hanya boleh dipakai di development
+	devJWTSecret = "secrect-key-rahasia"
+	redacted     = "[REDACTED]"
+)
+
+// Config satu-satunya sumber konfigurasi aplikasi.
+// Urutan prioritas: default < file (CONFIG_FILE, YAML/TOML) < environment variable.
 type Config struct {
-	DBHost      string
-	DBPort      int
-	DBUser      string
-	DBPassword  string
-	DBName      string
-	ServerPort  string
-	Environment string
-	DB          *gorm.DB
-}
-
-func LoadConfig() *Config {
-	_ = godotenv.Load(".env." + getEnv("GO_ENV", "development"))
+	Environment string          `json:"environment"`
+	Server      ServerConfig    `json:"server"`
+	Database    DatabaseConfig  `json:"database"`
+	Auth        AuthConfig      `json:"auth"`
+	CORS        CORSConfig      `json:"cors"`
+	RateLimit   RateLimitConfig `json:"rate_limit"`
+	Log         LogConfig       `json:"log"`
+	Metrics     MetricsConfig   `json:"metrics"`
+	Health      HealthConfig    `json:"health"`
+	Storage     StorageConfig   `json:"storage"`
+	Loans       LoanConfig      `json:"loans"`
+}
+
+type ServerConfig struct {
+	Port              int      `json:"port"`
+	ReadTimeout       Duration `json:"read_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout"`
+	WriteTimeout      Duration `json:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout"`
+	// Batas waktu drain request in-flight saat SIGTERM/SIGINT
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// TrustedProxies IP/CIDR reverse proxy yang header X-Forwarded-For-nya dipercaya untuk IP
+	// client (rate limit per IP, log). Kosong = IP koneksi langsung yang dipakai.
+	TrustedProxies []string `json:"trusted_proxies"`
+}
+
+type DatabaseConfig struct {
+	Host            string   `json:"host"`
+	Port            int      `json:"port"`
+	User            string   `json:"user"`
+	Password        string   `json:"password"`
+	Name            string   `json:"name"`
+	SSLMode         string   `json:"ssl_mode"`
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+	// Query lebih lama dari ini di-log sebagai slow query
+	SlowQueryThreshold Duration `json:"slow_query_threshold"`
+}
+
+type AuthConfig struct {
+	JWTSecret     string   `json:"jwt_secret"`
+	TokenDuration Duration `json:"token_duration"`
+	Issuer        string   `json:"issuer"`
+	// Masa berlaku personal API token kalau tidak diminta, dan batas atasnya
+	APITokenDefaultTTL Duration `json:"api_token_default_ttl"`
+	APITokenMaxTTL     Duration `json:"api_token_max_ttl"`
+	// LocalLoginEnabled false = login username/password ditolak, hanya SSO
+	LocalLoginEnabled bool            `json:"local_login_enabled"`
+	OIDC              OIDCConfig      `json:"oidc"`
+	LDAP              LDAPConfig      `json:"ldap"`
+	TwoFactor         TwoFactorConfig `json:"two_factor"`
+}
+
+// TwoFactorConfig TOTP untuk login password (lokal & LDAP). Akun OIDC mengikuti MFA di IdP.
+type TwoFactorConfig struct {
+	// Issuer nama yang tampil di aplikasi authenticator
+	Issuer string `json:"issuer"`
+	// RequiredRoles role yang wajib enrolment 2FA, mis. ["admin"]
+	RequiredRoles []string `json:"required_roles"`
+	// EncryptionKey kunci enkripsi secret TOTP di database. Default JWT secret,
+	// tapi set terpisah supaya rotasi JWT secret tidak mematikan semua enrolment.
+	EncryptionKey string   `json:"encryption_key"`
+	ChallengeTTL  Duration `json:"challenge_ttl"`
+}
+
+// OIDCConfig login SSO authorization code + PKCE
+type OIDCConfig struct {
+	Enabled      bool   `json:"enabled"`
+	IssuerURL    string `json:"issuer_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	// RedirectURL callback API yang didaftarkan di IdP, mis. https://api.example.com/api/auth/oidc/callback
+	RedirectURL string   `json:"redirect_url"`
+	Scopes      []string `json:"scopes"`
+	GroupsClaim string   `json:"groups_claim"`
+	// PostLoginRedirect URL frontend tujuan setelah login, token dikirim di fragment (#token=...).
+	// Kosong = callback menjawab JSON seperti /api/auth/login.
+	PostLoginRedirect string             `json:"post_login_redirect"`
+	Groups            GroupMappingConfig `json:"groups"`
+	// StateKey kunci HMAC cookie state login. Kosong = diturunkan dari JWT secret (HKDF),
+	// jadi tidak pernah sama dengan kunci tanda tangan token.
+	StateKey string `json:"state_key"`
+}
+
+// LDAPConfig login username/password lewat bind ke LDAP / Active Directory.
+// Dicoba setelah user lokal kalau local login juga aktif.
+type LDAPConfig struct {
+	Enabled bool `json:"enabled"`
+	// URL ldap://host:389 atau ldaps://host:636
+	URL                string `json:"url"`
+	StartTLS           bool   `json:"start_tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	BindDN             string `json:"bind_dn"`
+	BindPassword       string `json:"bind_password"`
+	BaseDN             string `json:"base_dn"`
+	// UserFilter %s diganti username, mis. (uid=%s) atau (sAMAccountName=%s) untuk AD
+	UserFilter string `json:"user_filter"`
+	// GroupBaseDN & GroupFilter opsional untuk direktori tanpa memberOf, %s diganti DN user
+	GroupBaseDN string             `json:"group_base_dn"`
+	GroupFilter string             `json:"group_filter"`
+	Attributes  LDAPAttributes     `json:"attributes"`
+	Groups      GroupMappingConfig `json:"groups"`
+	Timeout     Duration           `json:"timeout"`
+}
+
+// LDAPAttributes nama atribut direktori yang dipetakan ke user lokal
+type LDAPAttributes struct {
+	// ID atribut yang stabil walau user dipindah OU: entryUUID (OpenLDAP) atau objectGUID (AD)
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	FullName   string `json:"full_name"`
+	Department string `json:"department"`
+	Groups     string `json:"groups"`
+}
+
+// GroupMappingConfig mapping nama group IdP ke role (admin/technician/user) dan department
+type GroupMappingConfig struct {
+	Roles       map[string]string `json:"roles"`
+	Departments map[string]string `json:"departments"`
+	DefaultRole string            `json:"default_role"`
+}
+
+// CORSConfig kebijakan CORS. AllowOrigins mendukung "*", wildcard subdomain
+// ("https://*.example.com") dan wildcard port ("http://localhost:*").
+// Origins dan credentials yang tidak di-set mengikuti default per environment.
+type CORSConfig struct {
+	AllowOrigins     []string `json:"allow_origins"`
+	AllowMethods     []string `json:"allow_methods"`
+	AllowHeaders     []string `json:"allow_headers"`
+	ExposeHeaders    []string `json:"expose_headers"`
+	AllowCredentials *bool    `json:"allow_credentials"`
+	MaxAge           Duration `json:"max_age"`
+}
+
+// Nama route group yang dipasangi rate limit
+const (
+	RateLimitGroupAuth   = "auth"   // login, per IP
+	RateLimitGroupSearch = "search" // endpoint search, di atas limit api
+	RateLimitGroupAPI    = "api"    // semua route terproteksi
+)
+
+type RateLimitConfig struct {
+	Enabled bool                      `json:"enabled"`
+	Groups  map[string]RateLimitGroup `json:"groups"`
+}
+
+// RateLimitRule token bucket: burst request sekaligus, diisi ulang requests_per_minute.
+// requests_per_minute 0 berarti tanpa limit.
+type RateLimitRule struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
+}
+
+// RateLimitGroup limit default group plus override per role
+type RateLimitGroup struct {
+	RateLimitRule
+	Roles map[string]RateLimitRule `json:"roles,omitempty"`
+}
+
+type LogConfig struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type MetricsConfig struct {
+	// Interval refresh gauge domain (loan aktif, overdue, stok)
+	RefreshInterval Duration `json:"refresh_interval"`
+}
+
+type HealthConfig struct {
+	// Timeout per komponen readiness check
+	CheckTimeout Duration `json:"check_timeout"`
+}
+
+// Driver storage file upload
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// StorageConfig penyimpanan foto & dokumen toolkit
+type StorageConfig struct {
+	// Driver local (filesystem) atau s3 (S3-compatible, termasuk MinIO)
+	Driver string             `json:"driver"`
+	Local  LocalStorageConfig `json:"local"`
+	S3     S3StorageConfig    `json:"s3"`
+	// Batas ukuran file per jenis upload dalam MB
+	MaxPhotoSizeMB    int `json:"max_photo_size_mb"`
+	MaxDocumentSizeMB int `json:"max_document_size_mb"`
+	// ThumbnailSize sisi terpanjang thumbnail dalam pixel
+	ThumbnailSize int `json:"thumbnail_size"`
+}
+
+type LocalStorageConfig struct {
+	Dir string `json:"dir"`
+}
+
+type S3StorageConfig struct {
+	// Endpoint host[:port] tanpa skema, mis. s3.amazonaws.com atau minio:9000
+	Endpoint  string `json:"endpoint"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	Region    string `json:"region"`
+	UseSSL    bool   `json:"use_ssl"`
+}
+
+// LoanConfig kebijakan peminjaman: default checkout lewat scanner dan inspeksi wajib
+type LoanConfig struct {
+	// DefaultDuration lama pinjam kalau due date tidak diisi
+	DefaultDuration Duration `json:"default_duration"`
+	// RoleDurations override lama pinjam per role peminjam, mis. {"technician": "336h"}
+	RoleDurations map[string]Duration `json:"role_durations"`
+	// MaxActiveLoans batas loan aktif per peminjam, 0 = tanpa batas
+	MaxActiveLoans int `json:"max_active_loans"`
+	// BlockOverdue tolak checkout kalau peminjam masih punya loan yang lewat due date
+	BlockOverdue bool `json:"block_overdue"`
+	// RequireInspection loan hanya bisa dikembalikan setelah inspeksi checkout dicatat, dan
+	// toolkit tidak bisa dipinjam lagi selama ada pengembalian yang belum diinspeksi
+	RequireInspection bool `json:"require_inspection"`
+}
+
+// LoadConfig membaca .env, file konfigurasi opsional dan environment variable,
+// lalu memvalidasi hasilnya. Semua masalah dikembalikan sekaligus.
+func LoadConfig() (*Config, error) {
+	_ = godotenv.Load(".env." + getEnv("GO_ENV", EnvDevelopment))
 	_ = godotenv.Load()
 
-	env := getEnv("GO_ENV", "development")
+	cfg := defaults()
 
-	config := &Config{
-		DBHost:      getEnv("DB_HOST", "localhost"),
-		DBPort:      getEnvAsInt("DB_PORT", 5432),
-		DBUser:      getEnv("DB_USER", "postgres"),
-		DBPassword:  getEnv("DB_PASSWORD", "root"),
-		DBName:      getEnv("DB_NAME", "toolkit_db"),
-		ServerPort:  getEnv("SERVER_PORT", "8080"),
-		Environment: env,
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := config.InitDB(); err != nil {
-		panic(fmt.Sprintf("Failed to initialize database: %v", err))
+	if err := loadEnv(cfg); err != nil {
+		return nil, err
 	}
 
-	return config
+	cfg.applyEnvironmentDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func (c *Config) GetDBConnectionString() string {
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=UTC",
-		c.DBHost, c.DBUser, c.DBPassword, c.DBName, c.DBPort)
+func defaults() *Config {
+	return &Config{
+		Environment: EnvDevelopment,
+		Server: ServerConfig{
+			Port:              8080,
+			ReadTimeout:       Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(60 * time.Second),
+			ShutdownTimeout:   Duration(20 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               5432,
+			User:               "postgres",
+			Password:           "root",
+			Name:               "toolkit_db",
+			SSLMode:            "disable",
+			MaxOpenConns:       25,
+			MaxIdleConns:       10,
+			ConnMaxLifetime:    Duration(30 * time.Minute),
+			ConnMaxIdleTime:    Duration(5 * time.Minute),
+			SlowQueryThreshold: Duration(200 * time.Millisecond),
+		},
+		Auth: AuthConfig{
+			TokenDuration:      Duration(24 * time.Hour),
+			Issuer:             "toolkit-management",
+			APITokenDefaultTTL: Duration(90 * 24 * time.Hour),
+			APITokenMaxTTL:     Duration(365 * 24 * time.Hour),
+			LocalLoginEnabled:  true,
+			OIDC: OIDCConfig{
+				Scopes:      []string{"openid", "profile", "email"},
+				GroupsClaim: "groups",
+				Groups:      GroupMappingConfig{DefaultRole: "user"},
+			},
+			LDAP: LDAPConfig{
+				UserFilter: "(uid=%s)",
+				Attributes: LDAPAttributes{
+					ID:       "entryUUID",
+					Username: "uid",
+					Email:    "mail",
+					FullName: "cn",
+					Groups:   "memberOf",
+				},
+				Groups:  GroupMappingConfig{DefaultRole: "user"},
+				Timeout: Duration(10 * time.Second),
+			},
+			TwoFactor: TwoFactorConfig{
+				Issuer:       "Toolkit Management",
+				ChallengeTTL: Duration(10 * time.Minute),
+			},
+		},
+		CORS: CORSConfig{
+			AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+			AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
+			ExposeHeaders: []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			MaxAge:        Duration(12 * time.Hour),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Groups: map[string]RateLimitGroup{
+				RateLimitGroupAuth: {
+					RateLimitRule: RateLimitRule{RequestsPerMinute: 10, Burst: 5},
+				},
+				RateLimitGroupSearch: {
+					RateLimitRule: RateLimitRule{RequestsPerMinute: 60, Burst: 20},
+					Roles:         map[string]RateLimitRule{"admin": {RequestsPerMinute: 240, Burst: 60}},
+				},
+				RateLimitGroupAPI: {
+					RateLimitRule: RateLimitRule{RequestsPerMinute: 300, Burst: 100},
+					Roles:         map[string]RateLimitRule{"admin": {RequestsPerMinute: 1200, Burst: 300}},
+				},
+			},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Metrics: MetricsConfig{
+			RefreshInterval: Duration(30 * time.Second),
+		},
+		Health: HealthConfig{
+			CheckTimeout: Duration(2 * time.Second),
+		},
+		Storage: StorageConfig{
+			Driver:            StorageDriverLocal,
+			Local:             LocalStorageConfig{Dir: "uploads"},
+			S3:                S3StorageConfig{Region: "us-east-1", UseSSL: true},
+			MaxPhotoSizeMB:    10,
+			MaxDocumentSizeMB: 25,
+			ThumbnailSize:     320,
+		},
+		Loans: LoanConfig{
+			DefaultDuration: Duration(7 * 24 * time.Hour),
+			BlockOverdue:    true,
+		},
+	}
+}
+
+// applyEnvironmentDefaults default yang tergantung environment, dipanggil setelah file & env dibaca
+func (c *Config) applyEnvironmentDefaults() {
+	if c.IsDevelopment() && c.Auth.JWTSecret == "" {
+		c.Auth.JWTSecret = devJWTSecret
+	}
+	if c.Auth.TwoFactor.EncryptionKey == "" {
+		c.Auth.TwoFactor.EncryptionKey = c.Auth.JWTSecret
+	}
+
+	// Development: frontend lokal di port berapa pun. Staging/production: tidak ada
+	// origin lintas domain sampai di-set eksplisit (CORS_ALLOW_ORIGINS).
+	if c.CORS.AllowOrigins == nil {
+		c.CORS.AllowOrigins = []string{}
+		if c.IsDevelopment() {
+			c.CORS.AllowOrigins = []string{"http://localhost:*", "http://127.0.0.1:*"}
+		}
+	}
+	if c.CORS.AllowCredentials == nil {
+		credentials := c.IsDevelopment()
+		c.CORS.AllowCredentials = &credentials
+	}
 }
 
 func (c *Config) IsDevelopment() bool {
-	return c.Environment == "development"
+	return c.Environment == EnvDevelopment
 }
 
 func (c *Config) IsStaging() bool {
-	return c.Environment == "staging"
+	return c.Environment == EnvStaging
 }
 
 func (c *Config) IsProduction() bool {
-	return c.Environment == "production"
+	return c.Environment == EnvProduction
 }
 
-func (c *Config) InitDB() error {
-	dsn := c.GetDBConnectionString()
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return err
-	}
-	c.DB = db
-	return nil
+// Addr alamat listen HTTP server
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
 }
 
-func (c *Config) CloseDB() error {
-	if c.DB != nil {
-		sqlDB, err := c.DB.DB()
-		if err != nil {
-			return err
-		}
-		return sqlDB.Close()
-	}
-	return nil
+// DSN connection string Postgres. Mengandung password, jangan di-log.
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=UTC",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Redacted salinan config dengan semua secret disamarkan
+func (c *Config) Redacted() Config {
+	r := *c
+	r.Database.Password = redact(r.Database.Password)
+	r.Auth.JWTSecret = redact(r.Auth.JWTSecret)
+	r.Auth.OIDC.ClientSecret = redact(r.Auth.OIDC.ClientSecret)
+	r.Auth.OIDC.StateKey = redact(r.Auth.OIDC.StateKey)
+	r.Auth.LDAP.BindPassword = redact(r.Auth.LDAP.BindPassword)
+	r.Auth.TwoFactor.EncryptionKey = redact(r.Auth.TwoFactor.EncryptionKey)
+	r.Storage.S3.SecretKey = redact(r.Storage.S3.SecretKey)
+	return r
+}
+
+// LogValue supaya slog.Any("config", cfg) tidak pernah menulis secret
+func (c *Config) LogValue() slog.Value {
+	return slog.AnyValue(c.Redacted())
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
 	}
-	return defaultValue
+	return redacted
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
+func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+		return value
 	}
 	return defaultValue
 }
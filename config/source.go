@@ -0,0 +1,343 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Duration time.Duration yang di file config ditulis sebagai string, mis. "15s" atau "200ms"
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// loadFile membaca file YAML/TOML di atas default. Key yang tidak dikenal dianggap error
+// supaya salah ketik tidak diam-diam diabaikan.
+func loadFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &tree)
+	case ".toml":
+		err = toml.Unmarshal(raw, &tree)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q (use .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	// YAML dan TOML dinormalisasi lewat JSON supaya cukup satu set struct tag. Isi file
+	// digabung ke default per key, jadi mis. rate_limit.groups.auth yang hanya berisi burst
+	// tetap memakai requests_per_minute dan roles default.
+	merged, err := defaultTree(cfg)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	var values map[string]any
+	if err := normalize(tree, &values); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	mergeTree(merged, values)
+	normalized, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(normalized))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// defaultTree config saat ini dalam bentuk map JSON
+func defaultTree(cfg *Config) (map[string]any, error) {
+	var tree map[string]any
+	return tree, normalize(cfg, &tree)
+}
+
+func normalize(value any, tree *map[string]any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, tree)
+}
+
+// mergeTree timpa dst dengan src; map digabung per key, nilai lain (termasuk list) diganti
+func mergeTree(dst, src map[string]any) {
+	for key, value := range src {
+		if sub, ok := value.(map[string]any); ok {
+			if base, ok := dst[key].(map[string]any); ok {
+				mergeTree(base, sub)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// loadEnv menimpa nilai config dengan environment variable yang di-set.
+// Nilai yang tidak bisa di-parse dikumpulkan jadi satu error.
+func loadEnv(cfg *Config) error {
+	e := &envReader{}
+
+	e.string("GO_ENV", &cfg.Environment)
+
+	e.int("SERVER_PORT", &cfg.Server.Port)
+	e.duration("SERVER_READ_TIMEOUT_SECONDS", time.Second, &cfg.Server.ReadTimeout)
+	e.duration("SERVER_READ_HEADER_TIMEOUT_SECONDS", time.Second, &cfg.Server.ReadHeaderTimeout)
+	e.duration("SERVER_WRITE_TIMEOUT_SECONDS", time.Second, &cfg.Server.WriteTimeout)
+	e.duration("SERVER_IDLE_TIMEOUT_SECONDS", time.Second, &cfg.Server.IdleTimeout)
+	e.duration("SERVER_SHUTDOWN_TIMEOUT_SECONDS", time.Second, &cfg.Server.ShutdownTimeout)
+	e.list("SERVER_TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	e.string("DB_HOST", &cfg.Database.Host)
+	e.int("DB_PORT", &cfg.Database.Port)
+	e.string("DB_USER", &cfg.Database.User)
+	e.string("DB_PASSWORD", &cfg.Database.Password)
+	e.string("DB_NAME", &cfg.Database.Name)
+	e.string("DB_SSLMODE", &cfg.Database.SSLMode)
+	e.int("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	e.int("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	e.duration("DB_CONN_MAX_LIFETIME_MINUTES", time.Minute, &cfg.Database.ConnMaxLifetime)
+	e.duration("DB_CONN_MAX_IDLE_TIME_MINUTES", time.Minute, &cfg.Database.ConnMaxIdleTime)
+	e.duration("DB_SLOW_QUERY_MS", time.Millisecond, &cfg.Database.SlowQueryThreshold)
+
+	e.string("JWT_SECRET", &cfg.Auth.JWTSecret)
+	e.duration("JWT_TOKEN_DURATION_HOURS", time.Hour, &cfg.Auth.TokenDuration)
+	e.string("JWT_ISSUER", &cfg.Auth.Issuer)
+	e.duration("API_TOKEN_DEFAULT_DAYS", 24*time.Hour, &cfg.Auth.APITokenDefaultTTL)
+	e.duration("API_TOKEN_MAX_DAYS", 24*time.Hour, &cfg.Auth.APITokenMaxTTL)
+	e.bool("AUTH_LOCAL_LOGIN_ENABLED", &cfg.Auth.LocalLoginEnabled)
+
+	e.bool("OIDC_ENABLED", &cfg.Auth.OIDC.Enabled)
+	e.string("OIDC_ISSUER_URL", &cfg.Auth.OIDC.IssuerURL)
+	e.string("OIDC_CLIENT_ID", &cfg.Auth.OIDC.ClientID)
+	e.string("OIDC_CLIENT_SECRET", &cfg.Auth.OIDC.ClientSecret)
+	e.string("OIDC_REDIRECT_URL", &cfg.Auth.OIDC.RedirectURL)
+	e.list("OIDC_SCOPES", &cfg.Auth.OIDC.Scopes)
+	e.string("OIDC_GROUPS_CLAIM", &cfg.Auth.OIDC.GroupsClaim)
+	e.string("OIDC_POST_LOGIN_REDIRECT", &cfg.Auth.OIDC.PostLoginRedirect)
+	e.string("OIDC_STATE_KEY", &cfg.Auth.OIDC.StateKey)
+	e.mapping("OIDC_ROLE_GROUPS", &cfg.Auth.OIDC.Groups.Roles)
+	e.mapping("OIDC_DEPARTMENT_GROUPS", &cfg.Auth.OIDC.Groups.Departments)
+	e.string("OIDC_DEFAULT_ROLE", &cfg.Auth.OIDC.Groups.DefaultRole)
+
+	e.bool("LDAP_ENABLED", &cfg.Auth.LDAP.Enabled)
+	e.string("LDAP_URL", &cfg.Auth.LDAP.URL)
+	e.bool("LDAP_START_TLS", &cfg.Auth.LDAP.StartTLS)
+	e.bool("LDAP_INSECURE_SKIP_VERIFY", &cfg.Auth.LDAP.InsecureSkipVerify)
+	e.string("LDAP_BIND_DN", &cfg.Auth.LDAP.BindDN)
+	e.string("LDAP_BIND_PASSWORD", &cfg.Auth.LDAP.BindPassword)
+	e.string("LDAP_BASE_DN", &cfg.Auth.LDAP.BaseDN)
+	e.string("LDAP_USER_FILTER", &cfg.Auth.LDAP.UserFilter)
+	e.string("LDAP_GROUP_BASE_DN", &cfg.Auth.LDAP.GroupBaseDN)
+	e.string("LDAP_GROUP_FILTER", &cfg.Auth.LDAP.GroupFilter)
+	e.string("LDAP_ATTR_ID", &cfg.Auth.LDAP.Attributes.ID)
+	e.string("LDAP_ATTR_USERNAME", &cfg.Auth.LDAP.Attributes.Username)
+	e.string("LDAP_ATTR_EMAIL", &cfg.Auth.LDAP.Attributes.Email)
+	e.string("LDAP_ATTR_FULL_NAME", &cfg.Auth.LDAP.Attributes.FullName)
+	e.string("LDAP_ATTR_DEPARTMENT", &cfg.Auth.LDAP.Attributes.Department)
+	e.string("LDAP_ATTR_GROUPS", &cfg.Auth.LDAP.Attributes.Groups)
+	e.mapping("LDAP_ROLE_GROUPS", &cfg.Auth.LDAP.Groups.Roles)
+	e.mapping("LDAP_DEPARTMENT_GROUPS", &cfg.Auth.LDAP.Groups.Departments)
+	e.string("LDAP_DEFAULT_ROLE", &cfg.Auth.LDAP.Groups.DefaultRole)
+	e.duration("LDAP_TIMEOUT_SECONDS", time.Second, &cfg.Auth.LDAP.Timeout)
+
+	e.string("TWO_FACTOR_ISSUER", &cfg.Auth.TwoFactor.Issuer)
+	e.list("TWO_FACTOR_REQUIRED_ROLES", &cfg.Auth.TwoFactor.RequiredRoles)
+	e.string("TWO_FACTOR_ENCRYPTION_KEY", &cfg.Auth.TwoFactor.EncryptionKey)
+	e.duration("TWO_FACTOR_CHALLENGE_MINUTES", time.Minute, &cfg.Auth.TwoFactor.ChallengeTTL)
+
+	e.list("CORS_ALLOW_ORIGINS", &cfg.CORS.AllowOrigins)
+	e.list("CORS_ALLOW_METHODS", &cfg.CORS.AllowMethods)
+	e.list("CORS_ALLOW_HEADERS", &cfg.CORS.AllowHeaders)
+	e.list("CORS_EXPOSE_HEADERS", &cfg.CORS.ExposeHeaders)
+	e.optionalBool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	e.duration("CORS_MAX_AGE_HOURS", time.Hour, &cfg.CORS.MaxAge)
+
+	e.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	for _, group := range []string{RateLimitGroupAuth, RateLimitGroupSearch, RateLimitGroupAPI} {
+		g := cfg.RateLimit.Groups[group]
+		prefix := "RATE_LIMIT_" + strings.ToUpper(group)
+		e.int(prefix+"_PER_MINUTE", &g.RequestsPerMinute)
+		e.int(prefix+"_BURST", &g.Burst)
+		cfg.RateLimit.Groups[group] = g
+	}
+
+	e.string("LOG_LEVEL", &cfg.Log.Level)
+	e.string("LOG_FORMAT", &cfg.Log.Format)
+
+	e.duration("METRICS_REFRESH_SECONDS", time.Second, &cfg.Metrics.RefreshInterval)
+	e.duration("HEALTH_CHECK_TIMEOUT_MS", time.Millisecond, &cfg.Health.CheckTimeout)
+
+	e.string("STORAGE_DRIVER", &cfg.Storage.Driver)
+	e.string("STORAGE_LOCAL_DIR", &cfg.Storage.Local.Dir)
+	e.string("STORAGE_S3_ENDPOINT", &cfg.Storage.S3.Endpoint)
+	e.string("STORAGE_S3_BUCKET", &cfg.Storage.S3.Bucket)
+	e.string("STORAGE_S3_ACCESS_KEY", &cfg.Storage.S3.AccessKey)
+	e.string("STORAGE_S3_SECRET_KEY", &cfg.Storage.S3.SecretKey)
+	e.string("STORAGE_S3_REGION", &cfg.Storage.S3.Region)
+	e.bool("STORAGE_S3_USE_SSL", &cfg.Storage.S3.UseSSL)
+	e.int("UPLOAD_MAX_PHOTO_MB", &cfg.Storage.MaxPhotoSizeMB)
+	e.int("UPLOAD_MAX_DOCUMENT_MB", &cfg.Storage.MaxDocumentSizeMB)
+	e.int("UPLOAD_THUMBNAIL_SIZE", &cfg.Storage.ThumbnailSize)
+
+	e.duration("LOAN_DEFAULT_DAYS", 24*time.Hour, &cfg.Loans.DefaultDuration)
+	e.int("LOAN_MAX_ACTIVE", &cfg.Loans.MaxActiveLoans)
+	e.bool("LOAN_BLOCK_OVERDUE", &cfg.Loans.BlockOverdue)
+	e.bool("LOAN_REQUIRE_INSPECTION", &cfg.Loans.RequireInspection)
+
+	return errors.Join(e.errs...)
+}
+
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+func (e *envReader) fail(key, value, want string) {
+	e.errs = append(e.errs, fmt.Errorf("env %s=%q: must be %s", key, value, want))
+}
+
+func (e *envReader) string(key string, dst *string) {
+	if value, ok := e.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, value, "an integer")
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value, "true or false")
+		return
+	}
+	*dst = b
+}
+
+// optionalBool nil berarti tidak di-set, default diputuskan belakangan
+func (e *envReader) optionalBool(key string, dst **bool) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value, "true or false")
+		return
+	}
+	*dst = &b
+}
+
+// duration angka polos dikalikan unit (kompatibel dengan env lama), atau format Go seperti "90s"
+func (e *envReader) duration(key string, unit time.Duration, dst *Duration) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		*dst = Duration(time.Duration(n) * unit)
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, value, "a number or a duration like 30s")
+		return
+	}
+	*dst = Duration(d)
+}
+
+// list dipisah koma, spasi di sekitar item dibuang
+func (e *envReader) list(key string, dst *[]string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+// mapping format "key=value,key2=value2", mis. OIDC_ROLE_GROUPS=it-admins=admin,field-techs=technician
+func (e *envReader) mapping(key string, dst *map[string]string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	m := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, found := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !found || k == "" || v == "" {
+			e.fail(key, value, "a list of key=value pairs")
+			return
+		}
+		m[k] = v
+	}
+	*dst = m
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestLoadFileKeepsDefaultsOfPartialGroups(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+rate_limit:
+  groups:
+    auth:
+      burst: 3
+    api:
+      roles:
+        user:
+          requests_per_minute: 100
+`)
+	cfg := defaults()
+	if err := loadFile(path, cfg); err != nil {
+		t.Fatalf("loadFile: %v", err)
+	}
+
+	auth := cfg.RateLimit.Groups[RateLimitGroupAuth]
+	if auth.Burst != 3 || auth.RequestsPerMinute != 10 {
+		t.Errorf("auth = %+v, want burst 3 with the default 10 requests per minute", auth.RateLimitRule)
+	}
+	api := cfg.RateLimit.Groups[RateLimitGroupAPI]
+	if api.RequestsPerMinute != 300 || api.Roles["admin"].RequestsPerMinute != 1200 || api.Roles["user"].RequestsPerMinute != 100 {
+		t.Errorf("api = %+v, want defaults plus the user override", api)
+	}
+	if _, ok := cfg.RateLimit.Groups[RateLimitGroupSearch]; !ok {
+		t.Error("search group missing although the file does not mention it")
+	}
+}
+
+func TestLoadFileRejectsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", "[rate_limit.groups.auth]\nburts = 3\n")
+	if err := loadFile(path, defaults()); err == nil {
+		t.Fatal("loadFile accepted a misspelled key")
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// minJWTSecretLength panjang minimal secret HS256 di luar development
+const minJWTSecretLength = 32
+
+// Validate mengecek semua nilai config dan mengembalikan seluruh pelanggaran sekaligus
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(slices.Contains([]string{EnvDevelopment, EnvStaging, EnvProduction}, c.Environment),
+		"environment", "must be one of development, staging, production")
+
+	v.check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535")
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check(cidrErr == nil || net.ParseIP(proxy) != nil,
+			"server.trusted_proxies", fmt.Sprintf("%q is not an IP address or CIDR", proxy))
+	}
+
+	v.check(c.Database.Host != "", "database.host", "is required")
+	v.check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535")
+	v.check(c.Database.User != "", "database.user", "is required")
+	v.check(c.Database.Name != "", "database.name", "is required")
+	v.check(slices.Contains([]string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, c.Database.SSLMode),
+		"database.ssl_mode", "must be a valid Postgres sslmode")
+	v.check(c.Database.MaxOpenConns > 0, "database.max_open_conns", "must be greater than 0")
+	v.check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns", "must be between 0 and database.max_open_conns")
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	v.positive("database.slow_query_threshold", c.Database.SlowQueryThreshold)
+
+	v.check(c.Auth.JWTSecret != "", "auth.jwt_secret", "is required (set JWT_SECRET)")
+	if !c.IsDevelopment() {
+		v.check(len(c.Auth.JWTSecret) >= minJWTSecretLength,
+			"auth.jwt_secret", fmt.Sprintf("must be at least %d characters outside development", minJWTSecretLength))
+		v.check(c.Auth.JWTSecret != devJWTSecret, "auth.jwt_secret", "must not use the development default")
+	}
+	v.positive("auth.token_duration", c.Auth.TokenDuration)
+	v.positive("auth.api_token_max_ttl", c.Auth.APITokenMaxTTL)
+	v.check(c.Auth.APITokenDefaultTTL > 0 && c.Auth.APITokenDefaultTTL <= c.Auth.APITokenMaxTTL,
+		"auth.api_token_default_ttl", "must be greater than 0 and at most auth.api_token_max_ttl")
+	v.check(c.Auth.LocalLoginEnabled || c.Auth.OIDC.Enabled || c.Auth.LDAP.Enabled,
+		"auth.local_login_enabled", "cannot be disabled unless another login method is enabled")
+	if c.Auth.OIDC.Enabled {
+		v.check(c.Auth.OIDC.IssuerURL != "", "auth.oidc.issuer_url", "is required when OIDC is enabled")
+		v.check(c.Auth.OIDC.ClientID != "", "auth.oidc.client_id", "is required when OIDC is enabled")
+		v.check(c.Auth.OIDC.RedirectURL != "", "auth.oidc.redirect_url", "is required when OIDC is enabled")
+		v.check(slices.Contains(c.Auth.OIDC.Scopes, "openid"), "auth.oidc.scopes", `must include "openid"`)
+		v.groupMapping("auth.oidc.groups", c.Auth.OIDC.Groups)
+		if c.Auth.OIDC.StateKey != "" && !c.IsDevelopment() {
+			v.check(len(c.Auth.OIDC.StateKey) >= minJWTSecretLength, "auth.oidc.state_key",
+				fmt.Sprintf("must be at least %d characters outside development", minJWTSecretLength))
+		}
+	}
+	if c.Auth.LDAP.Enabled {
+		ldapURL, err := url.Parse(c.Auth.LDAP.URL)
+		validURL := err == nil && (ldapURL.Scheme == "ldap" || ldapURL.Scheme == "ldaps") && ldapURL.Host != ""
+		v.check(validURL, "auth.ldap.url", "must be an ldap:// or ldaps:// URL")
+		v.check(c.Auth.LDAP.BaseDN != "", "auth.ldap.base_dn", "is required when LDAP is enabled")
+		v.check(strings.Count(c.Auth.LDAP.UserFilter, "%s") == 1, "auth.ldap.user_filter", "must contain exactly one %s")
+		v.check(c.Auth.LDAP.GroupFilter == "" || strings.Count(c.Auth.LDAP.GroupFilter, "%s") == 1,
+			"auth.ldap.group_filter", "must contain exactly one %s")
+		v.check(c.Auth.LDAP.Attributes.Email != "", "auth.ldap.attributes.email", "is required")
+		v.positive("auth.ldap.timeout", c.Auth.LDAP.Timeout)
+		// Password user dikirim saat bind, jangan lewat koneksi plaintext di production
+		if c.IsProduction() {
+			v.check(!validURL || ldapURL.Scheme == "ldaps" || c.Auth.LDAP.StartTLS,
+				"auth.ldap.url", "must use ldaps:// or start_tls in production")
+			v.check(!c.Auth.LDAP.InsecureSkipVerify, "auth.ldap.insecure_skip_verify", "is not allowed in production")
+		}
+		v.groupMapping("auth.ldap.groups", c.Auth.LDAP.Groups)
+	}
+	for _, role := range c.Auth.TwoFactor.RequiredRoles {
+		v.check(validRole(role), "auth.two_factor.required_roles", "must contain only admin, technician or user")
+	}
+	v.check(c.Auth.TwoFactor.Issuer != "", "auth.two_factor.issuer", "is required")
+	v.positive("auth.two_factor.challenge_ttl", c.Auth.TwoFactor.ChallengeTTL)
+	if !c.IsDevelopment() {
+		v.check(len(c.Auth.TwoFactor.EncryptionKey) >= minJWTSecretLength, "auth.two_factor.encryption_key",
+			fmt.Sprintf("must be at least %d characters outside development", minJWTSecretLength))
+	}
+
+	// "*" + credentials tidak valid menurut spec CORS dan membuka token ke origin mana pun
+	v.check(!(slices.Contains(c.CORS.AllowOrigins, "*") && c.CORS.AllowCredentials != nil && *c.CORS.AllowCredentials),
+		"cors.allow_origins", `"*" cannot be combined with allow_credentials; list origins explicitly`)
+	v.check(!c.IsProduction() || !slices.Contains(c.CORS.AllowOrigins, "*"),
+		"cors.allow_origins", `"*" is not allowed in production`)
+	v.check(len(c.CORS.AllowMethods) > 0, "cors.allow_methods", "must not be empty")
+	v.check(c.CORS.MaxAge >= 0, "cors.max_age", "must not be negative")
+
+	for name, group := range c.RateLimit.Groups {
+		field := "rate_limit.groups." + name
+		v.check(slices.Contains([]string{RateLimitGroupAuth, RateLimitGroupSearch, RateLimitGroupAPI}, name),
+			field, "unknown group (want auth, search or api)")
+		v.rateLimitRule(field, group.RateLimitRule)
+		for role, rule := range group.Roles {
+			v.rateLimitRule(field+".roles."+role, rule)
+		}
+	}
+
+	v.check(slices.Contains([]string{"debug", "info", "warn", "warning", "error"}, strings.ToLower(c.Log.Level)),
+		"log.level", "must be one of debug, info, warn, error")
+	v.check(slices.Contains([]string{"json", "text"}, strings.ToLower(c.Log.Format)),
+		"log.format", "must be json or text")
+
+	v.positive("metrics.refresh_interval", c.Metrics.RefreshInterval)
+	v.positive("health.check_timeout", c.Health.CheckTimeout)
+
+	v.check(slices.Contains([]string{StorageDriverLocal, StorageDriverS3}, c.Storage.Driver),
+		"storage.driver", "must be local or s3")
+	switch c.Storage.Driver {
+	case StorageDriverLocal:
+		v.check(c.Storage.Local.Dir != "", "storage.local.dir", "is required for the local driver")
+	case StorageDriverS3:
+		v.check(c.Storage.S3.Endpoint != "" && !strings.Contains(c.Storage.S3.Endpoint, "://"),
+			"storage.s3.endpoint", "is required for the s3 driver (host[:port] without scheme)")
+		v.check(c.Storage.S3.Bucket != "", "storage.s3.bucket", "is required for the s3 driver")
+		v.check(c.Storage.S3.AccessKey != "" && c.Storage.S3.SecretKey != "",
+			"storage.s3.access_key", "access_key and secret_key are required for the s3 driver")
+	}
+	v.check(c.Storage.MaxPhotoSizeMB > 0, "storage.max_photo_size_mb", "must be greater than 0")
+	v.check(c.Storage.MaxDocumentSizeMB > 0, "storage.max_document_size_mb", "must be greater than 0")
+	v.check(c.Storage.ThumbnailSize >= 32 && c.Storage.ThumbnailSize <= 2048,
+		"storage.thumbnail_size", "must be between 32 and 2048")
+
+	v.positive("loans.default_duration", c.Loans.DefaultDuration)
+	for role, d := range c.Loans.RoleDurations {
+		v.check(validRole(role), "loans.role_durations."+role, "unknown role (want admin, technician or user)")
+		v.positive("loans.role_durations."+role, d)
+	}
+	v.check(c.Loans.MaxActiveLoans >= 0, "loans.max_active_loans", "must not be negative")
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(v.problems, "\n  - "))
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.problems = append(v.problems, field+": "+message)
+	}
+}
+
+func (v *validator) groupMapping(field string, m GroupMappingConfig) {
+	for group, role := range m.Roles {
+		v.check(validRole(role), field+".roles."+group, "must be admin, technician or user")
+	}
+	v.check(m.DefaultRole == "" || validRole(m.DefaultRole), field+".default_role", "must be admin, technician or user")
+}
+
+func validRole(role string) bool {
+	return slices.Contains([]string{"admin", "technician", "user"}, role)
+}
+
+func (v *validator) rateLimitRule(field string, rule RateLimitRule) {
+	v.check(rule.RequestsPerMinute >= 0, field+".requests_per_minute", "must not be negative")
+	v.check(rule.Burst >= 0, field+".burst", "must not be negative")
+}
+
+func (v *validator) positive(field string, d Duration) {
+	v.check(d > 0, field, "must be greater than 0")
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+type APITokenHandler struct {
+	service services.APITokenService
+}
+
+func NewAPITokenHandler(service services.APITokenService) *APITokenHandler {
+	return &APITokenHandler{service: service}
+}
+
+func (h *APITokenHandler) Create(c *gin.Context) {
+	var req models.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), userClaims, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "API token created, store it now: it will not be shown again",
+		"data":    result,
+	})
+}
+
+func (h *APITokenHandler) List(c *gin.Context) {
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	tokens, err := h.service.List(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API tokens retrieved successfully",
+		"data":    tokens,
+	})
+}
+
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), id, userClaims); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "API token revoked successfully",
+	})
+}
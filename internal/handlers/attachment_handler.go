@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+// multipartOverhead ruang untuk boundary dan field form di luar file
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	service services.AttachmentService
+}
+
+func NewAttachmentHandler(service services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	// Body di atas batas langsung diputus, tidak ditampung ke disk
+	maxBody := h.service.MaxUploadSize() + multipartOverhead
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+
+	var req models.AttachmentUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			_ = c.Error(services.NewTooLargeError("file", h.service.MaxUploadSize()))
+			return
+		}
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Upload(c.Request.Context(), toolkitID, userClaims.UserID, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "File uploaded successfully",
+		"data":    result,
+	})
+}
+
+func (h *AttachmentHandler) GetByToolkit(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	attachments, err := h.service.GetByToolkit(c.Request.Context(), toolkitID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Attachments retrieved successfully",
+		"data":    attachments,
+		"count":   len(attachments),
+	})
+}
+
+func (h *AttachmentHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	attachment, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Attachment retrieved successfully",
+		"data":    attachment,
+	})
+}
+
+// Content stream isi file dari storage, ?thumbnail=true untuk thumbnail foto
+func (h *AttachmentHandler) Content(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.AttachmentContentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	attachment, reader, err := h.service.Open(c.Request.Context(), id, req.Thumbnail)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("inline; filename=%q", attachment.FileName),
+		"Cache-Control":          "private, max-age=86400",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Attachment deleted successfully",
+	})
+}
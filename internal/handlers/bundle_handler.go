@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+)
+
+type BundleHandler struct {
+	service services.BundleService
+}
+
+func NewBundleHandler(service services.BundleService) *BundleHandler {
+	return &BundleHandler{service: service}
+}
+
+func (h *BundleHandler) Create(c *gin.Context) {
+	var req models.BundleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Bundle created successfully",
+		"data":    result,
+	})
+}
+
+func (h *BundleHandler) GetAll(c *gin.Context) {
+	var filter models.BundleFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.GetAll(c.Request.Context(), &filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundles retrieved successfully",
+		"data":    result,
+		"count":   len(result),
+	})
+}
+
+func (h *BundleHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	result, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle retrieved successfully",
+		"data":    result,
+	})
+}
+
+func (h *BundleHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.BundleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle updated successfully",
+		"data":    result,
+	})
+}
+
+func (h *BundleHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle deleted successfully",
+	})
+}
+
+// Checkout pinjam bundle: semua komponen dalam satu transaksi
+func (h *BundleHandler) Checkout(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.BundleCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Checkout(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Bundle checked out successfully",
+		"data":    result,
+	})
+}
+
+func (h *BundleHandler) GetLoans(c *gin.Context) {
+	var filter models.BundleLoanFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.GetLoans(c.Request.Context(), &filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle loans retrieved successfully",
+		"data":    result,
+		"count":   len(result),
+	})
+}
+
+func (h *BundleHandler) GetLoanByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	result, err := h.service.GetLoanByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle loan retrieved successfully",
+		"data":    result,
+	})
+}
+
+// Return items kosong = semua komponen yang masih dipinjam
+func (h *BundleHandler) Return(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.BundleReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Return(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bundle returned successfully",
+		"data":    result,
+	})
+}
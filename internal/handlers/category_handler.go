@@ -21,13 +21,13 @@ func NewCategoryHandler(service services.CategoryService) *CategoryHandler {
 func (h *CategoryHandler) Create(c *gin.Context) {
 	var req models.CategoryCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Create(&req)
+	result, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -41,13 +41,13 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 func (h *CategoryHandler) GetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	category, err := h.service.GetByID(id)
+	category, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Category not found"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -64,9 +64,9 @@ func (h *CategoryHandler) GetAll(c *gin.Context) {
 		filter = models.CategoryFilterRequest{}
 	}
 
-	categoryList, err := h.service.GetAll(&filter)
+	categoryList, err := h.service.GetAll(c.Request.Context(), &filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -81,19 +81,19 @@ func (h *CategoryHandler) GetAll(c *gin.Context) {
 func (h *CategoryHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
 	var req models.CategoryUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Update(id, &req)
+	result, err := h.service.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -107,13 +107,13 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 func (h *CategoryHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -124,9 +124,9 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 }
 
 func (h *CategoryHandler) GetTree(c *gin.Context) {
-	tree, err := h.service.GetTree()
+	tree, err := h.service.GetTree(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
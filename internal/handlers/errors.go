@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"toolkit-management/internal/services"
+)
+
+func init() {
+	// Pakai nama field JSON di pesan validasi, bukan nama field struct
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+func invalidIDError() error {
+	return services.NewValidationError("invalid ID", services.FieldError{Field: "id", Message: "must be a positive integer"})
+}
+
+// bindingError ubah error ShouldBind* jadi validation error dengan detail per field
+func bindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]services.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, services.FieldError{Field: fe.Field(), Message: validationMessage(fe)})
+		}
+		return services.NewValidationError("request validation failed", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return services.NewValidationError("request validation failed",
+			services.FieldError{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()})
+	}
+
+	if errors.Is(err, io.EOF) {
+		return services.NewValidationError("request body is required")
+	}
+
+	return services.NewValidationError("malformed request: " + err.Error())
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed on '%s' validation", fe.Tag())
+	}
+}
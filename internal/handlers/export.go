@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeCSV kirim report sebagai file CSV attachment
+func writeCSV(c *gin.Context, filename string, header []string, rows [][]string) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	c.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	_ = w.WriteAll(rows)
+}
+
+// writeFile kirim file hasil generate (label, dokumen) inline
+func writeFile(c *gin.Context, filename, contentType string, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func formatMoney(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/health"
+	"toolkit-management/internal/jobs"
+)
+
+type HealthHandler struct {
+	checker   *health.Checker
+	scheduler *jobs.Scheduler
+	details   func() gin.H
+}
+
+// details opsional, info tambahan untuk view admin (mis. statistik pool DB)
+func NewHealthHandler(checker *health.Checker, scheduler *jobs.Scheduler, details func() gin.H) *HealthHandler {
+	return &HealthHandler{checker: checker, scheduler: scheduler, details: details}
+}
+
+// Liveness proses masih hidup dan bisa melayani request, tanpa cek dependency
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// Readiness 503 kalau salah satu komponen down, supaya orchestrator berhenti kirim traffic
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+
+	components := make(gin.H, len(report.Components))
+	for name, component := range report.Components {
+		components[name] = component.Status
+	}
+
+	c.JSON(readinessStatus(report), gin.H{
+		"status":     report.Status,
+		"components": components,
+	})
+}
+
+// Detail view admin: status, latency dan error tiap komponen plus status job
+func (h *HealthHandler) Detail(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+
+	data := gin.H{
+		"status":     report.Status,
+		"checked_at": report.CheckedAt,
+		"components": report.Components,
+		"jobs":       h.scheduler.Status(),
+	}
+	if h.details != nil {
+		for k, v := range h.details() {
+			data[k] = v
+		}
+	}
+
+	c.JSON(readinessStatus(report), gin.H{
+		"success": report.Status == health.StatusUp,
+		"message": "Health report generated",
+		"data":    data,
+	})
+}
+
+func readinessStatus(report health.Report) int {
+	if report.Status != health.StatusUp {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+type IncidentHandler struct {
+	service services.IncidentService
+}
+
+func NewIncidentHandler(service services.IncidentService) *IncidentHandler {
+	return &IncidentHandler{service: service}
+}
+
+func (h *IncidentHandler) Create(c *gin.Context) {
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.IncidentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), loanID, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Incident reported successfully",
+		"data":    result,
+	})
+}
+
+func (h *IncidentHandler) GetAll(c *gin.Context) {
+	var filter models.IncidentFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	incidents, err := h.service.GetAll(c.Request.Context(), &filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Incidents retrieved successfully",
+		"data":    incidents,
+		"count":   len(incidents),
+	})
+}
+
+func (h *IncidentHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	incident, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Incident retrieved successfully",
+		"data":    incident,
+	})
+}
+
+func (h *IncidentHandler) AddEvidence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.IncidentEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.AddEvidence(c.Request.Context(), id, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Evidence attached successfully",
+		"data":    result,
+	})
+}
+
+func (h *IncidentHandler) Assess(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	// Body opsional
+	var req models.IncidentAssessRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Assess(c.Request.Context(), id, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Incident assessed",
+		"data":    result,
+	})
+}
+
+func (h *IncidentHandler) Resolve(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.IncidentResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Resolve(c.Request.Context(), id, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Incident resolved",
+		"data":    result,
+	})
+}
+
+func (h *IncidentHandler) GetAdjustments(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	adjustments, err := h.service.GetAdjustments(c.Request.Context(), toolkitID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Stock adjustments retrieved successfully",
+		"data":    adjustments,
+		"count":   len(adjustments),
+	})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+type InspectionHandler struct {
+	service services.InspectionService
+}
+
+func NewInspectionHandler(service services.InspectionService) *InspectionHandler {
+	return &InspectionHandler{service: service}
+}
+
+func (h *InspectionHandler) GetChecklist(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	items, err := h.service.GetChecklist(c.Request.Context(), categoryID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Checklist retrieved successfully",
+		"data":    items,
+		"count":   len(items),
+	})
+}
+
+func (h *InspectionHandler) CreateChecklistItem(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.ChecklistItemCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.CreateChecklistItem(c.Request.Context(), categoryID, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Checklist item created successfully",
+		"data":    result,
+	})
+}
+
+func (h *InspectionHandler) UpdateChecklistItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.ChecklistItemUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.UpdateChecklistItem(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Checklist item updated successfully",
+		"data":    result,
+	})
+}
+
+func (h *InspectionHandler) DeleteChecklistItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	if err := h.service.DeleteChecklistItem(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Checklist item deleted successfully",
+	})
+}
+
+func (h *InspectionHandler) Create(c *gin.Context) {
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.InspectionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), loanID, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	message := "Inspection recorded successfully"
+	if result.NeedsReview {
+		message = "Inspection recorded and flagged for review"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": message,
+		"data":    result,
+	})
+}
+
+func (h *InspectionHandler) GetByLoan(c *gin.Context) {
+	loanID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	h.list(c, &models.InspectionFilterRequest{LoanID: loanID})
+}
+
+func (h *InspectionHandler) GetAll(c *gin.Context) {
+	var filter models.InspectionFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	h.list(c, &filter)
+}
+
+func (h *InspectionHandler) list(c *gin.Context, filter *models.InspectionFilterRequest) {
+	inspections, err := h.service.GetAll(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Inspections retrieved successfully",
+		"data":    inspections,
+		"count":   len(inspections),
+	})
+}
+
+func (h *InspectionHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	inspection, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Inspection retrieved successfully",
+		"data":    inspection,
+	})
+}
+
+func (h *InspectionHandler) Review(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	// Body opsional
+	var req models.InspectionReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Review(c.Request.Context(), id, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Inspection reviewed",
+		"data":    result,
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+)
+
+type LabelHandler struct {
+	service services.LabelService
+}
+
+func NewLabelHandler(service services.LabelService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+func (h *LabelHandler) Toolkit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.LabelRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	data, err := h.service.Toolkit(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if req.Format == "pdf" {
+		writeFile(c, fmt.Sprintf("label-%d.pdf", id), "application/pdf", data)
+		return
+	}
+	writeFile(c, fmt.Sprintf("label-%d.png", id), "image/png", data)
+}
+
+func (h *LabelHandler) Batch(c *gin.Context) {
+	var req models.LabelBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	data, err := h.service.Batch(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	writeFile(c, "labels.pdf", "application/pdf", data)
+}
@@ -21,13 +21,13 @@ func NewLoanHandler(service services.LoanService) *LoanHandler {
 func (h *LoanHandler) Create(c *gin.Context) {
 	var req models.LoanCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Create(&req)
+	result, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -41,13 +41,13 @@ func (h *LoanHandler) Create(c *gin.Context) {
 func (h *LoanHandler) GetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	loan, err := h.service.GetByID(id)
+	loan, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Data not found"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -64,9 +64,9 @@ func (h *LoanHandler) GetAll(c *gin.Context) {
 		filter = models.LoanFilterRequest{}
 	}
 
-	loanList, err := h.service.GetAll(&filter)
+	loanList, err := h.service.GetAll(c.Request.Context(), &filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -83,19 +83,19 @@ func (h *LoanHandler) GetAll(c *gin.Context) {
 func (h *LoanHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
 	var req models.LoanUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Update(id, &req)
+	result, err := h.service.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -109,13 +109,13 @@ func (h *LoanHandler) Update(c *gin.Context) {
 func (h *LoanHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Data not found"})
+		_ = c.Error(err)
 		return
 	}
 
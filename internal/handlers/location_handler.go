@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+type LocationHandler struct {
+	service services.LocationService
+}
+
+func NewLocationHandler(service services.LocationService) *LocationHandler {
+	return &LocationHandler{service: service}
+}
+
+func (h *LocationHandler) Create(c *gin.Context) {
+	var req models.LocationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Location created successfully",
+		"data":    result,
+	})
+}
+
+func (h *LocationHandler) GetAll(c *gin.Context) {
+	var filter models.LocationFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.GetAll(c.Request.Context(), &filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Locations retrieved successfully",
+		"data":    result,
+		"count":   len(result),
+	})
+}
+
+func (h *LocationHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	result, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Location retrieved successfully",
+		"data":    result,
+	})
+}
+
+func (h *LocationHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.LocationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Location updated successfully",
+		"data":    result,
+	})
+}
+
+func (h *LocationHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Location deleted successfully",
+	})
+}
+
+// Stock stok per toolkit di lokasi, include_children=true untuk seluruh gudang
+func (h *LocationHandler) Stock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var filter models.LocationStockFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Stock(c.Request.Context(), id, &filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Location stock retrieved successfully",
+		"data":    result,
+		"count":   len(result),
+	})
+}
+
+func (h *LocationHandler) SetStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+	toolkitID, err := strconv.Atoi(c.Param("toolkit_id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.LocationStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.SetStock(c.Request.Context(), id, toolkitID, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Location stock updated successfully",
+		"data":    result,
+	})
+}
+
+// ToolkitAvailability stok satu toolkit per lokasi, route di bawah /toolkits/:id
+func (h *LocationHandler) ToolkitAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	result, err := h.service.ToolkitAvailability(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Toolkit availability retrieved successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+type MaintenanceHandler struct {
+	service services.MaintenanceService
+}
+
+func NewMaintenanceHandler(service services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: service}
+}
+
+func (h *MaintenanceHandler) Create(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.MaintenanceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Create(c.Request.Context(), toolkitID, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Maintenance created successfully",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) GetByToolkit(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var filter models.MaintenanceFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+	filter.ToolkitID = toolkitID
+
+	h.list(c, &filter)
+}
+
+func (h *MaintenanceHandler) GetAll(c *gin.Context) {
+	var filter models.MaintenanceFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	h.list(c, &filter)
+}
+
+func (h *MaintenanceHandler) list(c *gin.Context, filter *models.MaintenanceFilterRequest) {
+	records, err := h.service.GetAll(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance records retrieved successfully",
+		"data":    records,
+		"count":   len(records),
+	})
+}
+
+func (h *MaintenanceHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	record, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance retrieved successfully",
+		"data":    record,
+	})
+}
+
+func (h *MaintenanceHandler) Due(c *gin.Context) {
+	var req models.MaintenanceDueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Due(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Due maintenance retrieved successfully",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) Start(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Start(c.Request.Context(), id, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance started",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) Complete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	// Body opsional
+	var req models.MaintenanceCompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Complete(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance completed",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	// Body opsional
+	var req models.MaintenanceCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Cancel(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance cancelled",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) CreateSchedule(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.MaintenanceScheduleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateSchedule(c.Request.Context(), toolkitID, &req, userClaims)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Maintenance schedule created successfully",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) GetSchedules(c *gin.Context) {
+	toolkitID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	schedules, err := h.service.GetSchedules(c.Request.Context(), toolkitID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance schedules retrieved successfully",
+		"data":    schedules,
+		"count":   len(schedules),
+	})
+}
+
+func (h *MaintenanceHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	var req models.MaintenanceScheduleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.UpdateSchedule(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance schedule updated successfully",
+		"data":    result,
+	})
+}
+
+func (h *MaintenanceHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(invalidIDError())
+		return
+	}
+
+	if err := h.service.DeleteSchedule(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Maintenance schedule deleted successfully",
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/auth/oidc"
+)
+
+type OIDCHandler struct {
+	provider          *auth.OIDCProvider
+	userService       services.UserService
+	postLoginRedirect string
+	secureCookie      bool
+}
+
+func NewOIDCHandler(provider *auth.OIDCProvider, userService services.UserService, postLoginRedirect string, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{
+		provider:          provider,
+		userService:       userService,
+		postLoginRedirect: postLoginRedirect,
+		secureCookie:      secureCookie,
+	}
+}
+
+// Login redirect ke IdP. State, nonce dan PKCE verifier disimpan di cookie bertanda tangan.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	redirectURL, state, err := h.provider.AuthCodeURL(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "OIDC discovery failed", "error", err)
+		c.Status(http.StatusBadGateway)
+		_ = c.Error(errors.New("identity provider unavailable"))
+		return
+	}
+
+	h.setStateCookie(c, state, int(h.provider.StateTTL()/time.Second))
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// Callback tukar authorization code, provisioning user, lalu terbitkan JWT aplikasi
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if idpErr := c.Query("error"); idpErr != "" {
+		_ = c.Error(services.NewUnauthorizedError("identity provider returned error: " + idpErr))
+		return
+	}
+
+	state, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(auth.ErrInvalidOIDCState.Error()))
+		return
+	}
+	// State sekali pakai
+	h.setStateCookie(c, "", -1)
+
+	identity, err := h.provider.Exchange(c.Request.Context(), state, c.Query("state"), c.Query("code"))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidOIDCState) {
+			_ = c.Error(services.NewUnauthorizedError(err.Error()))
+			return
+		}
+		slog.WarnContext(c.Request.Context(), "OIDC login failed", "error", err)
+		_ = c.Error(services.NewUnauthorizedError("single sign-on failed"))
+		return
+	}
+
+	result, err := h.userService.LoginExternal(c.Request.Context(), &models.ExternalIdentity{
+		Provider:      "oidc",
+		Subject:       identity.Subject,
+		Username:      identity.Username,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		FullName:      identity.FullName,
+		Role:          identity.Role,
+		DefaultRole:   identity.DefaultRole,
+		Department:    identity.Department,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if h.postLoginRedirect != "" {
+		// Fragment tidak dikirim ke server, jadi token tidak masuk access log frontend
+		fragment := url.Values{}
+		if challenge := result.TwoFactor; challenge != nil {
+			// Frontend lanjutkan ke langkah kedua dengan challenge token
+			fragment.Set("two_factor_step", challenge.Step)
+			fragment.Set("challenge_token", challenge.ChallengeToken)
+			fragment.Set("expires_at", challenge.ExpiresAt.UTC().Format(time.RFC3339))
+		} else {
+			fragment.Set("token", result.Token)
+			fragment.Set("expires_at", result.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		c.Redirect(http.StatusFound, h.postLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Login successful",
+		"data":    result,
+	})
+}
+
+func (h *OIDCHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, oidcStateCookiePath, "", h.secureCookie, true)
+}
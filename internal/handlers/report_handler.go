@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+)
+
+type ReportHandler struct {
+	service services.ReportService
+}
+
+func NewReportHandler(service services.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+func (h *ReportHandler) Valuation(c *gin.Context) {
+	var req models.ValuationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	report, err := h.service.Valuation(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if req.Format == "csv" {
+		writeCSV(c, "valuation-"+report.AsOf.Format("2006-01-02")+".csv", valuationHeader, valuationRows(report))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Valuation report generated successfully",
+		"data":    report,
+	})
+}
+
+var valuationHeader = []string{
+	"toolkit_id", "sku", "name", "category", "method", "useful_life_months", "purchase_date",
+	"unit_cost", "units", "cost", "accumulated_depreciation", "book_value",
+	"units_acquired", "acquired_cost", "units_written_off", "written_off_book_value",
+}
+
+// valuationRows satu baris per toolkit, subtotal per kategori, lalu total
+func valuationRows(report *models.ValuationReport) [][]string {
+	rows := make([][]string, 0, len(report.Toolkits)+len(report.Categories)+1)
+	for _, t := range report.Toolkits {
+		purchaseDate := ""
+		if t.PurchaseDate != nil {
+			purchaseDate = t.PurchaseDate.Format("2006-01-02")
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(t.ToolkitID), t.SKU, t.Name, t.CategoryName, t.Method, strconv.Itoa(t.UsefulLifeMonths), purchaseDate,
+			formatMoney(t.UnitCost), strconv.Itoa(t.Units), formatMoney(t.Cost), formatMoney(t.AccumulatedDepreciation), formatMoney(t.BookValue),
+			strconv.Itoa(t.UnitsAcquired), formatMoney(t.AcquiredCost), strconv.Itoa(t.UnitsWrittenOff), formatMoney(t.WrittenOffBookValue),
+		})
+	}
+	for _, total := range report.Categories {
+		rows = append(rows, totalsRow("SUBTOTAL", total.CategoryName, total))
+	}
+	return append(rows, totalsRow("TOTAL", "", report.Total))
+}
+
+func totalsRow(label, category string, total models.ValuationTotals) []string {
+	return []string{
+		label, "", "", category, "", "", "",
+		"", strconv.Itoa(total.Units), formatMoney(total.Cost), formatMoney(total.AccumulatedDepreciation), formatMoney(total.BookValue),
+		strconv.Itoa(total.UnitsAcquired), formatMoney(total.AcquiredCost), strconv.Itoa(total.UnitsWrittenOff), formatMoney(total.WrittenOffBookValue),
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
+)
+
+// ScanHandler checkout dan return dari handheld scanner (kode item + badge peminjam)
+type ScanHandler struct {
+	service services.ScanService
+}
+
+func NewScanHandler(service services.ScanService) *ScanHandler {
+	return &ScanHandler{service: service}
+}
+
+func (h *ScanHandler) Checkout(c *gin.Context) {
+	var req models.ScanCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
+		return
+	}
+
+	result, err := h.service.Checkout(c.Request.Context(), userClaims.Username, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": result.Message,
+		"data":    result,
+	})
+}
+
+func (h *ScanHandler) Return(c *gin.Context) {
+	var req models.ScanReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	result, err := h.service.Return(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": result.Message,
+		"data":    result,
+	})
+}
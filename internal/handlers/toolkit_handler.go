@@ -8,6 +8,7 @@ import (
 
 	"toolkit-management/internal/models"
 	"toolkit-management/internal/services"
+	"toolkit-management/pkg/auth"
 )
 
 type ToolkitHandler struct {
@@ -21,13 +22,13 @@ func NewToolkitHandler(service services.ToolkitService) *ToolkitHandler {
 func (h *ToolkitHandler) Create(c *gin.Context) {
 	var req models.ToolkitCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Create(&req)
+	result, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -41,13 +42,13 @@ func (h *ToolkitHandler) Create(c *gin.Context) {
 func (h *ToolkitHandler) GetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	toolkit, err := h.service.GetByID(id)
+	toolkit, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Toolkit not found"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -75,39 +76,65 @@ func (h *ToolkitHandler) GetAll(c *gin.Context) {
 			filter.Brand = bodyFilter.Brand
 			filter.MinQuantity = bodyFilter.MinQuantity
 			filter.MaxQuantity = bodyFilter.MaxQuantity
+			if bodyFilter.PaginationMode != "" {
+				filter.PaginationMode = bodyFilter.PaginationMode
+			}
+			if bodyFilter.Cursor != "" {
+				filter.Cursor = bodyFilter.Cursor
+			}
+			if bodyFilter.IncludeTotal {
+				filter.IncludeTotal = true
+			}
+			if bodyFilter.SortBy != "" {
+				filter.SortBy = bodyFilter.SortBy
+			}
+			if bodyFilter.SortOrder != "" {
+				filter.SortOrder = bodyFilter.SortOrder
+			}
 		}
 	}
 
-	toolkitList, err := h.service.GetAll(&filter)
+	toolkitList, err := h.service.GetAll(c.Request.Context(), &filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	var pagination any = toolkitList.Pagination
+	if toolkitList.CursorPagination != nil {
+		pagination = toolkitList.CursorPagination
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
 		"message":    "Toolkits retrieved successfully",
 		"data":       toolkitList.Data,
-		"pagination": toolkitList.Pagination,
+		"pagination": pagination,
 	})
 }
 
 func (h *ToolkitHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
 	var req models.ToolkitUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
 		return
 	}
 
-	result, err := h.service.Update(id, &req)
+	result, err := h.service.Update(c.Request.Context(), id, &req, userClaims)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -121,13 +148,13 @@ func (h *ToolkitHandler) Update(c *gin.Context) {
 func (h *ToolkitHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -140,19 +167,25 @@ func (h *ToolkitHandler) Delete(c *gin.Context) {
 func (h *ToolkitHandler) UpdateStock(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
 	var req models.ToolkitStockUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	userClaims, err := auth.GetCurrentUser(c)
+	if err != nil {
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
 		return
 	}
 
-	result, err := h.service.UpdateStock(id, &req)
+	result, err := h.service.UpdateStock(c.Request.Context(), id, &req, userClaims)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
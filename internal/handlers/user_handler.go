@@ -22,13 +22,13 @@ func NewUserHandler(service services.UserService) *UserHandler {
 func (h *UserHandler) Create(c *gin.Context) {
 	var req models.UserCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Create(&req)
+	result, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -42,13 +42,13 @@ func (h *UserHandler) Create(c *gin.Context) {
 func (h *UserHandler) GetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	user, err := h.service.GetByID(id)
+	user, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "User not found"})
+		_ = c.Error(err)
 		return
 	}
 
@@ -73,39 +73,59 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 			filter.Role = bodyFilter.Role
 			filter.Department = bodyFilter.Department
 			filter.IsActive = bodyFilter.IsActive
+			if bodyFilter.PaginationMode != "" {
+				filter.PaginationMode = bodyFilter.PaginationMode
+			}
+			if bodyFilter.Cursor != "" {
+				filter.Cursor = bodyFilter.Cursor
+			}
+			if bodyFilter.IncludeTotal {
+				filter.IncludeTotal = true
+			}
+			if bodyFilter.SortBy != "" {
+				filter.SortBy = bodyFilter.SortBy
+			}
+			if bodyFilter.SortOrder != "" {
+				filter.SortOrder = bodyFilter.SortOrder
+			}
 		}
 	}
 
-	userList, err := h.service.GetAll(&filter)
+	userList, err := h.service.GetAll(c.Request.Context(), &filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	var pagination any = userList.Pagination
+	if userList.CursorPagination != nil {
+		pagination = userList.CursorPagination
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
 		"message":    "Users retrieved successfully",
 		"data":       userList.Data,
-		"pagination": userList.Pagination,
+		"pagination": pagination,
 	})
 }
 
 func (h *UserHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
 	var req models.UserUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Update(id, &req)
+	result, err := h.service.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -119,13 +139,13 @@ func (h *UserHandler) Update(c *gin.Context) {
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid ID"})
+		_ = c.Error(invalidIDError())
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -138,19 +158,24 @@ func (h *UserHandler) Delete(c *gin.Context) {
 func (h *UserHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
-	result, err := h.service.Login(&req)
+	result, err := h.service.Login(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
+	message := "Login successful"
+	if result.TwoFactor != nil {
+		message = "Two-factor authentication required"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Login successful",
+		"message": message,
 		"data":    result,
 	})
 }
@@ -158,13 +183,13 @@ func (h *UserHandler) Login(c *gin.Context) {
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userClaims, err := auth.GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": err.Error()})
+		_ = c.Error(services.NewUnauthorizedError(err.Error()))
 		return
 	}
 
-	user, err := h.service.GetByID(userClaims.UserID)
+	user, err := h.service.GetByID(c.Request.Context(), userClaims.UserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "User not found"})
+		_ = c.Error(err)
 		return
 	}
 
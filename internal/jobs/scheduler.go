@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Scheduler menjalankan job periodik di goroutine masing-masing dan mencatat status
+// run terakhir untuk health check.
+type Scheduler struct {
+	mu      sync.RWMutex
+	jobs    []*job
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context) error
+
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastError   string
+	lastLatency time.Duration
+}
+
+// JobStatus snapshot status job untuk health view
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Interval    string     `json:"interval"`
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LatencyMs   float64    `json:"latency_ms"`
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every daftarkan job, harus dipanggil sebelum Start
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, fn: fn})
+}
+
+// Start jalankan semua job, masing-masing langsung run sekali lalu per interval
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.running = true
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop batalkan semua job dan tunggu job yang sedang jalan selesai (atau ctx habis)
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler stop: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.run(ctx, j)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	start := time.Now()
+	err := j.fn(ctx)
+	latency := time.Since(start)
+
+	s.mu.Lock()
+	j.lastRun = start
+	j.lastLatency = latency
+	if err != nil {
+		j.lastError = err.Error()
+	} else {
+		j.lastError = ""
+		j.lastSuccess = start
+	}
+	s.mu.Unlock()
+
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Background job failed", "job", j.name, "error", err)
+	}
+}
+
+// Check health: scheduler harus jalan dan tiap job sukses dalam 3x interval terakhir
+func (s *Scheduler) Check(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.running {
+		return fmt.Errorf("scheduler is not running")
+	}
+	for _, j := range s.jobs {
+		if j.lastRun.IsZero() {
+			continue // masih run pertama
+		}
+		if time.Since(j.lastSuccess) > 3*j.interval {
+			return fmt.Errorf("job %s has not succeeded since %s: %s", j.name, j.lastSuccess.Format(time.RFC3339), j.lastError)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := JobStatus{
+			Name:      j.name,
+			Interval:  j.interval.String(),
+			LastError: j.lastError,
+			LatencyMs: float64(j.lastLatency.Microseconds()) / 1000,
+		}
+		if !j.lastRun.IsZero() {
+			lastRun := j.lastRun
+			st.LastRun = &lastRun
+		}
+		if !j.lastSuccess.IsZero() {
+			lastSuccess := j.lastSuccess
+			st.LastSuccess = &lastSuccess
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
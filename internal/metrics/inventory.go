@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"toolkit-management/internal/repositories"
+)
+
+// InventoryCollector gauge domain yang di-refresh per interval oleh scheduler,
+// supaya scrape tidak memicu query agregat ke database.
+type InventoryCollector struct {
+	repo repositories.StatsRepository
+
+	activeLoans         prometheus.Gauge
+	overdueLoans        prometheus.Gauge
+	toolkitsOutOfStock  prometheus.Gauge
+	availableByCategory *prometheus.GaugeVec
+	lastRefresh         prometheus.Gauge
+}
+
+func NewInventoryCollector(m *Metrics, repo repositories.StatsRepository) *InventoryCollector {
+	c := &InventoryCollector{
+		repo: repo,
+		activeLoans: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "loans_active",
+			Help: "Loans that have not been returned.",
+		}),
+		overdueLoans: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "loans_overdue",
+			Help: "Unreturned loans past their due date.",
+		}),
+		toolkitsOutOfStock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "toolkits_out_of_stock",
+			Help: "Non-retired toolkits with no available units.",
+		}),
+		availableByCategory: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "category_available_quantity",
+			Help: "Available toolkit units per category.",
+		}, []string{"category_id", "category"}),
+		lastRefresh: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "inventory_metrics_last_refresh_timestamp_seconds",
+			Help: "Unix time of the last successful inventory gauge refresh.",
+		}),
+	}
+
+	m.Registry.MustRegister(c.activeLoans, c.overdueLoans, c.toolkitsOutOfStock, c.availableByCategory, c.lastRefresh)
+	return c
+}
+
+// Refresh query agregat sekali dan update semua gauge, dijadwalkan lewat jobs.Scheduler
+func (c *InventoryCollector) Refresh(ctx context.Context) error {
+	stats, err := c.repo.GetInventoryStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.activeLoans.Set(float64(stats.ActiveLoans))
+	c.overdueLoans.Set(float64(stats.OverdueLoans))
+	c.toolkitsOutOfStock.Set(float64(stats.ToolkitsOutOfStock))
+
+	// Reset supaya kategori yang dihapus tidak tertinggal
+	c.availableByCategory.Reset()
+	for _, cat := range stats.AvailableByCategory {
+		c.availableByCategory.WithLabelValues(strconv.Itoa(cat.CategoryID), cat.CategoryName).Set(float64(cat.Available))
+	}
+
+	c.lastRefresh.SetToCurrentTime()
+	return nil
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "toolkit"
+
+// Metrics registry Prometheus milik aplikasi (tidak pakai global default registry)
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	HTTPRequestDuration *prometheus.HistogramVec
+	HTTPRequestsTotal   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		Registry: registry,
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		HTTPRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route template, method and status.",
+		}, []string{"method", "route", "status"}),
+	}
+	registry.MustRegister(m.HTTPRequestDuration, m.HTTPRequestsTotal)
+
+	return m
+}
+
+// RegisterDB statistik connection pool dari sql.DB.Stats, dibaca saat scrape (murah)
+func (m *Metrics) RegisterDB(db *sql.DB, dbName string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
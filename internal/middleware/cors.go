@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type CORSOptions struct {
+	// Pola origin: "*", "https://app.example.com", "https://*.example.com", "http://localhost:*"
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS middleware dengan pencocokan origin berbasis pola. Origin yang ditolak di-log
+// dan request-nya dijawab 403 oleh gin-contrib/cors.
+func CORS(opts CORSOptions) (gin.HandlerFunc, error) {
+	patterns, err := ParseOriginPatterns(opts.AllowOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AllowCredentials {
+		for _, p := range patterns {
+			if p.any {
+				return nil, fmt.Errorf("cors: origin \"*\" cannot be combined with credentials")
+			}
+		}
+	}
+
+	return cors.New(cors.Config{
+		AllowOriginWithContextFunc: func(c *gin.Context, origin string) bool {
+			if MatchOrigin(patterns, origin) {
+				return true
+			}
+			slog.WarnContext(c.Request.Context(), "CORS origin rejected",
+				"origin", origin,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+			)
+			return false
+		},
+		AllowMethods:     opts.AllowMethods,
+		AllowHeaders:     opts.AllowHeaders,
+		ExposeHeaders:    opts.ExposeHeaders,
+		AllowCredentials: opts.AllowCredentials,
+		MaxAge:           opts.MaxAge,
+	}), nil
+}
+
+// OriginPattern satu pola origin yang sudah di-parse
+type OriginPattern struct {
+	any    bool
+	scheme string
+	host   string // "*.example.com" untuk wildcard subdomain
+	port   string // "" = port default, "*" = port apa saja
+}
+
+// ParseOriginPatterns validasi dan parse daftar pola origin
+func ParseOriginPatterns(values []string) ([]OriginPattern, error) {
+	patterns := make([]OriginPattern, 0, len(values))
+	for _, value := range values {
+		p, err := parseOriginPattern(value)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+func parseOriginPattern(value string) (OriginPattern, error) {
+	if value == "*" {
+		return OriginPattern{any: true}, nil
+	}
+
+	scheme, host, port, ok := splitOrigin(value)
+	if !ok || (scheme != "http" && scheme != "https") {
+		return OriginPattern{}, fmt.Errorf("cors: invalid origin pattern %q (want scheme://host[:port])", value)
+	}
+
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") || host == "*." {
+		return OriginPattern{}, fmt.Errorf("cors: invalid origin pattern %q (wildcard only allowed as leading subdomain, e.g. https://*.example.com)", value)
+	}
+	if port != "" && port != "*" && strings.Trim(port, "0123456789") != "" {
+		return OriginPattern{}, fmt.Errorf("cors: invalid origin pattern %q (bad port)", value)
+	}
+
+	return OriginPattern{scheme: scheme, host: host, port: port}, nil
+}
+
+// MatchOrigin true jika origin cocok dengan salah satu pola
+func MatchOrigin(patterns []OriginPattern, origin string) bool {
+	scheme, host, port, ok := splitOrigin(origin)
+	if !ok {
+		return false
+	}
+
+	for _, p := range patterns {
+		if p.any {
+			return true
+		}
+		if p.scheme != scheme {
+			continue
+		}
+		if p.port != "*" && p.port != port {
+			continue
+		}
+		if suffix, wildcard := strings.CutPrefix(p.host, "*."); wildcard {
+			// Hanya subdomain, apex domain harus didaftarkan terpisah
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if p.host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOrigin pecah "scheme://host[:port]" (tanpa path) jadi bagian-bagiannya, huruf kecil
+func splitOrigin(origin string) (scheme, host, port string, ok bool) {
+	scheme, rest, found := strings.Cut(strings.ToLower(origin), "://")
+	if !found || scheme == "" || rest == "" || strings.ContainsAny(rest, "/?#@") {
+		return "", "", "", false
+	}
+
+	host = rest
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.HasSuffix(rest, "]") {
+		host, port = rest[:i], rest[i+1:]
+		if port == "" {
+			return "", "", "", false
+		}
+	}
+	if host == "" {
+		return "", "", "", false
+	}
+	return scheme, host, port, true
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/services"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem response error RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code"`
+	Errors   []services.FieldError `json:"errors,omitempty"`
+	Success  bool                  `json:"success"`
+}
+
+var kindStatus = map[services.ErrorKind]int{
+	services.ErrKindNotFound:          http.StatusNotFound,
+	services.ErrKindConflict:          http.StatusConflict,
+	services.ErrKindValidation:        http.StatusBadRequest,
+	services.ErrKindForbidden:         http.StatusForbidden,
+	services.ErrKindUnauthorized:      http.StatusUnauthorized,
+	services.ErrKindInsufficientStock: http.StatusConflict,
+	services.ErrKindTooLarge:          http.StatusRequestEntityTooLarge,
+	services.ErrKindUnsupportedMedia:  http.StatusUnsupportedMediaType,
+}
+
+// ErrorHandler render error yang di-push handler lewat c.Error() sebagai problem+json.
+// Handler cukup `c.Error(err)` lalu return, mapping status ada di sini saja.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		problem := NewProblem(c.Errors.Last().Err, c.Writer.Status())
+		problem.Instance = c.Request.URL.Path
+
+		if problem.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "request failed",
+				"method", c.Request.Method, "path", c.Request.URL.Path, "error", c.Errors.Last().Err)
+		}
+
+		c.Header("Content-Type", problemContentType)
+		c.AbortWithStatusJSON(problem.Status, problem)
+	}
+}
+
+// NewProblem mapping error ke Problem. currentStatus dipakai untuk error tanpa tipe
+// yang sudah set status (mis. c.AbortWithError(401, ...) dari middleware auth).
+func NewProblem(err error, currentStatus int) Problem {
+	var domainErr *services.Error
+	if errors.As(err, &domainErr) {
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return Problem{
+			Type:   problemType(domainErr.Code),
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: domainErr.Message,
+			Code:   domainErr.Code,
+			Errors: domainErr.Fields,
+		}
+	}
+
+	if currentStatus >= http.StatusBadRequest {
+		code := statusCode(currentStatus)
+		return Problem{
+			Type:   problemType(code),
+			Title:  http.StatusText(currentStatus),
+			Status: currentStatus,
+			Detail: err.Error(),
+			Code:   code,
+		}
+	}
+
+	// Jangan bocorkan detail error internal (SQL dll.) ke client
+	return Problem{
+		Type:   problemType("internal_error"),
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "an unexpected error occurred",
+		Code:   "internal_error",
+	}
+}
+
+func problemType(code string) string {
+	return "/problems/" + code
+}
+
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return string(services.ErrKindUnauthorized)
+	case http.StatusForbidden:
+		return string(services.ErrKindForbidden)
+	case http.StatusNotFound:
+		return string(services.ErrKindNotFound)
+	case http.StatusConflict:
+		return string(services.ErrKindConflict)
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	default:
+		return "internal_error"
+	}
+}
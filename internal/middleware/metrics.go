@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/metrics"
+)
+
+// Metrics catat latency dan status per route template (c.FullPath), bukan path mentah,
+// supaya cardinality label tetap kecil.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+		m.HTTPRequestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/ratelimit"
+	"toolkit-management/pkg/auth"
+)
+
+// RateLimiter middleware token bucket per route group. Key per user ID kalau sudah
+// login (pasang setelah RequireAuth), per IP kalau anonim.
+type RateLimiter struct {
+	store    ratelimit.Store
+	policies map[string]ratelimit.Policy
+	enabled  bool
+}
+
+func NewRateLimiter(store ratelimit.Store, enabled bool, policies ...ratelimit.Policy) *RateLimiter {
+	l := &RateLimiter{store: store, enabled: enabled, policies: map[string]ratelimit.Policy{}}
+	for _, p := range policies {
+		l.policies[p.Name] = p
+	}
+	return l
+}
+
+// Group middleware untuk policy dengan nama group
+func (l *RateLimiter) Group(name string) gin.HandlerFunc {
+	policy, ok := l.policies[name]
+	if !l.enabled || !ok {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		key, role := rateLimitKey(c)
+		limit := policy.LimitFor(role)
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		result, err := l.store.Take(c.Request.Context(), policy.Name+":"+key, limit)
+		if err != nil {
+			// Store bermasalah jangan sampai mematikan API, fail open
+			slog.ErrorContext(c.Request.Context(), "rate limit store failed", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d", limit.RequestsPerMinute, result.Limit))
+		h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ratelimit.Seconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := max(ratelimit.Seconds(result.RetryAfter), 1)
+			h.Set("Retry-After", strconv.Itoa(retryAfter))
+			slog.WarnContext(c.Request.Context(), "rate limit exceeded", "policy", policy.Name, "key", key)
+
+			c.Status(http.StatusTooManyRequests)
+			_ = c.Error(fmt.Errorf("rate limit exceeded, retry in %d seconds", retryAfter))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKey(c *gin.Context) (key, role string) {
+	if claims, err := auth.GetCurrentUser(c); err == nil {
+		return "user:" + strconv.Itoa(claims.UserID), claims.Role
+	}
+	return "ip:" + c.ClientIP(), ""
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// Scope API token
+const (
+	ScopeRead  = "read"  // GET/HEAD
+	ScopeWrite = "write" // POST/PUT/PATCH/DELETE
+	ScopeAdmin = "admin" // route admin, hanya untuk pemilik ber-role admin
+)
+
+// APIToken personal access token untuk script/service account.
+// Token plaintext hanya ditampilkan sekali saat dibuat, yang disimpan hanya hash SHA-256.
+type APIToken struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     int        `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// HasScope true jika token punya scope tersebut
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APITokenCreateRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=read write admin"`
+	// Masa berlaku dalam hari, default dan batas atas dari config
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1"`
+}
+
+// APITokenCreateResponse satu-satunya response yang memuat token plaintext
+type APITokenCreateResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
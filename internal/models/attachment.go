@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"mime/multipart"
+	"time"
+)
+
+// Jenis attachment toolkit
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+)
+
+// Jenis dokumen
+const (
+	DocumentManual      = "manual"
+	DocumentDatasheet   = "datasheet"
+	DocumentCertificate = "certificate"
+	DocumentOther       = "other"
+)
+
+// Attachment foto atau dokumen toolkit. File disimpan di storage (local/S3) dengan StorageKey,
+// baris ikut terhapus bersama toolkit dan object-nya dibersihkan oleh service.
+type Attachment struct {
+	ID           int    `json:"id" gorm:"primaryKey"`
+	ToolkitID    int    `json:"toolkit_id" gorm:"not null;index"`
+	Kind         string `json:"kind" gorm:"not null"`
+	DocumentType string `json:"document_type,omitempty"`
+	Title        string `json:"title"`
+	FileName     string `json:"file_name"`
+	// ContentType hasil sniffing isi file, bukan header dari client
+	ContentType   string    `json:"content_type" gorm:"not null"`
+	Size          int64     `json:"size" gorm:"not null"`
+	Width         int       `json:"width,omitempty"`
+	Height        int       `json:"height,omitempty"`
+	StorageKey    string    `json:"-" gorm:"not null;uniqueIndex"`
+	ThumbnailKey  string    `json:"-"`
+	ThumbnailSize int64     `json:"-"`
+	UploadedBy    *int      `json:"uploaded_by"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	URL          string `json:"url" gorm:"-"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" gorm:"-"`
+
+	Toolkit *Toolkit `json:"-" gorm:"foreignKey:ToolkitID;constraint:OnDelete:CASCADE"`
+}
+
+// AttachmentContentURL path download isi file lewat API
+func AttachmentContentURL(id int, thumbnail bool) string {
+	if thumbnail {
+		return fmt.Sprintf("/api/attachments/%d/content?thumbnail=true", id)
+	}
+	return fmt.Sprintf("/api/attachments/%d/content", id)
+}
+
+// AttachmentUploadRequest form multipart/form-data
+type AttachmentUploadRequest struct {
+	File         *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+	Kind         string                `json:"kind" form:"kind" binding:"required,oneof=photo document"`
+	DocumentType string                `json:"document_type,omitempty" form:"document_type" binding:"omitempty,oneof=manual datasheet certificate other"`
+	Title        string                `json:"title,omitempty" form:"title" binding:"max=200"`
+	// Primary jadikan foto ini Toolkit.PrimaryImageID. Foto pertama otomatis jadi primary.
+	Primary bool `json:"primary,omitempty" form:"primary"`
+}
+
+type AttachmentContentRequest struct {
+	Thumbnail bool `json:"thumbnail,omitempty" form:"thumbnail"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import "time"
+
+// Status bundle loan, dihitung dari loan komponennya
+const (
+	BundleLoanBorrowed          = "borrowed"
+	BundleLoanPartiallyReturned = "partially_returned"
+	BundleLoanReturned          = "returned"
+)
+
+// Bundle kit berisi beberapa toolkit, mis. fiber splicing kit: splicer, cleaver, OTDR, consumable.
+// Stok tetap dicatat per toolkit; ketersediaan bundle dihitung dari komponennya.
+type Bundle struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	Code        string    `json:"code" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Components []BundleComponent `json:"components" gorm:"foreignKey:BundleID;constraint:OnDelete:CASCADE"`
+	// Available jumlah set lengkap yang bisa dipinjam sekarang
+	Available int `json:"available" gorm:"-"`
+}
+
+// BundleComponent jumlah unit satu toolkit dalam satu set bundle
+type BundleComponent struct {
+	ID        int `json:"id" gorm:"primaryKey"`
+	BundleID  int `json:"bundle_id" gorm:"not null;uniqueIndex:idx_bundle_component"`
+	ToolkitID int `json:"toolkit_id" gorm:"not null;uniqueIndex:idx_bundle_component"`
+	Quantity  int `json:"quantity" gorm:"not null;default:1"`
+
+	Toolkit *Toolkit `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID;constraint:OnDelete:CASCADE"`
+}
+
+// BundleLoan loan induk checkout bundle. Tiap komponen tetap punya Loan sendiri
+// (Loan.BundleLoanID) supaya stok, overdue dan incident berjalan seperti loan biasa.
+type BundleLoan struct {
+	ID       int  `json:"id" gorm:"primaryKey"`
+	BundleID *int `json:"bundle_id" gorm:"index"`
+	// BundleName disimpan supaya riwayat tetap terbaca walau bundle dihapus
+	BundleName string    `json:"bundle_name" gorm:"not null"`
+	UserID     int       `json:"user_id" gorm:"not null;index"`
+	Quantity   int       `json:"quantity" gorm:"not null;default:1"`
+	Purpose    string    `json:"purpose"`
+	BorrowDate time.Time `json:"borrow_date"`
+	DueDate    time.Time `json:"due_date" gorm:"not null"`
+	ApprovedBy string    `json:"approved_by"`
+	Notes      string    `json:"notes"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Bundle *Bundle `json:"-" gorm:"foreignKey:BundleID;constraint:OnDelete:SET NULL"`
+	User   *User   `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Loans  []Loan  `json:"loans" gorm:"foreignKey:BundleLoanID"`
+	// Status borrowed, partially_returned atau returned
+	Status string `json:"status" gorm:"-"`
+}
+
+type BundleComponentRequest struct {
+	ToolkitID int `json:"toolkit_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
+
+type BundleCreateRequest struct {
+	Name        string                   `json:"name" binding:"required,max=255"`
+	Code        string                   `json:"code" binding:"required,max=100"`
+	Description string                   `json:"description"`
+	Components  []BundleComponentRequest `json:"components" binding:"required,min=1,max=100,dive"`
+}
+
+// BundleUpdateRequest components diisi = daftar komponen diganti seluruhnya
+type BundleUpdateRequest struct {
+	Name        string                   `json:"name,omitempty" binding:"max=255"`
+	Code        string                   `json:"code,omitempty" binding:"max=100"`
+	Description string                   `json:"description,omitempty"`
+	IsActive    *bool                    `json:"is_active,omitempty"`
+	Components  []BundleComponentRequest `json:"components,omitempty" binding:"omitempty,min=1,max=100,dive"`
+}
+
+type BundleFilterRequest struct {
+	SearchTerm string `json:"search_term,omitempty" form:"search_term"`
+	IsActive   *bool  `json:"is_active,omitempty" form:"is_active"`
+}
+
+// BundleCheckoutRequest pinjam Quantity set bundle sekaligus
+type BundleCheckoutRequest struct {
+	UserID           int       `json:"user_id" binding:"required"`
+	Quantity         int       `json:"quantity,omitempty" binding:"omitempty,min=1"`
+	Purpose          string    `json:"purpose" binding:"required"`
+	DueDate          time.Time `json:"due_date" binding:"required"`
+	ApprovedBy       string    `json:"approved_by"`
+	Notes            string    `json:"notes"`
+	ConditionChecked string    `json:"condition_checked"`
+	// LocationID lokasi asal semua komponen, kosong = stok yang belum ditempatkan
+	LocationID *int `json:"location_id"`
+}
+
+type BundleReturnItem struct {
+	LoanID int `json:"loan_id" binding:"required"`
+	// Quantity kosong = seluruh unit loan komponen
+	Quantity int `json:"quantity,omitempty" binding:"omitempty,min=1"`
+}
+
+// BundleReturnRequest items kosong = semua komponen yang masih dipinjam dikembalikan
+type BundleReturnRequest struct {
+	Items           []BundleReturnItem `json:"items,omitempty" binding:"omitempty,max=100,dive"`
+	ConditionReturn string             `json:"condition_return"`
+	Notes           string             `json:"notes"`
+	// ReturnLocationID default lokasi asal tiap loan komponen
+	ReturnLocationID *int `json:"return_location_id,omitempty"`
+}
+
+type BundleLoanFilterRequest struct {
+	UserID   int  `json:"user_id,omitempty" form:"user_id"`
+	BundleID int  `json:"bundle_id,omitempty" form:"bundle_id"`
+	Active   bool `json:"active,omitempty" form:"active"`
+}
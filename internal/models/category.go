@@ -14,6 +14,11 @@ type Category struct {
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
+	// Default depresiasi toolkit di kategori ini, SalvagePercent persen dari harga beli
+	DepreciationMethod string  `json:"depreciation_method" gorm:"not null;default:straight_line"`
+	UsefulLifeMonths   int     `json:"useful_life_months" gorm:"not null;default:60"`
+	SalvagePercent     float64 `json:"salvage_percent" gorm:"not null;default:0"`
+
 	Toolkits []Toolkit  `json:"toolkits,omitempty" gorm:"foreignKey:CategoryID"`
 }
 
@@ -26,6 +31,10 @@ type CategoryCreateRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
 	SortOrder   int    `json:"sort_order"`
+
+	DepreciationMethod string  `json:"depreciation_method" binding:"omitempty,oneof=straight_line declining_balance"`
+	UsefulLifeMonths   int     `json:"useful_life_months" binding:"omitempty,min=1,max=600"`
+	SalvagePercent     float64 `json:"salvage_percent" binding:"omitempty,min=0,max=100"`
 }
 
 type CategoryUpdateRequest struct {
@@ -33,4 +42,8 @@ type CategoryUpdateRequest struct {
 	Description string `json:"description,omitempty"`
 	SortOrder   int    `json:"sort_order,omitempty"`
 	IsActive    *bool  `json:"is_active,omitempty"`
+
+	DepreciationMethod string   `json:"depreciation_method,omitempty" binding:"omitempty,oneof=straight_line declining_balance"`
+	UsefulLifeMonths   int      `json:"useful_life_months,omitempty" binding:"omitempty,min=1,max=600"`
+	SalvagePercent     *float64 `json:"salvage_percent,omitempty" binding:"omitempty,min=0,max=100"`
 }
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+)
+
+// Jenis incident
+const (
+	IncidentDamaged = "damaged"
+	IncidentLost    = "lost"
+)
+
+// Status incident: open -> assessed -> resolved
+const (
+	IncidentOpen     = "open"
+	IncidentAssessed = "assessed"
+	IncidentResolved = "resolved"
+)
+
+// Resolusi incident
+const (
+	ResolutionRepaired            = "repaired"
+	ResolutionWrittenOff          = "written_off"
+	ResolutionChargedToDepartment = "charged_to_department"
+)
+
+// Incident unit rusak atau hilang dari sebuah loan. Selama belum resolved, unit tidak masuk
+// Toolkit.Available. Resolusi repaired mengembalikan unit ke stok, written_off dan
+// charged_to_department mengurangi Toolkit.Quantity lewat StockAdjustment.
+type Incident struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	LoanID      int    `json:"loan_id" gorm:"not null;index"`
+	ToolkitID   int    `json:"toolkit_id" gorm:"not null;index"`
+	UserID      int    `json:"user_id" gorm:"not null;index"`
+	Type        string `json:"type" gorm:"not null"`
+	Quantity    int    `json:"quantity" gorm:"not null"`
+	Description string `json:"description"`
+	Status      string `json:"status" gorm:"not null;default:open;index"`
+	// HeldFromStock unit ditarik dari Available saat incident dibuat (loan sudah returned)
+	HeldFromStock bool `json:"held_from_stock"`
+	ReportedBy    *int `json:"reported_by"`
+	// LocationID lokasi stok unit incident: lokasi asal loan, atau lokasi pengembalian kalau
+	// unit ditarik dari stok. Kosong = stok yang belum ditempatkan.
+	LocationID *int `json:"location_id"`
+
+	// ReplacementCost PurchasePrice x Quantity saat incident dibuat, batas atas AssessedCost
+	ReplacementCost float64    `json:"replacement_cost"`
+	AssessedCost    *float64   `json:"assessed_cost"`
+	AssessedBy      *int       `json:"assessed_by"`
+	AssessedAt      *time.Time `json:"assessed_at"`
+	AssessmentNotes string     `json:"assessment_notes"`
+
+	Resolution        string     `json:"resolution,omitempty"`
+	ResolutionNotes   string     `json:"resolution_notes"`
+	ChargedDepartment string     `json:"charged_department,omitempty"`
+	ResolvedBy        *int       `json:"resolved_by"`
+	ResolvedAt        *time.Time `json:"resolved_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	Evidence []IncidentEvidence `json:"evidence,omitempty" gorm:"foreignKey:IncidentID;constraint:OnDelete:CASCADE"`
+	Loan     *Loan              `json:"loan,omitempty" gorm:"foreignKey:LoanID"`
+	Toolkit  *Toolkit           `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID"`
+	User     *User              `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// IncidentEvidence lampiran bukti (foto, laporan) untuk incident
+type IncidentEvidence struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	IncidentID  int       `json:"incident_id" gorm:"not null;index"`
+	URL         string    `json:"url" gorm:"not null"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Description string    `json:"description"`
+	UploadedBy  int       `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Reason perpindahan stok antar lokasi, dicatat sebagai StockAdjustment dengan LocationID
+const (
+	MovementPlacement    = "placement"
+	MovementLoanCheckout = "loan_checkout"
+	MovementLoanReturn   = "loan_return"
+	MovementTransferOut  = "transfer_out"
+	MovementTransferIn   = "transfer_in"
+)
+
+// StockAdjustment catatan perubahan stok. Tanpa LocationID = perubahan Toolkit.Quantity;
+// dengan LocationID = unit masuk/keluar lokasi itu (penempatan, loan, transfer), total tidak berubah.
+type StockAdjustment struct {
+	ID             int       `json:"id" gorm:"primaryKey"`
+	ToolkitID      int       `json:"toolkit_id" gorm:"not null;index"`
+	LocationID     *int      `json:"location_id,omitempty" gorm:"index"`
+	QuantityChange int       `json:"quantity_change" gorm:"not null"`
+	Reason         string    `json:"reason" gorm:"not null"`
+	ReferenceType  string    `json:"reference_type,omitempty"`
+	ReferenceID    *int      `json:"reference_id,omitempty"`
+	Notes          string    `json:"notes"`
+	CreatedBy      *int      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type IncidentCreateRequest struct {
+	Type string `json:"type" binding:"required,oneof=damaged lost"`
+	// Quantity kosong = seluruh unit loan
+	Quantity    int    `json:"quantity" binding:"omitempty,min=1"`
+	Description string `json:"description" binding:"required"`
+}
+
+type IncidentFilterRequest struct {
+	LoanID    int    `json:"loan_id,omitempty" form:"loan_id"`
+	ToolkitID int    `json:"toolkit_id,omitempty" form:"toolkit_id"`
+	UserID    int    `json:"user_id,omitempty" form:"user_id"`
+	Type      string `json:"type,omitempty" form:"type" binding:"omitempty,oneof=damaged lost"`
+	Status    string `json:"status,omitempty" form:"status" binding:"omitempty,oneof=open assessed resolved"`
+}
+
+type IncidentEvidenceRequest struct {
+	URL         string `json:"url" binding:"required,url"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Description string `json:"description"`
+}
+
+type IncidentAssessRequest struct {
+	// AssessedCost kosong = ReplacementCost
+	AssessedCost *float64 `json:"assessed_cost" binding:"omitempty,min=0"`
+	Notes        string   `json:"notes"`
+}
+
+type IncidentResolveRequest struct {
+	Resolution string `json:"resolution" binding:"required,oneof=repaired written_off charged_to_department"`
+	Notes      string `json:"notes"`
+}
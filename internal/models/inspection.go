@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+)
+
+// Jenis inspeksi pada siklus peminjaman
+const (
+	InspectionCheckout = "checkout"
+	InspectionReturn   = "return"
+)
+
+// conditionRank urutan kondisi toolkit, makin besar makin baik
+var conditionRank = map[string]int{
+	"poor":      1,
+	"fair":      2,
+	"good":      3,
+	"excellent": 4,
+}
+
+// ConditionDropped true kalau kondisi after lebih buruk dari before. Kondisi tak dikenal tidak dibandingkan.
+func ConditionDropped(before, after string) bool {
+	b, okBefore := conditionRank[before]
+	a, okAfter := conditionRank[after]
+	return okBefore && okAfter && a < b
+}
+
+// ChecklistItem satu poin pemeriksaan untuk semua toolkit dalam satu kategori
+type ChecklistItem struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CategoryID  int       `json:"category_id" gorm:"not null;index"`
+	Label       string    `json:"label" gorm:"not null"`
+	Description string    `json:"description"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Inspection hasil pemeriksaan saat checkout atau return, maksimal satu per jenis per loan
+type Inspection struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	LoanID      int    `json:"loan_id" gorm:"not null;uniqueIndex:idx_inspection_loan_kind"`
+	ToolkitID   int    `json:"toolkit_id" gorm:"not null;index"`
+	Kind        string `json:"kind" gorm:"not null;uniqueIndex:idx_inspection_loan_kind"`
+	Condition   string `json:"condition" gorm:"not null"`
+	Passed      bool   `json:"passed"`
+	InspectorID int    `json:"inspector_id"`
+	Notes       string `json:"notes"`
+	// Return dengan kondisi turun atau item gagal yang sebelumnya lolos perlu direview staff
+	NeedsReview bool       `json:"needs_review" gorm:"index"`
+	ReviewedBy  *int       `json:"reviewed_by"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	ReviewNotes string     `json:"review_notes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Results   []InspectionResult `json:"results,omitempty" gorm:"foreignKey:InspectionID;constraint:OnDelete:CASCADE"`
+	Loan      *Loan              `json:"loan,omitempty" gorm:"foreignKey:LoanID;constraint:OnDelete:CASCADE"`
+	Inspector *User              `json:"inspector,omitempty" gorm:"foreignKey:InspectorID"`
+}
+
+// InspectionResult label disalin supaya hasil lama tetap terbaca walau checklist diubah
+type InspectionResult struct {
+	ID              int    `json:"id" gorm:"primaryKey"`
+	InspectionID    int    `json:"inspection_id" gorm:"not null;index"`
+	ChecklistItemID int    `json:"checklist_item_id"`
+	Label           string `json:"label" gorm:"not null"`
+	Passed          bool   `json:"passed"`
+	Notes           string `json:"notes"`
+}
+
+type ChecklistItemCreateRequest struct {
+	Label       string `json:"label" binding:"required"`
+	Description string `json:"description"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+type ChecklistItemUpdateRequest struct {
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+	SortOrder   *int   `json:"sort_order,omitempty"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+}
+
+type InspectionItemRequest struct {
+	ChecklistItemID int    `json:"checklist_item_id" binding:"required"`
+	Passed          bool   `json:"passed"`
+	Notes           string `json:"notes"`
+}
+
+// InspectionCreateRequest semua item checklist aktif kategori toolkit wajib diisi
+type InspectionCreateRequest struct {
+	Kind      string                  `json:"kind" binding:"required,oneof=checkout return"`
+	Condition string                  `json:"condition" binding:"required,oneof=excellent good fair poor"`
+	Items     []InspectionItemRequest `json:"items" binding:"dive"`
+	Notes     string                  `json:"notes"`
+}
+
+type InspectionFilterRequest struct {
+	LoanID    int    `json:"loan_id,omitempty" form:"loan_id"`
+	ToolkitID int    `json:"toolkit_id,omitempty" form:"toolkit_id"`
+	Kind      string `json:"kind,omitempty" form:"kind" binding:"omitempty,oneof=checkout return"`
+	// ReviewPending hanya yang ditandai perlu review dan belum direview
+	ReviewPending bool `json:"review_pending,omitempty" form:"review_pending"`
+}
+
+type InspectionReviewRequest struct {
+	Notes string `json:"notes"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// Isi kode yang di-encode ke label
+const (
+	LabelContentSKU          = "sku"
+	LabelContentSerialNumber = "serial_number"
+)
+
+// LabelOptions ukuran, simbologi dan isi kode label
+type LabelOptions struct {
+	// Size preset printer label, mis. 62x29 (Brother DK-11209). Diabaikan kalau width_mm & height_mm diisi.
+	Size      string  `json:"size,omitempty" form:"size"`
+	WidthMM   float64 `json:"width_mm,omitempty" form:"width_mm" binding:"omitempty,min=15,max=210"`
+	HeightMM  float64 `json:"height_mm,omitempty" form:"height_mm" binding:"omitempty,min=10,max=297"`
+	Symbology string  `json:"symbology,omitempty" form:"symbology" binding:"omitempty,oneof=qr code128"`
+	Content   string  `json:"content,omitempty" form:"content" binding:"omitempty,oneof=sku serial_number"`
+	DPI       int     `json:"dpi,omitempty" form:"dpi" binding:"omitempty,min=150,max=600"`
+}
+
+type LabelRequest struct {
+	LabelOptions
+	Format string `json:"format,omitempty" form:"format" binding:"omitempty,oneof=png pdf"`
+}
+
+type LabelBatchItem struct {
+	ToolkitID int `json:"toolkit_id" binding:"required"`
+	Copies    int `json:"copies,omitempty" binding:"omitempty,min=1,max=100"`
+}
+
+// LabelBatchRequest selalu menghasilkan PDF
+type LabelBatchRequest struct {
+	LabelOptions
+	Items []LabelBatchItem `json:"items" binding:"required,min=1,max=200,dive"`
+	// Layout roll (satu halaman per label, default) atau a4 (grid di lembar stiker A4)
+	Layout string `json:"layout,omitempty" binding:"omitempty,oneof=roll a4"`
+}
@@ -18,12 +18,18 @@ type Loan struct {
 	Notes            string     `json:"notes"`
 	ConditionChecked string     `json:"condition_checked"`
 	ConditionReturn  string     `json:"condition_return"`
+	BundleLoanID     *int       `json:"bundle_loan_id,omitempty" gorm:"index"`
+	LocationID       *int       `json:"location_id" gorm:"index"`
+	ReturnLocationID *int       `json:"return_location_id"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 	DeletedAt        *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
 	User    User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Toolkit Toolkit `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID"`
+	// Lokasi asal dan lokasi tempat unit dikembalikan, kosong = stok yang belum ditempatkan
+	Location       *Location `json:"location,omitempty" gorm:"foreignKey:LocationID;constraint:OnDelete:SET NULL"`
+	ReturnLocation *Location `json:"return_location,omitempty" gorm:"foreignKey:ReturnLocationID;constraint:OnDelete:SET NULL"`
 }
 
 type LoanFilterRequest struct {
@@ -46,6 +52,8 @@ type LoanCreateRequest struct {
 	ApprovedBy       string    `json:"approved_by"`
 	Notes            string    `json:"notes"`
 	ConditionChecked string    `json:"condition_checked"`
+	// LocationID lokasi asal unit; wajib kalau semua stok toolkit sudah ditempatkan di lokasi
+	LocationID *int `json:"location_id"`
 }
 
 type LoanUpdateRequest struct {
@@ -61,4 +69,6 @@ type LoanUpdateRequest struct {
 	Notes            string     `json:"notes,omitempty"`
 	ConditionChecked string     `json:"condition_checked,omitempty"`
 	ConditionReturn  string     `json:"condition_return,omitempty"`
+	// ReturnLocationID dipakai saat status jadi returned, default lokasi asal loan
+	ReturnLocationID *int `json:"return_location_id,omitempty"`
 }
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// Jenis lokasi, dari yang terluas. Lokasi hanya boleh berada di bawah jenis yang lebih luas.
+const (
+	LocationWarehouse = "warehouse"
+	LocationShelf     = "shelf"
+	LocationBin       = "bin"
+)
+
+// Location gudang/depot, rak atau bin. Stok per lokasi dicatat di ToolkitStock;
+// Toolkit.Quantity/Available tetap total seluruh lokasi plus stok yang belum ditempatkan.
+type Location struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	Code        string    `json:"code" gorm:"uniqueIndex;not null"`
+	Type        string    `json:"type" gorm:"not null;index"`
+	ParentID    *int      `json:"parent_id" gorm:"index"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Parent   *Location  `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:RESTRICT"`
+	Children []Location `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+}
+
+// ToolkitStock jumlah unit satu toolkit di satu lokasi. Available berkurang saat dipinjam
+// dari lokasi ini dan bertambah di lokasi tempat unit dikembalikan.
+type ToolkitStock struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	ToolkitID  int       `json:"toolkit_id" gorm:"not null;uniqueIndex:idx_toolkit_location"`
+	LocationID int       `json:"location_id" gorm:"not null;uniqueIndex:idx_toolkit_location;index"`
+	Quantity   int       `json:"quantity" gorm:"not null;default:0"`
+	Available  int       `json:"available" gorm:"not null;default:0"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Toolkit  *Toolkit  `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID;constraint:OnDelete:CASCADE"`
+	Location *Location `json:"location,omitempty" gorm:"foreignKey:LocationID;constraint:OnDelete:CASCADE"`
+}
+
+type LocationCreateRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Code        string `json:"code" binding:"required,max=100"`
+	Type        string `json:"type" binding:"required,oneof=warehouse shelf bin"`
+	ParentID    *int   `json:"parent_id"`
+	Description string `json:"description"`
+}
+
+type LocationUpdateRequest struct {
+	Name        string `json:"name,omitempty" binding:"max=255"`
+	Code        string `json:"code,omitempty" binding:"max=100"`
+	Type        string `json:"type,omitempty" binding:"omitempty,oneof=warehouse shelf bin"`
+	ParentID    *int   `json:"parent_id,omitempty"`
+	Description string `json:"description,omitempty"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+}
+
+type LocationFilterRequest struct {
+	Type       string `json:"type,omitempty" form:"type" binding:"omitempty,oneof=warehouse shelf bin"`
+	ParentID   int    `json:"parent_id,omitempty" form:"parent_id"`
+	SearchTerm string `json:"search_term,omitempty" form:"search_term"`
+}
+
+// LocationStockRequest set jumlah unit toolkit yang ditempatkan di lokasi
+type LocationStockRequest struct {
+	Quantity int `json:"quantity" binding:"min=0"`
+}
+
+type LocationStockFilterRequest struct {
+	// IncludeChildren stok gudang termasuk semua rak dan bin di bawahnya
+	IncludeChildren bool `json:"include_children,omitempty" form:"include_children"`
+	ToolkitID       int  `json:"toolkit_id,omitempty" form:"toolkit_id"`
+}
+
+// LocationStockLine stok satu toolkit di lokasi (atau gabungan sub-lokasinya)
+type LocationStockLine struct {
+	ToolkitID int    `json:"toolkit_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Available int    `json:"available"`
+}
+
+// StockLevel jumlah dan ketersediaan unit
+type StockLevel struct {
+	Quantity  int `json:"quantity"`
+	Available int `json:"available"`
+}
+
+// ToolkitAvailability stok toolkit per lokasi plus total keseluruhan
+type ToolkitAvailability struct {
+	ToolkitID int        `json:"toolkit_id"`
+	SKU       string     `json:"sku"`
+	Total     StockLevel `json:"total"`
+	// Unassigned unit yang belum ditempatkan di lokasi mana pun
+	Unassigned StockLevel `json:"unassigned"`
+	// InTransit unit dalam transfer, sudah keluar dari lokasi asal dan belum diterima
+	InTransit int            `json:"in_transit"`
+	Locations []ToolkitStock `json:"locations"`
+}
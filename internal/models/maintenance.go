@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+)
+
+// Jenis maintenance
+const (
+	MaintenancePreventive  = "preventive"
+	MaintenanceCorrective  = "corrective"
+	MaintenanceCalibration = "calibration"
+	MaintenanceInspection  = "inspection"
+)
+
+// Status maintenance record: scheduled -> in_progress -> completed, atau cancelled
+const (
+	MaintenanceScheduled  = "scheduled"
+	MaintenanceInProgress = "in_progress"
+	MaintenanceCompleted  = "completed"
+	MaintenanceCancelled  = "cancelled"
+)
+
+// MaintenanceRecord satu pekerjaan maintenance/perbaikan. Selama in_progress, Quantity unit
+// dikeluarkan dari Toolkit.Available dan dikembalikan saat selesai atau dibatalkan. Unit diambil
+// dari stok LocationID, kosong = stok yang belum ditempatkan di lokasi mana pun.
+type MaintenanceRecord struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	ToolkitID     int        `json:"toolkit_id" gorm:"not null;index"`
+	ScheduleID    *int       `json:"schedule_id" gorm:"index"`
+	Type          string     `json:"type" gorm:"not null"`
+	Status        string     `json:"status" gorm:"not null;default:scheduled;index"`
+	Quantity      int        `json:"quantity" gorm:"not null;default:1"`
+	LocationID    *int       `json:"location_id" gorm:"index"`
+	ScheduledDate time.Time  `json:"scheduled_date" gorm:"not null;index"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	TechnicianID  *int       `json:"technician_id"`
+	Cost          float64    `json:"cost"`
+	Notes         string     `json:"notes"`
+	CreatedBy     int        `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Toolkit    Toolkit `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID;constraint:OnDelete:CASCADE"`
+	Technician *User   `json:"technician,omitempty" gorm:"foreignKey:TechnicianID"`
+}
+
+// MaintenanceSchedule maintenance preventif berulang. Record berikutnya dibuat otomatis
+// IntervalDays setelah record sebelumnya selesai atau dibatalkan.
+type MaintenanceSchedule struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	ToolkitID    int       `json:"toolkit_id" gorm:"not null;index"`
+	Type         string    `json:"type" gorm:"not null"`
+	IntervalDays int       `json:"interval_days" gorm:"not null"`
+	Quantity     int       `json:"quantity" gorm:"not null;default:1"`
+	LocationID   *int      `json:"location_id"`
+	NextDueDate  time.Time `json:"next_due_date" gorm:"not null"`
+	TechnicianID *int      `json:"technician_id"`
+	Notes        string    `json:"notes"`
+	IsActive     bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Toolkit Toolkit `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID;constraint:OnDelete:CASCADE"`
+}
+
+type MaintenanceFilterRequest struct {
+	ToolkitID    int    `json:"toolkit_id,omitempty" form:"toolkit_id"`
+	TechnicianID int    `json:"technician_id,omitempty" form:"technician_id"`
+	Type         string `json:"type,omitempty" form:"type" binding:"omitempty,oneof=preventive corrective calibration inspection"`
+	Status       string `json:"status,omitempty" form:"status" binding:"omitempty,oneof=scheduled in_progress completed cancelled"`
+}
+
+type MaintenanceCreateRequest struct {
+	Type          string    `json:"type" binding:"required,oneof=preventive corrective calibration inspection"`
+	Quantity      int       `json:"quantity" binding:"omitempty,min=1"`
+	LocationID    *int      `json:"location_id" binding:"omitempty,min=1"`
+	ScheduledDate time.Time `json:"scheduled_date"`
+	TechnicianID  *int      `json:"technician_id"`
+	Notes         string    `json:"notes"`
+	// StartNow langsung in_progress, unit langsung dikeluarkan dari stok tersedia
+	StartNow bool `json:"start_now"`
+}
+
+type MaintenanceCompleteRequest struct {
+	Cost  float64 `json:"cost" binding:"omitempty,min=0"`
+	Notes string  `json:"notes"`
+	// Condition kondisi toolkit setelah maintenance, kosong = tidak berubah
+	Condition string `json:"condition" binding:"omitempty,oneof=excellent good fair poor"`
+}
+
+type MaintenanceCancelRequest struct {
+	Notes string `json:"notes"`
+}
+
+type MaintenanceScheduleCreateRequest struct {
+	Type         string `json:"type" binding:"required,oneof=preventive corrective calibration inspection"`
+	IntervalDays int    `json:"interval_days" binding:"required,min=1"`
+	Quantity     int    `json:"quantity" binding:"omitempty,min=1"`
+	LocationID   *int   `json:"location_id" binding:"omitempty,min=1"`
+	// FirstDueDate kosong = hari ini + interval
+	FirstDueDate *time.Time `json:"first_due_date"`
+	TechnicianID *int       `json:"technician_id"`
+	Notes        string     `json:"notes"`
+}
+
+type MaintenanceScheduleUpdateRequest struct {
+	IntervalDays int    `json:"interval_days,omitempty" binding:"omitempty,min=1"`
+	Quantity     int    `json:"quantity,omitempty" binding:"omitempty,min=1"`
+	TechnicianID *int   `json:"technician_id,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+	IsActive     *bool  `json:"is_active,omitempty"`
+}
+
+type MaintenanceDueRequest struct {
+	// WithinDays jendela "upcoming" dari hari ini, default 30
+	WithinDays int `json:"within_days,omitempty" form:"within_days" binding:"omitempty,min=1,max=365"`
+}
+
+// MaintenanceDueResponse maintenance yang belum dikerjakan, dipisah overdue dan upcoming
+type MaintenanceDueResponse struct {
+	Overdue  []MaintenanceRecord `json:"overdue"`
+	Upcoming []MaintenanceRecord `json:"upcoming"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Aksi hasil scan
+const (
+	ScanActionCheckout = "checkout"
+	ScanActionReturn   = "return"
+)
+
+// ScanCheckoutRequest checkout dari handheld scanner: cukup kode item dan identitas peminjam
+type ScanCheckoutRequest struct {
+	// ItemCode SKU atau serial number hasil scan label
+	ItemCode string `json:"item_code" binding:"required,max=100"`
+	// Borrower nomor badge, username atau email peminjam
+	Borrower string `json:"borrower" binding:"required,max=100"`
+	// Quantity default 1
+	Quantity int `json:"quantity,omitempty" binding:"omitempty,min=1"`
+	// DueDate kosong = mengikuti kebijakan lama pinjam per role
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	Purpose          string     `json:"purpose,omitempty" binding:"max=255"`
+	Notes            string     `json:"notes,omitempty"`
+	ConditionChecked string     `json:"condition_checked,omitempty"`
+	// LocationID lokasi scanner, asal unit yang dipinjam
+	LocationID *int `json:"location_id,omitempty"`
+}
+
+// ScanReturnRequest pengembalian lewat scanner, loan aktif terlama milik peminjam untuk item itu yang ditutup
+type ScanReturnRequest struct {
+	ItemCode        string `json:"item_code" binding:"required,max=100"`
+	Borrower        string `json:"borrower" binding:"required,max=100"`
+	ConditionReturn string `json:"condition_return,omitempty"`
+	Notes           string `json:"notes,omitempty"`
+	// LocationID lokasi scanner, default lokasi asal loan
+	LocationID *int `json:"location_id,omitempty"`
+}
+
+// ScanResult respons ringkas untuk layar scanner
+type ScanResult struct {
+	Action    string    `json:"action"`
+	LoanID    int       `json:"loan_id"`
+	SKU       string    `json:"sku"`
+	ItemName  string    `json:"item_name"`
+	Borrower  string    `json:"borrower"`
+	Quantity  int       `json:"quantity"`
+	DueDate   time.Time `json:"due_date"`
+	Overdue   bool      `json:"overdue,omitempty"`
+	Available int       `json:"available"`
+	Message   string    `json:"message"`
+}
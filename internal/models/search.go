@@ -0,0 +1,31 @@
+package models
+
+const (
+	SearchTypeToolkit  = "toolkit"
+	SearchTypeCategory = "category"
+	SearchTypeUser     = "user"
+	SearchTypeLoan     = "loan"
+)
+
+type SearchRequest struct {
+	Query string `json:"q" form:"q" binding:"required,min=2"`
+	Limit int    `json:"limit,omitempty" form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type SearchHit struct {
+	ID       int     `json:"id"`
+	Type     string  `json:"type"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle,omitempty"`
+	Snippet  string  `json:"snippet,omitempty"`
+	Rank     float64 `json:"rank"`
+}
+
+// SearchResponse hasil global search, dikelompokkan per tipe
+type SearchResponse struct {
+	Query      string      `json:"query"`
+	Toolkits   []SearchHit `json:"toolkits"`
+	Categories []SearchHit `json:"categories"`
+	Users      []SearchHit `json:"users,omitempty"`
+	Loans      []SearchHit `json:"loans"`
+}
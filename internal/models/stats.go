@@ -0,0 +1,15 @@
+package models
+
+// InventoryStats agregat ringan untuk metrics / dashboard
+type InventoryStats struct {
+	ActiveLoans         int64                  `json:"active_loans"`
+	OverdueLoans        int64                  `json:"overdue_loans"`
+	ToolkitsOutOfStock  int64                  `json:"toolkits_out_of_stock"`
+	AvailableByCategory []CategoryAvailability `json:"available_by_category"`
+}
+
+type CategoryAvailability struct {
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Available    int64  `json:"available"`
+}
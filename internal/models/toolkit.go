@@ -2,13 +2,16 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+
 	"toolkit-management/pkg/utils"
 )
 
 type Toolkit struct {
 	ID            int        `json:"id" gorm:"primaryKey"`
 	Name          string     `json:"name" binding:"required" gorm:"not null"`
-	SKU           string     `json:"sku" gorm:"unique;not null"`
+	SKU           string     `json:"sku" gorm:"not null;uniqueIndex:idx_toolkits_sku,where:deleted_at IS NULL"`
 	Description   string     `json:"description"`
 	CategoryID    int        `json:"category_id" gorm:"not null"`
 	Quantity      int        `json:"quantity" gorm:"not null"`
@@ -25,12 +28,25 @@ type Toolkit struct {
 	Notes         string     `json:"notes"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
-	DeletedAt     *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	// PrimaryImageID foto utama dari attachment. ImageURL tidak diubah karena URL attachment
+	// butuh token; PrimaryImage URL isinya lewat API.
+	PrimaryImageID *int   `json:"primary_image_id,omitempty" gorm:"index"`
+	PrimaryImage   string `json:"primary_image,omitempty" gorm:"-"`
+	// DeletedAt soft delete: laporan historis tetap menemukan toolkit yang sudah dihapus
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 	Loans    []Loan   `json:"loans,omitempty" gorm:"foreignKey:ToolkitID"`
 }
 
+// AfterFind isi PrimaryImage dari PrimaryImageID
+func (t *Toolkit) AfterFind(*gorm.DB) error {
+	if t.PrimaryImageID != nil {
+		t.PrimaryImage = AttachmentContentURL(*t.PrimaryImageID, false)
+	}
+	return nil
+}
+
 type ToolkitFilterRequest struct {
 	SearchTerm  string `json:"search_term,omitempty"`
 	CategoryID  int    `json:"category_id,omitempty"`
@@ -39,8 +55,16 @@ type ToolkitFilterRequest struct {
 	Brand       string `json:"brand,omitempty"`
 	MinQuantity int    `json:"min_quantity,omitempty"`
 	MaxQuantity int    `json:"max_quantity,omitempty"`
+	LocationID  int    `json:"location_id,omitempty" form:"location_id"`
 	Page        int    `json:"page,omitempty" form:"page"`
 	PageSize    int    `json:"page_size,omitempty" form:"page_size"`
+
+	// Keyset pagination (pagination_mode=cursor atau cursor diisi)
+	PaginationMode string `json:"pagination_mode,omitempty" form:"pagination_mode" binding:"omitempty,oneof=offset cursor"`
+	Cursor         string `json:"cursor,omitempty" form:"cursor"`
+	IncludeTotal   bool   `json:"include_total,omitempty" form:"include_total"`
+	SortBy         string `json:"sort_by,omitempty" form:"sort_by"`
+	SortOrder      string `json:"sort_order,omitempty" form:"sort_order" binding:"omitempty,oneof=asc desc"`
 }
 
 type ToolkitCreateRequest struct {
@@ -78,6 +102,12 @@ type ToolkitUpdateRequest struct {
 	Notes         string     `json:"notes,omitempty"`
 }
 
+// Reason StockAdjustment untuk perubahan stok manual lewat PATCH /toolkits/:id/stock
+const (
+	AdjustmentAcquisition = "acquisition"
+	AdjustmentWriteOff    = "write_off"
+)
+
 type ToolkitStockUpdateRequest struct {
 	QuantityChange int    `json:"quantity_change" binding:"required"`
 	Reason         string `json:"reason" binding:"required"`
@@ -85,6 +115,7 @@ type ToolkitStockUpdateRequest struct {
 }
 
 type ToolkitListResponse struct {
-	Data       []Toolkit                `json:"data"`
-	Pagination utils.PaginationResponse `json:"pagination"`
+	Data             []Toolkit                       `json:"data"`
+	Pagination       *utils.PaginationResponse       `json:"pagination,omitempty"`
+	CursorPagination *utils.CursorPaginationResponse `json:"cursor_pagination,omitempty"`
 }
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Status transfer stok: draft → in_transit → received
+const (
+	TransferDraft     = "draft"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+)
+
+// TransferShortage reason StockAdjustment untuk unit yang tidak sampai di lokasi tujuan
+const TransferShortage = "transfer_shortage"
+
+// Transfer dokumen pemindahan stok antar lokasi. Unit in_transit sudah keluar dari lokasi
+// asal dan belum masuk ke tujuan, jadi tidak tersedia di mana pun.
+type Transfer struct {
+	ID                    int        `json:"id" gorm:"primaryKey"`
+	SourceLocationID      int        `json:"source_location_id" gorm:"not null;index"`
+	DestinationLocationID int        `json:"destination_location_id" gorm:"not null;index"`
+	Status                string     `json:"status" gorm:"not null;default:'draft';index"`
+	Notes                 string     `json:"notes"`
+	CreatedBy             int        `json:"created_by"`
+	DispatchedBy          *int       `json:"dispatched_by"`
+	DispatchedAt          *time.Time `json:"dispatched_at"`
+	ReceivedBy            *int       `json:"received_by"`
+	ReceivedAt            *time.Time `json:"received_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+
+	Source      *Location      `json:"source,omitempty" gorm:"foreignKey:SourceLocationID;constraint:OnDelete:RESTRICT"`
+	Destination *Location      `json:"destination,omitempty" gorm:"foreignKey:DestinationLocationID;constraint:OnDelete:RESTRICT"`
+	Lines       []TransferLine `json:"lines" gorm:"foreignKey:TransferID;constraint:OnDelete:CASCADE"`
+	// HasDiscrepancy ada line yang diterima kurang dari yang dikirim
+	HasDiscrepancy bool `json:"has_discrepancy" gorm:"-"`
+}
+
+// TransferLine jumlah unit satu toolkit dalam transfer
+type TransferLine struct {
+	ID         int `json:"id" gorm:"primaryKey"`
+	TransferID int `json:"transfer_id" gorm:"not null;uniqueIndex:idx_transfer_line"`
+	ToolkitID  int `json:"toolkit_id" gorm:"not null;uniqueIndex:idx_transfer_line"`
+	Quantity   int `json:"quantity" gorm:"not null"`
+	// ReceivedQuantity diisi saat diterima, selisih dengan Quantity = discrepancy
+	ReceivedQuantity *int   `json:"received_quantity"`
+	DiscrepancyNotes string `json:"discrepancy_notes,omitempty"`
+
+	Toolkit *Toolkit `json:"toolkit,omitempty" gorm:"foreignKey:ToolkitID"`
+}
+
+type TransferLineRequest struct {
+	ToolkitID int `json:"toolkit_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
+
+type TransferCreateRequest struct {
+	SourceLocationID      int                   `json:"source_location_id" binding:"required"`
+	DestinationLocationID int                   `json:"destination_location_id" binding:"required"`
+	Notes                 string                `json:"notes"`
+	Lines                 []TransferLineRequest `json:"lines" binding:"required,min=1,max=100,dive"`
+}
+
+// TransferUpdateRequest hanya untuk draft; lines diisi = daftar line diganti seluruhnya
+type TransferUpdateRequest struct {
+	SourceLocationID      int                   `json:"source_location_id,omitempty"`
+	DestinationLocationID int                   `json:"destination_location_id,omitempty"`
+	Notes                 string                `json:"notes,omitempty"`
+	Lines                 []TransferLineRequest `json:"lines,omitempty" binding:"omitempty,min=1,max=100,dive"`
+}
+
+type TransferFilterRequest struct {
+	Status string `json:"status,omitempty" form:"status" binding:"omitempty,oneof=draft in_transit received"`
+	// LocationID transfer dari atau ke lokasi ini
+	LocationID int `json:"location_id,omitempty" form:"location_id"`
+	ToolkitID  int `json:"toolkit_id,omitempty" form:"toolkit_id"`
+}
+
+type TransferReceiveLine struct {
+	LineID           int    `json:"line_id" binding:"required"`
+	ReceivedQuantity *int   `json:"received_quantity" binding:"required,min=0"`
+	Notes            string `json:"notes"`
+}
+
+// TransferReceiveRequest lines kosong = semua line diterima lengkap
+type TransferReceiveRequest struct {
+	Lines []TransferReceiveLine `json:"lines,omitempty" binding:"omitempty,max=100,dive"`
+	Notes string                `json:"notes"`
+}
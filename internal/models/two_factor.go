@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactor enrolment TOTP per user. EnabledAt nil = setup belum dikonfirmasi.
+// ChallengeID challenge login yang masih berlaku (kosong = tidak ada), FailedAttempts
+// jumlah kode salah untuk challenge itu.
+type TwoFactor struct {
+	ID     int `json:"-" gorm:"primaryKey"`
+	UserID int `json:"-" gorm:"uniqueIndex;not null"`
+	// Secret terenkripsi AES-GCM, lihat auth.TOTP
+	Secret    string     `json:"-" gorm:"not null"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	// LastUsedStep periode TOTP terakhir yang dipakai, untuk menolak replay kode
+	LastUsedStep   int64     `json:"-" gorm:"not null;default:0"`
+	ChallengeID    string    `json:"-"`
+	FailedAttempts int       `json:"-" gorm:"not null;default:0"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// RecoveryCode kode cadangan sekali pakai kalau HP hilang. Disimpan sebagai hash SHA-256.
+type RecoveryCode struct {
+	ID        int        `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse secret ditampilkan untuk input manual kalau QR tidak bisa di-scan
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorEnableRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorEnableResponse recovery code hanya ditampilkan sekali.
+// Login terisi kalau enrolment dilakukan di tengah login (2FA wajib untuk role user).
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string       `json:"recovery_codes"`
+	Login         *LoginResponse `json:"login,omitempty"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Kode TOTP 6 digit atau recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest mematikan 2FA butuh password dan kode yang masih berlaku
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallenge dikembalikan login kalau masih perlu langkah kedua
+type TwoFactorChallenge struct {
+	// Step "verify" = kirim kode ke /api/auth/2fa/verify,
+	// "setup" = enrolment wajib lewat /api/auth/2fa/setup dengan challenge token sebagai bearer
+	Step           string    `json:"step"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
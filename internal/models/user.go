@@ -16,12 +16,16 @@ type User struct {
 	Role        string     `json:"role" binding:"required" gorm:"default:user"`
 	Department  string     `json:"department"`
 	PhoneNumber string     `json:"phone_number"`
+	BadgeNumber *string    `json:"badge_number,omitempty" gorm:"uniqueIndex"`
 	IsActive    bool       `json:"is_active" gorm:"default:true"`
 	LastLogin   *time.Time `json:"last_login"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
-	Loans       []Loan     `json:"loans,omitempty" gorm:"foreignKey:UserID"`
+	// Sumber login: local (password), ldap, oidc. ExternalID "<provider>:<subject>" untuk user SSO.
+	AuthProvider string     `json:"auth_provider" gorm:"default:local"`
+	ExternalID   *string    `json:"-" gorm:"uniqueIndex"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	Loans        []Loan     `json:"loans,omitempty" gorm:"foreignKey:UserID"`
 }
 
 type UserFilterRequest struct {
@@ -31,11 +35,19 @@ type UserFilterRequest struct {
 	IsActive   *bool  `json:"is_active,omitempty"`
 	Page       int    `json:"page,omitempty" form:"page"`
 	PageSize   int    `json:"page_size,omitempty" form:"page_size"`
+
+	// Keyset pagination (pagination_mode=cursor atau cursor diisi)
+	PaginationMode string `json:"pagination_mode,omitempty" form:"pagination_mode" binding:"omitempty,oneof=offset cursor"`
+	Cursor         string `json:"cursor,omitempty" form:"cursor"`
+	IncludeTotal   bool   `json:"include_total,omitempty" form:"include_total"`
+	SortBy         string `json:"sort_by,omitempty" form:"sort_by"`
+	SortOrder      string `json:"sort_order,omitempty" form:"sort_order" binding:"omitempty,oneof=asc desc"`
 }
 
 type UserListResponse struct {
-	Data       []User                   `json:"data"`
-	Pagination utils.PaginationResponse `json:"pagination"`
+	Data             []User                          `json:"data"`
+	Pagination       *utils.PaginationResponse       `json:"pagination,omitempty"`
+	CursorPagination *utils.CursorPaginationResponse `json:"cursor_pagination,omitempty"`
 }
 
 type UserCreateRequest struct {
@@ -46,6 +58,8 @@ type UserCreateRequest struct {
 	Role        string `json:"role" binding:"required,oneof=admin user technician"`
 	Department  string `json:"department"`
 	PhoneNumber string `json:"phone_number"`
+	// BadgeNumber kode kartu karyawan untuk checkout lewat scanner
+	BadgeNumber string `json:"badge_number"`
 }
 
 type UserUpdateRequest struct {
@@ -56,6 +70,7 @@ type UserUpdateRequest struct {
 	Role        string `json:"role,omitempty" binding:"omitempty,oneof=admin user technician"`
 	Department  string `json:"department,omitempty"`
 	PhoneNumber string `json:"phone_number,omitempty"`
+	BadgeNumber string `json:"badge_number,omitempty"`
 	IsActive    *bool  `json:"is_active,omitempty"`
 }
 
@@ -64,10 +79,27 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse Token kosong kalau login masih menunggu langkah kedua (TwoFactor)
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	User      User      `json:"user"`
-	ExpiresAt time.Time `json:"expires_at"`
+	Token     string              `json:"token,omitempty"`
+	User      *User               `json:"user,omitempty"`
+	ExpiresAt *time.Time          `json:"expires_at,omitempty"`
+	TwoFactor *TwoFactorChallenge `json:"two_factor,omitempty"`
+}
+
+// ExternalIdentity identitas user dari IdP, role & department sudah hasil mapping group.
+// Role kosong berarti tidak ada group yang cocok: role user yang sudah ada tidak diubah.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	FullName      string
+	Role          string
+	// DefaultRole role user baru kalau Role kosong
+	DefaultRole string
+	Department  string
 }
 
 func HashPassword(password string) (string, error) {
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// Metode depresiasi
+const (
+	DepreciationStraightLine     = "straight_line"
+	DepreciationDecliningBalance = "declining_balance"
+)
+
+// DefaultUsefulLifeMonths umur ekonomis kalau kategori belum diatur
+const DefaultUsefulLifeMonths = 60
+
+type ValuationRequest struct {
+	// AsOf tanggal penilaian, default hari ini
+	AsOf *time.Time `json:"as_of,omitempty" form:"as_of" time_format:"2006-01-02"`
+	// PeriodStart awal periode untuk unit yang dibeli/dihapus, default 1 Januari tahun AsOf
+	PeriodStart *time.Time `json:"period_start,omitempty" form:"period_start" time_format:"2006-01-02"`
+	CategoryID  int        `json:"category_id,omitempty" form:"category_id"`
+	Format      string     `json:"format,omitempty" form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// ToolkitValuation nilai buku satu toolkit (semua unit) per tanggal AsOf. Unit yang dibeli di
+// periode: semua unit kalau PurchaseDate di periode, kalau tidak hanya adjustment acquisition.
+type ToolkitValuation struct {
+	ToolkitID               int        `json:"toolkit_id"`
+	Name                    string     `json:"name"`
+	SKU                     string     `json:"sku"`
+	CategoryID              int        `json:"category_id"`
+	CategoryName            string     `json:"category_name"`
+	Method                  string     `json:"method"`
+	UsefulLifeMonths        int        `json:"useful_life_months"`
+	PurchaseDate            *time.Time `json:"purchase_date"`
+	UnitCost                float64    `json:"unit_cost"`
+	Units                   int        `json:"units"`
+	Cost                    float64    `json:"cost"`
+	AccumulatedDepreciation float64    `json:"accumulated_depreciation"`
+	BookValue               float64    `json:"book_value"`
+	AcquiredInPeriod        bool       `json:"acquired_in_period"`
+	UnitsAcquired           int        `json:"units_acquired"`
+	AcquiredCost            float64    `json:"acquired_cost"`
+	UnitsWrittenOff         int        `json:"units_written_off"`
+	WrittenOffBookValue     float64    `json:"written_off_book_value"`
+}
+
+// ValuationTotals subtotal per kategori dan grand total
+type ValuationTotals struct {
+	CategoryID              int     `json:"category_id,omitempty"`
+	CategoryName            string  `json:"category_name,omitempty"`
+	Toolkits                int     `json:"toolkits"`
+	Units                   int     `json:"units"`
+	Cost                    float64 `json:"cost"`
+	AccumulatedDepreciation float64 `json:"accumulated_depreciation"`
+	BookValue               float64 `json:"book_value"`
+	UnitsAcquired           int     `json:"units_acquired"`
+	AcquiredCost            float64 `json:"acquired_cost"`
+	UnitsWrittenOff         int     `json:"units_written_off"`
+	WrittenOffBookValue     float64 `json:"written_off_book_value"`
+}
+
+type ValuationReport struct {
+	AsOf        time.Time          `json:"as_of"`
+	PeriodStart time.Time          `json:"period_start"`
+	Toolkits    []ToolkitValuation `json:"toolkits"`
+	Categories  []ValuationTotals  `json:"categories"`
+	Total       ValuationTotals    `json:"total"`
+	// Unvalued toolkit tanpa harga atau tanggal beli, tidak ikut dinilai
+	Unvalued int `json:"unvalued"`
+}
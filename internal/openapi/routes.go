@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"net/http"
+
+	"toolkit-management/internal/models"
+)
+
+// Operations semua route yang didaftarkan di registerRoutes (routes.go). Route baru wajib
+// ditambahkan di sini, TestRoutesDocumented gagal kalau MissingRoutes tidak kosong.
+var Operations = []Operation{
+	// Meta
+	{Method: http.MethodGet, Path: "/api/health", Tag: "meta", Summary: "Readiness check (alias of /readyz)", Access: Public, Raw: true},
+	{Method: http.MethodGet, Path: "/livez", Tag: "meta", Summary: "Liveness probe", Access: Public, Raw: true},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "meta", Summary: "Readiness probe (503 when a dependency is down)", Access: Public, Raw: true},
+	{Method: http.MethodGet, Path: "/api/admin/health", Tag: "meta", Summary: "Detailed component health", Access: AdminOnly},
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "meta", Summary: "OpenAPI document", Access: Public, Raw: true},
+	{Method: http.MethodGet, Path: "/api/docs", Tag: "meta", Summary: "API documentation UI", Access: Public, Raw: true,
+		Produces: "text/html"},
+	{Method: http.MethodGet, Path: "/metrics", Tag: "meta", Summary: "Prometheus metrics", Access: Public, Raw: true,
+		Produces: "text/plain"},
+
+	// Auth
+	{Method: http.MethodPost, Path: "/api/auth/login", Tag: "auth", Summary: "Login with username and password",
+		Access: Public, Body: models.LoginRequest{}, Response: models.LoginResponse{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/auth/oidc/login", Tag: "auth", Summary: "Start single sign-on (redirects to the identity provider)",
+		Access: Public, Status: http.StatusFound, Raw: true, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/auth/oidc/callback", Tag: "auth", Summary: "Single sign-on callback; redirects to the frontend or returns a login response",
+		Access: Public, Response: models.LoginResponse{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/auth/me", Tag: "auth", Summary: "Current user",
+		Access: Authenticated, Response: models.User{}},
+	{Method: http.MethodPost, Path: "/api/auth/tokens", Tag: "auth", Summary: "Create a personal API token (plaintext returned once)",
+		Access: Authenticated, Body: models.APITokenCreateRequest{}, Response: models.APITokenCreateResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/auth/tokens", Tag: "auth", Summary: "List own API tokens",
+		Access: Authenticated, Response: []models.APIToken{}},
+	{Method: http.MethodDelete, Path: "/api/auth/tokens/:id", Tag: "auth", Summary: "Revoke an API token",
+		Access: Authenticated},
+	{Method: http.MethodPost, Path: "/api/auth/2fa/verify", Tag: "auth", Summary: "Second login step: TOTP or recovery code for a challenge token",
+		Access: Public, Body: models.TwoFactorVerifyRequest{}, Response: models.LoginResponse{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/auth/2fa", Tag: "auth", Summary: "Two-factor status of the current user",
+		Access: Authenticated, Response: models.TwoFactorStatus{}},
+	{Method: http.MethodPost, Path: "/api/auth/2fa/setup", Tag: "auth", Summary: "Start TOTP enrolment (session or setup challenge token)",
+		Access: Authenticated, Response: models.TwoFactorSetupResponse{}},
+	{Method: http.MethodPost, Path: "/api/auth/2fa/enable", Tag: "auth", Summary: "Confirm TOTP enrolment and receive recovery codes",
+		Access: Authenticated, Body: models.TwoFactorEnableRequest{}, Response: models.TwoFactorEnableResponse{}},
+	{Method: http.MethodPost, Path: "/api/auth/2fa/disable", Tag: "auth", Summary: "Disable two-factor authentication (password and code required)",
+		Access: Authenticated, Body: models.TwoFactorDisableRequest{}},
+
+	// Search
+	{Method: http.MethodGet, Path: "/api/search", Tag: "search", Summary: "Search toolkits, categories, users and loans",
+		Access: Authenticated, Query: models.SearchRequest{}, Response: models.SearchResponse{}},
+
+	// Users
+	{Method: http.MethodPost, Path: "/api/users", Tag: "users", Summary: "Create user",
+		Access: AdminOnly, Body: models.UserCreateRequest{}, Response: models.User{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/users", Tag: "users", Summary: "List users",
+		Access: AdminOnly, Query: models.UserFilterRequest{}, Response: []models.User{}, Paginated: true},
+	{Method: http.MethodPost, Path: "/api/users/search", Tag: "users", Summary: "Search users",
+		Access: AdminOnly, Query: models.UserFilterRequest{}, Body: models.UserFilterRequest{}, Response: []models.User{}, Paginated: true},
+	{Method: http.MethodGet, Path: "/api/users/:id", Tag: "users", Summary: "Get user",
+		Access: AdminOnly, Response: models.User{}},
+	{Method: http.MethodPut, Path: "/api/users/:id", Tag: "users", Summary: "Update user",
+		Access: AdminOnly, Body: models.UserUpdateRequest{}, Response: models.User{}},
+	{Method: http.MethodDelete, Path: "/api/users/:id", Tag: "users", Summary: "Delete user", Access: AdminOnly},
+
+	// Toolkits
+	{Method: http.MethodPost, Path: "/api/toolkits", Tag: "toolkits", Summary: "Create toolkit",
+		Access: AdminOnly, Body: models.ToolkitCreateRequest{}, Response: models.Toolkit{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/toolkits/:id", Tag: "toolkits", Summary: "Update toolkit",
+		Access: AdminOnly, Body: models.ToolkitUpdateRequest{}, Response: models.Toolkit{}},
+	{Method: http.MethodDelete, Path: "/api/toolkits/:id", Tag: "toolkits", Summary: "Delete toolkit", Access: AdminOnly},
+	{Method: http.MethodPatch, Path: "/api/toolkits/:id/stock", Tag: "toolkits", Summary: "Adjust toolkit stock",
+		Access: AdminOnly, Body: models.ToolkitStockUpdateRequest{}, Response: models.Toolkit{}},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/stock-adjustments", Tag: "toolkits", Summary: "Stock history: acquisitions, write-offs and movements between locations",
+		Access: AdminOnly, Response: []models.StockAdjustment{}},
+	{Method: http.MethodPost, Path: "/api/toolkits/:id/attachments", Tag: "toolkits", Summary: "Upload a toolkit photo or document (manual, datasheet, certificate)",
+		Access: AdminOnly, Body: models.AttachmentUploadRequest{}, ContentType: "multipart/form-data", Response: models.Attachment{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/attachments", Tag: "toolkits", Summary: "List toolkit photos and documents",
+		Access: Authenticated, Response: []models.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/label", Tag: "toolkits", Summary: "Printable label with QR or Code128 (PNG or PDF)",
+		Access: StaffOnly, Query: models.LabelRequest{}, Raw: true, Produces: "image/png"},
+	{Method: http.MethodPost, Path: "/api/toolkits/labels", Tag: "toolkits", Summary: "Batch of labels as one PDF (label roll or A4 sheet)",
+		Access: StaffOnly, Body: models.LabelBatchRequest{}, Raw: true, Produces: "application/pdf"},
+	{Method: http.MethodGet, Path: "/api/attachments/:id", Tag: "toolkits", Summary: "Get attachment metadata",
+		Access: Authenticated, Response: models.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/attachments/:id/content", Tag: "toolkits", Summary: "Download attachment file or its thumbnail",
+		Access: Authenticated, Query: models.AttachmentContentRequest{}, Raw: true, Produces: "application/octet-stream"},
+	{Method: http.MethodDelete, Path: "/api/attachments/:id", Tag: "toolkits", Summary: "Delete attachment and its stored files", Access: AdminOnly},
+	{Method: http.MethodGet, Path: "/api/toolkits", Tag: "toolkits", Summary: "List toolkits",
+		Access: Authenticated, Query: models.ToolkitFilterRequest{}, Response: []models.Toolkit{}, Paginated: true},
+	{Method: http.MethodPost, Path: "/api/toolkits/search", Tag: "toolkits", Summary: "Search toolkits",
+		Access: Authenticated, Query: models.ToolkitFilterRequest{}, Body: models.ToolkitFilterRequest{}, Response: []models.Toolkit{}, Paginated: true},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id", Tag: "toolkits", Summary: "Get toolkit",
+		Access: Authenticated, Response: models.Toolkit{}},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/availability", Tag: "locations", Summary: "Toolkit stock per location and in total",
+		Access: Authenticated, Response: models.ToolkitAvailability{}},
+
+	// Categories
+	{Method: http.MethodPost, Path: "/api/categories", Tag: "categories", Summary: "Create category",
+		Access: AdminOnly, Body: models.CategoryCreateRequest{}, Response: models.Category{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/categories", Tag: "categories", Summary: "List categories",
+		Access: AdminOnly, Body: models.CategoryFilterRequest{}, Response: []models.Category{}},
+	{Method: http.MethodGet, Path: "/api/categories/:id", Tag: "categories", Summary: "Get category",
+		Access: AdminOnly, Response: models.Category{}},
+	{Method: http.MethodPut, Path: "/api/categories/:id", Tag: "categories", Summary: "Update category",
+		Access: AdminOnly, Body: models.CategoryUpdateRequest{}, Response: models.Category{}},
+	{Method: http.MethodDelete, Path: "/api/categories/:id", Tag: "categories", Summary: "Delete category", Access: AdminOnly},
+	{Method: http.MethodGet, Path: "/api/categories/tree", Tag: "categories", Summary: "Category tree",
+		Access: AdminOnly, Response: []models.Category{}},
+	{Method: http.MethodGet, Path: "/api/categories/:id/checklist", Tag: "inspections", Summary: "Inspection checklist of a category",
+		Access: Authenticated, Response: []models.ChecklistItem{}},
+	{Method: http.MethodPost, Path: "/api/categories/:id/checklist", Tag: "inspections", Summary: "Add checklist item",
+		Access: AdminOnly, Body: models.ChecklistItemCreateRequest{}, Response: models.ChecklistItem{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/checklist-items/:id", Tag: "inspections", Summary: "Update checklist item",
+		Access: AdminOnly, Body: models.ChecklistItemUpdateRequest{}, Response: models.ChecklistItem{}},
+	{Method: http.MethodDelete, Path: "/api/checklist-items/:id", Tag: "inspections", Summary: "Delete checklist item", Access: AdminOnly},
+
+	// Loans
+	{Method: http.MethodPost, Path: "/api/loans", Tag: "loans", Summary: "Create loan",
+		Access: Authenticated, Body: models.LoanCreateRequest{}, Response: models.Loan{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/loans", Tag: "loans", Summary: "List loans",
+		Access: Authenticated, Body: models.LoanFilterRequest{}, Response: []models.Loan{}},
+	{Method: http.MethodGet, Path: "/api/loans/:id", Tag: "loans", Summary: "Get loan",
+		Access: Authenticated, Response: models.Loan{}},
+	{Method: http.MethodPut, Path: "/api/loans/:id", Tag: "loans", Summary: "Update loan",
+		Access: Authenticated, Body: models.LoanUpdateRequest{}, Response: models.Loan{}},
+	{Method: http.MethodDelete, Path: "/api/loans/:id", Tag: "loans", Summary: "Delete loan", Access: Authenticated},
+
+	// Bundles
+	{Method: http.MethodPost, Path: "/api/bundles", Tag: "bundles", Summary: "Create bundle of component toolkits",
+		Access: AdminOnly, Body: models.BundleCreateRequest{}, Response: models.Bundle{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/bundles", Tag: "bundles", Summary: "List bundles with computed availability",
+		Access: Authenticated, Query: models.BundleFilterRequest{}, Response: []models.Bundle{}},
+	{Method: http.MethodGet, Path: "/api/bundles/:id", Tag: "bundles", Summary: "Get bundle with computed availability",
+		Access: Authenticated, Response: models.Bundle{}},
+	{Method: http.MethodPut, Path: "/api/bundles/:id", Tag: "bundles", Summary: "Update bundle (components replace the current list)",
+		Access: AdminOnly, Body: models.BundleUpdateRequest{}, Response: models.Bundle{}},
+	{Method: http.MethodDelete, Path: "/api/bundles/:id", Tag: "bundles", Summary: "Delete bundle", Access: AdminOnly},
+	{Method: http.MethodPost, Path: "/api/bundles/:id/checkout", Tag: "bundles", Summary: "Check out bundle: one parent loan with a loan per component",
+		Access: Authenticated, Body: models.BundleCheckoutRequest{}, Response: models.BundleLoan{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/bundle-loans", Tag: "bundles", Summary: "List bundle loans",
+		Access: Authenticated, Query: models.BundleLoanFilterRequest{}, Response: []models.BundleLoan{}},
+	{Method: http.MethodGet, Path: "/api/bundle-loans/:id", Tag: "bundles", Summary: "Get bundle loan with component loans",
+		Access: Authenticated, Response: models.BundleLoan{}},
+	{Method: http.MethodPost, Path: "/api/bundle-loans/:id/return", Tag: "bundles", Summary: "Return all or some components of a bundle loan",
+		Access: Authenticated, Body: models.BundleReturnRequest{}, Response: models.BundleLoan{}},
+
+	// Locations
+	{Method: http.MethodPost, Path: "/api/locations", Tag: "locations", Summary: "Create warehouse, shelf or bin",
+		Access: AdminOnly, Body: models.LocationCreateRequest{}, Response: models.Location{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/locations", Tag: "locations", Summary: "List locations",
+		Access: Authenticated, Query: models.LocationFilterRequest{}, Response: []models.Location{}},
+	{Method: http.MethodGet, Path: "/api/locations/:id", Tag: "locations", Summary: "Get location with its direct children",
+		Access: Authenticated, Response: models.Location{}},
+	{Method: http.MethodPut, Path: "/api/locations/:id", Tag: "locations", Summary: "Update location",
+		Access: AdminOnly, Body: models.LocationUpdateRequest{}, Response: models.Location{}},
+	{Method: http.MethodDelete, Path: "/api/locations/:id", Tag: "locations", Summary: "Delete empty location", Access: AdminOnly},
+	{Method: http.MethodGet, Path: "/api/locations/:id/stock", Tag: "locations", Summary: "Toolkit stock at a location, optionally including sub-locations",
+		Access: Authenticated, Query: models.LocationStockFilterRequest{}, Response: []models.LocationStockLine{}},
+	{Method: http.MethodPut, Path: "/api/locations/:id/stock/:toolkit_id", Tag: "locations", Summary: "Set units of a toolkit placed at a location",
+		Access: AdminOnly, Body: models.LocationStockRequest{}, Response: models.ToolkitStock{}},
+
+	// Transfers
+	{Method: http.MethodPost, Path: "/api/transfers", Tag: "locations", Summary: "Create draft stock transfer between locations",
+		Access: StaffOnly, Body: models.TransferCreateRequest{}, Response: models.Transfer{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/transfers", Tag: "locations", Summary: "List stock transfers",
+		Access: Authenticated, Query: models.TransferFilterRequest{}, Response: []models.Transfer{}},
+	{Method: http.MethodGet, Path: "/api/transfers/:id", Tag: "locations", Summary: "Get stock transfer with lines",
+		Access: Authenticated, Response: models.Transfer{}},
+	{Method: http.MethodPut, Path: "/api/transfers/:id", Tag: "locations", Summary: "Update draft transfer (lines replace the current list)",
+		Access: StaffOnly, Body: models.TransferUpdateRequest{}, Response: models.Transfer{}},
+	{Method: http.MethodDelete, Path: "/api/transfers/:id", Tag: "locations", Summary: "Delete draft transfer", Access: StaffOnly},
+	{Method: http.MethodPost, Path: "/api/transfers/:id/dispatch", Tag: "locations", Summary: "Dispatch transfer: stock leaves the source and is in transit",
+		Access: StaffOnly, Response: models.Transfer{}},
+	{Method: http.MethodPost, Path: "/api/transfers/:id/receive", Tag: "locations", Summary: "Receive transfer at the destination, recording discrepancies",
+		Access: StaffOnly, Body: models.TransferReceiveRequest{}, Response: models.Transfer{}},
+
+	// Scanner
+	{Method: http.MethodPost, Path: "/api/scan/checkout", Tag: "scan", Summary: "Check out by scanned item code and borrower badge",
+		Access: StaffOnly, Body: models.ScanCheckoutRequest{}, Response: models.ScanResult{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/scan/return", Tag: "scan", Summary: "Return the borrower's oldest active loan of a scanned item",
+		Access: StaffOnly, Body: models.ScanReturnRequest{}, Response: models.ScanResult{}},
+
+	// Inspections
+	{Method: http.MethodPost, Path: "/api/loans/:id/inspections", Tag: "inspections", Summary: "Record checkout or return inspection",
+		Access: Authenticated, Body: models.InspectionCreateRequest{}, Response: models.Inspection{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/loans/:id/inspections", Tag: "inspections", Summary: "Inspections of a loan",
+		Access: Authenticated, Response: []models.Inspection{}},
+	{Method: http.MethodGet, Path: "/api/inspections", Tag: "inspections", Summary: "List inspections",
+		Access: StaffOnly, Query: models.InspectionFilterRequest{}, Response: []models.Inspection{}},
+	{Method: http.MethodGet, Path: "/api/inspections/:id", Tag: "inspections", Summary: "Get inspection",
+		Access: StaffOnly, Response: models.Inspection{}},
+	{Method: http.MethodPost, Path: "/api/inspections/:id/review", Tag: "inspections", Summary: "Mark flagged return inspection as reviewed",
+		Access: StaffOnly, Body: models.InspectionReviewRequest{}, Response: models.Inspection{}},
+
+	// Incidents
+	{Method: http.MethodPost, Path: "/api/loans/:id/incidents", Tag: "incidents", Summary: "Report damaged or lost units of a loan",
+		Access: Authenticated, Body: models.IncidentCreateRequest{}, Response: models.Incident{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/incidents", Tag: "incidents", Summary: "List incidents",
+		Access: StaffOnly, Query: models.IncidentFilterRequest{}, Response: []models.Incident{}},
+	{Method: http.MethodGet, Path: "/api/incidents/:id", Tag: "incidents", Summary: "Get incident",
+		Access: StaffOnly, Response: models.Incident{}},
+	{Method: http.MethodPost, Path: "/api/incidents/:id/evidence", Tag: "incidents", Summary: "Attach evidence to incident",
+		Access: Authenticated, Body: models.IncidentEvidenceRequest{}, Response: models.IncidentEvidence{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/incidents/:id/assess", Tag: "incidents", Summary: "Assess repair or replacement cost",
+		Access: StaffOnly, Body: models.IncidentAssessRequest{}, Response: models.Incident{}},
+	{Method: http.MethodPost, Path: "/api/incidents/:id/resolve", Tag: "incidents", Summary: "Resolve incident (repaired, written off, charged to department)",
+		Access: AdminOnly, Body: models.IncidentResolveRequest{}, Response: models.Incident{}},
+
+	// Maintenance
+	{Method: http.MethodPost, Path: "/api/toolkits/:id/maintenance", Tag: "maintenance", Summary: "Create maintenance record",
+		Access: StaffOnly, Body: models.MaintenanceCreateRequest{}, Response: models.MaintenanceRecord{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/maintenance", Tag: "maintenance", Summary: "Maintenance history of a toolkit",
+		Access: StaffOnly, Query: models.MaintenanceFilterRequest{}, Response: []models.MaintenanceRecord{}},
+	{Method: http.MethodPost, Path: "/api/toolkits/:id/maintenance-schedules", Tag: "maintenance", Summary: "Create recurring maintenance schedule",
+		Access: StaffOnly, Body: models.MaintenanceScheduleCreateRequest{}, Response: models.MaintenanceSchedule{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/toolkits/:id/maintenance-schedules", Tag: "maintenance", Summary: "List maintenance schedules of a toolkit",
+		Access: StaffOnly, Response: []models.MaintenanceSchedule{}},
+	{Method: http.MethodGet, Path: "/api/maintenance", Tag: "maintenance", Summary: "List maintenance records",
+		Access: StaffOnly, Query: models.MaintenanceFilterRequest{}, Response: []models.MaintenanceRecord{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/due", Tag: "maintenance", Summary: "Overdue and upcoming maintenance",
+		Access: StaffOnly, Query: models.MaintenanceDueRequest{}, Response: models.MaintenanceDueResponse{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/:id", Tag: "maintenance", Summary: "Get maintenance record",
+		Access: StaffOnly, Response: models.MaintenanceRecord{}},
+	{Method: http.MethodPost, Path: "/api/maintenance/:id/start", Tag: "maintenance", Summary: "Start maintenance (units leave available stock)",
+		Access: StaffOnly, Response: models.MaintenanceRecord{}},
+	{Method: http.MethodPost, Path: "/api/maintenance/:id/complete", Tag: "maintenance", Summary: "Complete maintenance (units return to available stock)",
+		Access: StaffOnly, Body: models.MaintenanceCompleteRequest{}, Response: models.MaintenanceRecord{}},
+	{Method: http.MethodPost, Path: "/api/maintenance/:id/cancel", Tag: "maintenance", Summary: "Cancel maintenance",
+		Access: StaffOnly, Body: models.MaintenanceCancelRequest{}, Response: models.MaintenanceRecord{}},
+	{Method: http.MethodPut, Path: "/api/maintenance-schedules/:id", Tag: "maintenance", Summary: "Update maintenance schedule",
+		Access: StaffOnly, Body: models.MaintenanceScheduleUpdateRequest{}, Response: models.MaintenanceSchedule{}},
+	{Method: http.MethodDelete, Path: "/api/maintenance-schedules/:id", Tag: "maintenance", Summary: "Delete maintenance schedule", Access: StaffOnly},
+
+	// Reports
+	{Method: http.MethodGet, Path: "/api/reports/valuation", Tag: "reports", Summary: "Depreciated book value per toolkit, category and total (format=csv to export)",
+		Access: AdminOnly, Query: models.ValuationRequest{}, Response: models.ValuationReport{}},
+}
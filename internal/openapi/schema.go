@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema subset JSON Schema yang dipakai OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+)
+
+// schemaRegistry membuat schema dari struct Go (json + binding tag) dan menyimpan
+// struct bernama sebagai components.schemas supaya relasi rekursif aman.
+type schemaRegistry struct {
+	components map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: map[string]*Schema{}}
+}
+
+// schemaOf mengembalikan $ref untuk struct bernama, inline untuk tipe lain
+func (r *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := r.baseSchema(t)
+	if nullable && s.Ref == "" {
+		if typ, ok := s.Type.(string); ok {
+			s.Type = []string{typ, "null"}
+		}
+	}
+	return s
+}
+
+func (r *schemaRegistry) baseSchema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t == fileHeaderType {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := r.components[name]; !ok {
+			// Placeholder dulu supaya relasi melingkar (Toolkit <-> Category) tidak loop
+			r.components[name] = &Schema{}
+			*r.components[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interface{} / any
+		return &Schema{}
+	}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := fieldName(field, "json")
+		if !ok {
+			continue
+		}
+
+		// Embedded struct tanpa json name di-flatten seperti encoding/json
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft)
+				continue
+			}
+		}
+
+		prop := r.schemaOf(field.Type)
+		if applyBinding(prop, field) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// queryParameters membuat parameter query dari struct dengan form tag
+func (r *schemaRegistry) queryParameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Embedded struct tanpa form tag di-flatten seperti binding gin
+		if field.Anonymous && field.Tag.Get("form") == "" && field.Type.Kind() == reflect.Struct {
+			params = append(params, r.queryParameters(field.Type)...)
+			continue
+		}
+
+		name, ok := fieldName(field, "form")
+		if !ok || field.Tag.Get("form") == "" {
+			continue
+		}
+
+		schema := r.schemaOf(field.Type)
+		required := applyBinding(schema, field)
+		params = append(params, Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+func fieldName(field reflect.StructField, tag string) (string, bool) {
+	name := strings.Split(field.Tag.Get(tag), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// applyBinding menerjemahkan binding tag validator ke constraint JSON Schema.
+// Return true kalau field wajib diisi.
+func applyBinding(s *Schema, field reflect.StructField) bool {
+	tag := field.Tag.Get("binding")
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "dive":
+			// Rule setelah dive berlaku untuk tiap item slice
+			if s.Items == nil {
+				return required
+			}
+			s = s.Items
+		case "email":
+			s.Format = "email"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			if s.Type == "array" {
+				if key == "min" {
+					s.MinItems = &n
+				} else {
+					s.MaxItems = &n
+				}
+				continue
+			}
+			if s.Type == "string" {
+				if key == "min" {
+					s.MinLength = &n
+				} else {
+					s.MaxLength = &n
+				}
+				continue
+			}
+			f := float64(n)
+			if key == "min" {
+				s.Minimum = &f
+			} else {
+				s.Maximum = &f
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,335 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/pkg/utils"
+)
+
+const Version = "3.1.0"
+
+// Access level route, menentukan security requirement di spec
+type Access int
+
+const (
+	Public Access = iota
+	Authenticated
+	AdminOnly
+	StaffOnly // admin atau technician
+)
+
+// Operation deskripsi satu route yang didaftarkan di main.go.
+// Body/Query/Response diisi dengan zero value struct model, schema di-generate via reflection.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Access      Access
+	Query       any
+	Body        any
+	Response    any
+	Status      int
+	Paginated   bool
+	ContentType string
+	Produces    string
+	Raw         bool // response tidak dibungkus envelope {success, message, data}
+	RateLimited bool // route publik yang kena rate limit (route terproteksi selalu kena)
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem satu operation (method) di bawah path
+type PathItem struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// Spec dokumen OpenAPI untuk semua route di Operations, dibangun sekali
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = Build(Operations)
+	})
+	return spec
+}
+
+// Build membuat dokumen OpenAPI dari daftar operation
+func Build(operations []Operation) *Document {
+	registry := newSchemaRegistry()
+	registry.components["Problem"] = problemSchema()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Toolkit Management API",
+			Version:     "1.0.0",
+			Description: "Inventory and loan management for network toolkits.",
+		},
+		Servers: []Server{{URL: "/"}},
+		Paths:   map[string]map[string]*PathItem{},
+		Components: Components{
+			Schemas: registry.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "JWT from /api/auth/login, or a personal API token (tkm_...) from /api/auth/tokens"},
+			},
+		},
+	}
+
+	tags := map[string]bool{}
+	for _, op := range operations {
+		path := toOpenAPIPath(op.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = buildOperation(registry, op)
+
+		if op.Tag != "" && !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: op.Tag})
+		}
+	}
+
+	return doc
+}
+
+func buildOperation(registry *schemaRegistry, op Operation) *PathItem {
+	item := &PathItem{
+		Summary:     op.Summary,
+		OperationID: operationID(op),
+		Responses:   map[string]Response{},
+	}
+	if op.Tag != "" {
+		item.Tags = []string{op.Tag}
+	}
+
+	for _, name := range pathParams(op.Path) {
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = &Schema{Type: "integer"}
+		}
+		item.Parameters = append(item.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	if op.Query != nil {
+		item.Parameters = append(item.Parameters, registry.queryParameters(reflect.TypeOf(op.Query))...)
+	}
+
+	if op.Body != nil {
+		contentType := "application/json"
+		if op.ContentType != "" {
+			contentType = op.ContentType
+		}
+		item.RequestBody = &RequestBody{
+			Required: op.Method != http.MethodGet,
+			Content:  map[string]MediaType{contentType: {Schema: registry.schemaOf(reflect.TypeOf(op.Body))}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	produces := "application/json"
+	if op.Produces != "" {
+		produces = op.Produces
+	}
+	item.Responses[strconv.Itoa(status)] = Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{produces: {Schema: responseSchema(registry, op)}},
+	}
+
+	problemRef := map[string]MediaType{"application/problem+json": {Schema: &Schema{Ref: "#/components/schemas/Problem"}}}
+	if op.Body != nil || op.Query != nil || len(pathParams(op.Path)) > 0 {
+		item.Responses["400"] = Response{Description: "Validation failed", Content: problemRef}
+	}
+	if op.Access != Public {
+		item.Security = []map[string][]string{{"bearerAuth": {}}}
+		item.Responses["401"] = Response{Description: "Unauthorized", Content: problemRef}
+	}
+	if op.Access == AdminOnly || op.Access == StaffOnly {
+		item.Responses["403"] = Response{Description: "Forbidden", Content: problemRef}
+	}
+	if op.Access != Public || op.RateLimited {
+		item.Responses["429"] = Response{Description: "Rate limit exceeded, see Retry-After", Content: problemRef}
+	}
+	if len(pathParams(op.Path)) > 0 {
+		item.Responses["404"] = Response{Description: "Not found", Content: problemRef}
+	}
+	item.Responses["500"] = Response{Description: "Internal error", Content: problemRef}
+
+	return item
+}
+
+// responseSchema bungkus schema response dengan envelope standar handler
+func responseSchema(registry *schemaRegistry, op Operation) *Schema {
+	var data *Schema
+	if op.Response != nil {
+		data = registry.schemaOf(reflect.TypeOf(op.Response))
+	}
+	if op.Raw {
+		if data == nil {
+			return &Schema{Type: "object"}
+		}
+		return data
+	}
+
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"success", "message"},
+	}
+	if data != nil {
+		envelope.Properties["data"] = data
+	}
+	if op.Paginated {
+		envelope.Properties["pagination"] = &Schema{OneOf: []*Schema{
+			registry.schemaOf(reflect.TypeOf(utils.PaginationResponse{})),
+			registry.schemaOf(reflect.TypeOf(utils.CursorPaginationResponse{})),
+		}}
+	}
+	return envelope
+}
+
+func problemSchema() *Schema {
+	str := func() *Schema { return &Schema{Type: "string"} }
+	return &Schema{
+		Type:        "object",
+		Description: "RFC 7807 problem details",
+		Properties: map[string]*Schema{
+			"type":     str(),
+			"title":    str(),
+			"status":   {Type: "integer"},
+			"detail":   str(),
+			"instance": str(),
+			"code":     str(),
+			"success":  {Type: "boolean"},
+			"errors": {Type: "array", Items: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"field": str(), "message": str()},
+			}},
+		},
+		Required: []string{"type", "title", "status", "code"},
+	}
+}
+
+// MissingRoutes route gin yang belum ada di spec (format "METHOD /path")
+func MissingRoutes(routes gin.RoutesInfo) []string {
+	doc := Spec()
+
+	var missing []string
+	for _, route := range routes {
+		ops, ok := doc.Paths[toOpenAPIPath(route.Path)]
+		if !ok || ops[strings.ToLower(route.Method)] == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// toOpenAPIPath ubah /toolkits/:id jadi /toolkits/{id}
+func toOpenAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, p := range strings.Split(path, "/") {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			params = append(params, p[1:])
+		}
+	}
+	return params
+}
+
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, p := range strings.Split(op.Path, "/") {
+		p = strings.TrimLeft(p, ":*")
+		for _, word := range strings.FieldsFunc(p, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
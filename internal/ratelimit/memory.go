@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore token bucket di memori proses
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+	burst := limit.burst()
+	rate := limit.ratePerSecond()
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		// Bucket baru (atau limit berubah, mis. role user diganti) mulai penuh
+		b = &bucket{tokens: float64(burst), last: now, limit: limit}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	b.last = now
+
+	result := Result{Limit: burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((float64(burst) - b.tokens) / rate)
+	return result, nil
+}
+
+// Cleanup buang bucket yang sudah penuh lagi (idle), dipanggil periodik oleh scheduler
+func (s *MemoryStore) Cleanup(_ context.Context) error {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		burst := float64(b.limit.burst())
+		if b.tokens+now.Sub(b.last).Seconds()*b.limit.ratePerSecond() >= burst {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestStore store dengan jam yang dimajukan manual lewat advance
+func newTestStore() (*MemoryStore, func(time.Duration)) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func take(t *testing.T, store *MemoryStore, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	return result
+}
+
+func TestMemoryStoreBurstThenReject(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{RequestsPerMinute: 60, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result := take(t, store, "ip:1", limit)
+		if !result.Allowed || result.Remaining != i || result.Limit != 3 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", 3-i, result, i)
+		}
+	}
+
+	result := take(t, store, "ip:1", limit)
+	if result.Allowed {
+		t.Fatal("request over burst was allowed")
+	}
+	if result.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s at 1 token/s", result.RetryAfter)
+	}
+	if result.Reset != 3*time.Second {
+		t.Errorf("Reset = %v, want 3s until the bucket is full", result.Reset)
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store, advance := newTestStore()
+	limit := Limit{RequestsPerMinute: 30, Burst: 2}
+
+	take(t, store, "ip:1", limit)
+	take(t, store, "ip:1", limit)
+	if take(t, store, "ip:1", limit).Allowed {
+		t.Fatal("empty bucket allowed a request")
+	}
+
+	// 30/menit = satu token per 2 detik
+	advance(time.Second)
+	if take(t, store, "ip:1", limit).Allowed {
+		t.Fatal("half a token allowed a request")
+	}
+	advance(time.Second)
+	if !take(t, store, "ip:1", limit).Allowed {
+		t.Fatal("refilled token was not available")
+	}
+
+	// Isi ulang tidak melebihi burst
+	advance(time.Hour)
+	if result := take(t, store, "ip:1", limit); result.Remaining != 1 {
+		t.Errorf("Remaining = %d, want burst-1 after a long idle", result.Remaining)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{RequestsPerMinute: 1}
+
+	if !take(t, store, "ip:1", limit).Allowed {
+		t.Fatal("first request rejected")
+	}
+	if take(t, store, "ip:1", limit).Allowed {
+		t.Fatal("second request for the same key allowed")
+	}
+	if !take(t, store, "ip:2", limit).Allowed {
+		t.Error("other key shares the bucket")
+	}
+}
+
+func TestMemoryStoreLimitChangeStartsFullBucket(t *testing.T) {
+	store, _ := newTestStore()
+
+	take(t, store, "user:1", Limit{RequestsPerMinute: 1})
+	if take(t, store, "user:1", Limit{RequestsPerMinute: 1}).Allowed {
+		t.Fatal("bucket not exhausted")
+	}
+	// Role naik, mis. user jadi admin
+	if result := take(t, store, "user:1", Limit{RequestsPerMinute: 10}); !result.Allowed || result.Remaining != 9 {
+		t.Errorf("result = %+v, want a fresh bucket for the new limit", result)
+	}
+}
+
+func TestMemoryStoreUnlimited(t *testing.T) {
+	store, _ := newTestStore()
+	for range 100 {
+		if !take(t, store, "ip:1", Limit{}).Allowed {
+			t.Fatal("unlimited request rejected")
+		}
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("buckets = %d, unlimited requests must not create buckets", len(store.buckets))
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	store, advance := newTestStore()
+	limit := Limit{RequestsPerMinute: 60, Burst: 2}
+
+	take(t, store, "idle", limit)
+	advance(2 * time.Second)
+	take(t, store, "busy", limit)
+	take(t, store, "busy", limit)
+
+	if err := store.Cleanup(context.Background()); err != nil {
+		t.Fatalf("Cleanup: %v", err)
+	}
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket that refilled completely was kept")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Error("bucket that is still refilling was removed")
+	}
+}
+
+func TestPolicyLimitFor(t *testing.T) {
+	policy := Policy{
+		Default: Limit{RequestsPerMinute: 10},
+		Roles:   map[string]Limit{"admin": {RequestsPerMinute: 100}},
+	}
+	tests := map[string]int{"admin": 100, "user": 10, "": 10}
+	for role, want := range tests {
+		if got := policy.LimitFor(role).RequestsPerMinute; got != want {
+			t.Errorf("LimitFor(%q) = %d, want %d", role, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit token bucket: Burst token maksimal, diisi ulang RequestsPerMinute per menit.
+// Burst 0 berarti sama dengan RequestsPerMinute.
+type Limit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+func (l Limit) burst() int {
+	if l.Burst <= 0 {
+		return max(l.RequestsPerMinute, 1)
+	}
+	return l.Burst
+}
+
+// ratePerSecond kecepatan isi ulang bucket
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.RequestsPerMinute) / 60
+}
+
+// Unlimited true kalau limit tidak dibatasi (0 request per menit)
+func (l Limit) Unlimited() bool {
+	return l.RequestsPerMinute <= 0
+}
+
+// Result hasil satu kali ambil token, dipakai untuk header RateLimit-*
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset waktu sampai bucket penuh lagi
+	Reset time.Duration
+	// RetryAfter waktu tunggu sampai satu token tersedia, hanya terisi kalau ditolak
+	RetryAfter time.Duration
+}
+
+// Store penyimpanan bucket. Implementasi in-memory cukup untuk satu instance,
+// deployment multi-instance perlu store bersama (mis. Redis) dengan interface yang sama.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Policy limit untuk satu route group, bisa di-override per role
+type Policy struct {
+	Name    string
+	Default Limit
+	Roles   map[string]Limit
+}
+
+// LimitFor limit yang berlaku untuk role, role kosong berarti anonim
+func (p Policy) LimitFor(role string) Limit {
+	if limit, ok := p.Roles[role]; ok && role != "" {
+		return limit
+	}
+	return p.Default
+}
+
+// Seconds durasi dalam detik dibulatkan ke atas (format header RateLimit-Reset/Retry-After)
+func Seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *models.APIToken) (*models.APIToken, error)
+	GetByID(ctx context.Context, id int) (*models.APIToken, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIToken, error)
+	GetByUserID(ctx context.Context, userID int) ([]*models.APIToken, error)
+	Revoke(ctx context.Context, id int, at time.Time) error
+	TouchLastUsed(ctx context.Context, id int, at time.Time, ip string) error
+}
+
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, token *models.APIToken) (*models.APIToken, error) {
+	result := r.db.WithContext(ctx).Create(token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return token, nil
+}
+
+func (r *apiTokenRepository) GetByID(ctx context.Context, id int) (*models.APIToken, error) {
+	var token models.APIToken
+	result := r.db.WithContext(ctx).First(&token, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// GetByHash token beserta pemiliknya, dipakai saat autentikasi
+func (r *apiTokenRepository) GetByHash(ctx context.Context, hash string) (*models.APIToken, error) {
+	var token models.APIToken
+	result := r.db.WithContext(ctx).Preload("User").Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetByUserID(ctx context.Context, userID int) ([]*models.APIToken, error) {
+	var tokens []*models.APIToken
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.Error
+}
+
+// TouchLastUsed update kolom pemakaian saja, tanpa menyentuh updated_at
+func (r *apiTokenRepository) TouchLastUsed(ctx context.Context, id int, at time.Time, ip string) error {
+	result := r.db.WithContext(ctx).Model(&models.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": at, "last_used_ip": ip})
+	return result.Error
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *models.Attachment) (*models.Attachment, error)
+	GetByID(ctx context.Context, id int) (*models.Attachment, error)
+	// GetByToolkit foto dulu, lalu dokumen, terbaru di atas
+	GetByToolkit(ctx context.Context, toolkitID int) ([]models.Attachment, error)
+	Delete(ctx context.Context, id int) error
+	// SetToolkitImage set Toolkit.PrimaryImageID tanpa menyentuh kolom lain
+	SetToolkitImage(ctx context.Context, toolkitID, attachmentID int) error
+	// ReplaceToolkitImage ganti Toolkit.PrimaryImageID hanya kalau nilainya masih current,
+	// next nil = tanpa foto utama
+	ReplaceToolkitImage(ctx context.Context, toolkitID, current int, next *int) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *models.Attachment) (*models.Attachment, error) {
+	result := r.db.WithContext(ctx).Create(attachment)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return attachment, nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	result := r.db.WithContext(ctx).First(&attachment, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetByToolkit(ctx context.Context, toolkitID int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	result := r.db.WithContext(ctx).Where("toolkit_id = ?", toolkitID).
+		Order("kind DESC, created_at DESC, id DESC").Find(&attachments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Attachment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *attachmentRepository) SetToolkitImage(ctx context.Context, toolkitID, attachmentID int) error {
+	return r.db.WithContext(ctx).Model(&models.Toolkit{}).
+		Where("id = ?", toolkitID).Update("primary_image_id", attachmentID).Error
+}
+
+func (r *attachmentRepository) ReplaceToolkitImage(ctx context.Context, toolkitID, current int, next *int) error {
+	return r.db.WithContext(ctx).Model(&models.Toolkit{}).
+		Where("id = ? AND primary_image_id = ?", toolkitID, current).Update("primary_image_id", next).Error
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// ErrLoanChanged loan sudah dikembalikan atau diubah request lain sejak dibaca
+var ErrLoanChanged = errors.New("loan was changed concurrently")
+
+// BundleReturn pengembalian sebagian atau seluruh unit satu loan komponen
+type BundleReturn struct {
+	Loan     *models.Loan
+	Quantity int
+}
+
+type BundleRepository interface {
+	Create(ctx context.Context, bundle *models.Bundle) (*models.Bundle, error)
+	// GetByID bundle beserta komponen dan toolkit-nya
+	GetByID(ctx context.Context, id int) (*models.Bundle, error)
+	GetAll(ctx context.Context, filter *models.BundleFilterRequest) ([]*models.Bundle, error)
+	// Update simpan bundle; components non-nil menggantikan seluruh komponen lama
+	Update(ctx context.Context, bundle *models.Bundle, components []models.BundleComponent) (*models.Bundle, error)
+	Delete(ctx context.Context, id int) error
+	HasActiveLoans(ctx context.Context, bundleID int) (bool, error)
+
+	// Checkout simpan loan induk dan semua loan komponen dalam satu transaksi. Stok tiap
+	// toolkit dikurangi bersyarat; satu komponen kurang = semuanya batal (ErrInsufficientAvailable).
+	Checkout(ctx context.Context, parent *models.BundleLoan, loans []models.Loan) error
+	GetLoanByID(ctx context.Context, id int) (*models.BundleLoan, error)
+	GetLoans(ctx context.Context, filter *models.BundleLoanFilterRequest) ([]*models.BundleLoan, error)
+	// Return kembalikan unit ke stok di lokasi to (nil = lokasi asal loan). Loan yang dikembalikan
+	// sebagian dipecah: sisa tetap borrowed, bagian yang kembali jadi loan returned baru.
+	Return(ctx context.Context, returns []BundleReturn, to *int, condition, notes string) error
+}
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+func NewBundleRepository(db *gorm.DB) BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+func (r *bundleRepository) Create(ctx context.Context, bundle *models.Bundle) (*models.Bundle, error) {
+	result := r.db.WithContext(ctx).Omit("Components.Toolkit").Create(bundle)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return bundle, nil
+}
+
+func (r *bundleRepository) GetByID(ctx context.Context, id int) (*models.Bundle, error) {
+	var bundle models.Bundle
+	result := r.db.WithContext(ctx).
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Components.Toolkit").
+		First(&bundle, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &bundle, nil
+}
+
+func (r *bundleRepository) GetAll(ctx context.Context, filter *models.BundleFilterRequest) ([]*models.Bundle, error) {
+	var bundles []*models.Bundle
+
+	query := r.db.WithContext(ctx).
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Components.Toolkit")
+	if filter.SearchTerm != "" {
+		term := "%" + filter.SearchTerm + "%"
+		query = query.Where("name ILIKE ? OR code ILIKE ?", term, term)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	result := query.Order("name ASC").Find(&bundles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return bundles, nil
+}
+
+func (r *bundleRepository) Update(ctx context.Context, bundle *models.Bundle, components []models.BundleComponent) (*models.Bundle, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Components").Save(bundle).Error; err != nil {
+			return err
+		}
+		if components == nil {
+			return nil
+		}
+		if err := tx.Where("bundle_id = ?", bundle.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		for i := range components {
+			components[i].BundleID = bundle.ID
+		}
+		return tx.Omit("Toolkit").Create(&components).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (r *bundleRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Bundle{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *bundleRepository) HasActiveLoans(ctx context.Context, bundleID int) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.Loan{}).
+		Joins("JOIN bundle_loans ON bundle_loans.id = loans.bundle_loan_id").
+		Where("bundle_loans.bundle_id = ? AND loans.status <> ?", bundleID, "returned").
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *bundleRepository) Checkout(ctx context.Context, parent *models.BundleLoan, loans []models.Loan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Bundle", "User", "Loans").Create(parent).Error; err != nil {
+			return err
+		}
+		for i := range loans {
+			loans[i].BundleLoanID = &parent.ID
+		}
+		if err := tx.Omit("User", "Toolkit").Create(&loans).Error; err != nil {
+			return err
+		}
+		for _, loan := range loans {
+			if err := checkoutStock(tx, loan.ID, loan.ToolkitID, loan.LocationID, loan.Quantity); err != nil {
+				return fmt.Errorf("toolkit %d: %w", loan.ToolkitID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *bundleRepository) GetLoanByID(ctx context.Context, id int) (*models.BundleLoan, error) {
+	var loan models.BundleLoan
+	result := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Loans", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Loans.Toolkit").
+		First(&loan, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &loan, nil
+}
+
+func (r *bundleRepository) GetLoans(ctx context.Context, filter *models.BundleLoanFilterRequest) ([]*models.BundleLoan, error) {
+	var loans []*models.BundleLoan
+
+	query := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Loans", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Loans.Toolkit")
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.BundleID != 0 {
+		query = query.Where("bundle_id = ?", filter.BundleID)
+	}
+	if filter.Active {
+		query = query.Where("EXISTS (SELECT 1 FROM loans WHERE loans.bundle_loan_id = bundle_loans.id AND loans.status <> ?)", "returned")
+	}
+
+	result := query.Order("borrow_date DESC").Find(&loans)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return loans, nil
+}
+
+func (r *bundleRepository) Return(ctx context.Context, returns []BundleReturn, to *int, condition, notes string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, ret := range returns {
+			loan := ret.Loan
+			dest := loan.LocationID
+			if to != nil {
+				dest = to
+			}
+			// Bersyarat supaya return ganda yang bersamaan tidak menambah stok dua kali
+			active := tx.Model(&models.Loan{}).
+				Where("id = ? AND status IN ? AND quantity = ?", loan.ID, []string{"borrowed", "overdue"}, loan.Quantity)
+			var result *gorm.DB
+			if ret.Quantity == loan.Quantity {
+				updates := map[string]interface{}{
+					"status":             "returned",
+					"return_date":        now,
+					"condition_return":   condition,
+					"return_location_id": dest,
+				}
+				if notes != "" {
+					updates["notes"] = notes
+				}
+				result = active.Updates(updates)
+			} else {
+				result = active.Update("quantity", gorm.Expr("quantity - ?", ret.Quantity))
+			}
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("loan %d: %w", loan.ID, ErrLoanChanged)
+			}
+
+			if ret.Quantity < loan.Quantity {
+				returned := models.Loan{
+					UserID:           loan.UserID,
+					ToolkitID:        loan.ToolkitID,
+					Quantity:         ret.Quantity,
+					Purpose:          loan.Purpose,
+					BorrowDate:       loan.BorrowDate,
+					DueDate:          loan.DueDate,
+					ReturnDate:       &now,
+					Status:           "returned",
+					ApprovedBy:       loan.ApprovedBy,
+					Notes:            notes,
+					ConditionChecked: loan.ConditionChecked,
+					ConditionReturn:  condition,
+					BundleLoanID:     loan.BundleLoanID,
+					LocationID:       loan.LocationID,
+					ReturnLocationID: dest,
+				}
+				if err := tx.Omit("User", "Toolkit").Create(&returned).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := returnStock(tx, loan.ID, loan.ToolkitID, loan.LocationID, dest, ret.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
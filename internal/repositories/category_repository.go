@@ -1,18 +1,20 @@
 package repositories
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 
 	"toolkit-management/internal/models"
 )
 
 type CategoryRepository interface {
-	Create(category *models.Category) (*models.Category, error)
-	GetByID(id int) (*models.Category, error)
-	GetAll(filter *models.CategoryFilterRequest) ([]models.Category, error)
-	Update(category *models.Category) (*models.Category, error)
-	Delete(id int) error
-	GetTree() ([]models.Category, error)
+	Create(ctx context.Context, category *models.Category) (*models.Category, error)
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	GetAll(ctx context.Context, filter *models.CategoryFilterRequest) ([]models.Category, error)
+	Update(ctx context.Context, category *models.Category) (*models.Category, error)
+	Delete(ctx context.Context, id int) error
+	GetTree(ctx context.Context) ([]models.Category, error)
 }
 
 type categoryRepository struct {
@@ -23,26 +25,26 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) Create(category *models.Category) (*models.Category, error) {
-	result := r.db.Create(category)
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) (*models.Category, error) {
+	result := r.db.WithContext(ctx).Create(category)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return category, nil
 }
 
-func (r *categoryRepository) GetByID(id int) (*models.Category, error) {
+func (r *categoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
 	var category models.Category
-	result := r.db.First(&category, id)
+	result := r.db.WithContext(ctx).First(&category, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &category, nil
 }
 
-func (r *categoryRepository) GetAll(filter *models.CategoryFilterRequest) ([]models.Category, error) {
+func (r *categoryRepository) GetAll(ctx context.Context, filter *models.CategoryFilterRequest) ([]models.Category, error) {
 	var categories []models.Category
-	query := r.db.Model(&models.Category{})
+	query := r.db.WithContext(ctx).Model(&models.Category{})
 
 	if filter.SearchTerm != "" {
 		query = query.Where("name ILIKE ? OR description ILIKE ?",
@@ -61,23 +63,23 @@ func (r *categoryRepository) GetAll(filter *models.CategoryFilterRequest) ([]mod
 	return categories, nil
 }
 
-func (r *categoryRepository) Update(category *models.Category) (*models.Category, error) {
-	result := r.db.Save(category)
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) (*models.Category, error) {
+	result := r.db.WithContext(ctx).Save(category)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return category, nil
 }
 
-func (r *categoryRepository) Delete(id int) error {
-	result := r.db.Delete(&models.Category{}, id)
+func (r *categoryRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Category{}, id)
 	return result.Error
 }
 
-func (r *categoryRepository) GetTree() ([]models.Category, error) {
+func (r *categoryRepository) GetTree(ctx context.Context) ([]models.Category, error) {
 	var categories []models.Category
 
-	result := r.db.Order("sort_order ASC, name ASC").Find(&categories)
+	result := r.db.WithContext(ctx).Order("sort_order ASC, name ASC").Find(&categories)
 
 	if result.Error != nil {
 		return nil, result.Error
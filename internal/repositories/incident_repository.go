@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// ErrIncidentChanged status incident sudah berubah sejak dibaca (resolve ganda)
+var ErrIncidentChanged = errors.New("incident was changed concurrently")
+
+// IncidentResolution efek stok dari resolusi incident, dijalankan dalam satu transaksi.
+// Stok lokasi incident (LocationID) ikut berubah.
+type IncidentResolution struct {
+	// Restock jumlah unit yang kembali ke Toolkit.Available
+	Restock int
+	// Adjustment pengurangan Toolkit.Quantity (write-off), nil kalau tidak ada
+	Adjustment *models.StockAdjustment
+	// CloseLoan loan ditutup sebagai returned dan Restock dicatat sebagai loan_return.
+	// Hanya kalau status loan masih LoanStatus, kalau tidak ErrLoanChanged.
+	CloseLoan  bool
+	LoanStatus string
+}
+
+type IncidentRepository interface {
+	// Create simpan incident. Kalau HeldFromStock, unit ditarik dari Available dan stok lokasi
+	// secara bersyarat (ErrInsufficientAvailable); kalau tidak, status loan diubah jadi damaged.
+	Create(ctx context.Context, incident *models.Incident) (*models.Incident, error)
+	GetByID(ctx context.Context, id int) (*models.Incident, error)
+	GetAll(ctx context.Context, filter *models.IncidentFilterRequest) ([]*models.Incident, error)
+	// GetOpenByLoan incident yang belum resolved untuk loan
+	GetOpenByLoan(ctx context.Context, loanID int) (*models.Incident, error)
+	Update(ctx context.Context, incident *models.Incident) (*models.Incident, error)
+	AddEvidence(ctx context.Context, evidence *models.IncidentEvidence) (*models.IncidentEvidence, error)
+	// Resolve hanya untuk incident yang masih assessed, kalau tidak ErrIncidentChanged
+	Resolve(ctx context.Context, incident *models.Incident, resolution IncidentResolution) error
+	GetAdjustments(ctx context.Context, toolkitID int) ([]*models.StockAdjustment, error)
+}
+
+type incidentRepository struct {
+	db *gorm.DB
+}
+
+func NewIncidentRepository(db *gorm.DB) IncidentRepository {
+	return &incidentRepository{db: db}
+}
+
+func (r *incidentRepository) Create(ctx context.Context, incident *models.Incident) (*models.Incident, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if incident.HeldFromStock {
+			if err := takeStock(tx, incident.ToolkitID, incident.LocationID, incident.Quantity, "borrowed"); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Model(&models.Loan{}).
+				Where("id = ?", incident.LoanID).
+				Update("status", "damaged").Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Loan", "Toolkit", "User").Create(incident).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (r *incidentRepository) GetByID(ctx context.Context, id int) (*models.Incident, error) {
+	var incident models.Incident
+	result := r.db.WithContext(ctx).
+		Preload("Evidence").Preload("Loan").Preload("Toolkit").Preload("User").
+		First(&incident, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &incident, nil
+}
+
+func (r *incidentRepository) GetAll(ctx context.Context, filter *models.IncidentFilterRequest) ([]*models.Incident, error) {
+	var incidents []*models.Incident
+
+	query := r.db.WithContext(ctx).Preload("Toolkit").Preload("User")
+	if filter.LoanID != 0 {
+		query = query.Where("loan_id = ?", filter.LoanID)
+	}
+	if filter.ToolkitID != 0 {
+		query = query.Where("toolkit_id = ?", filter.ToolkitID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	result := query.Order("created_at DESC").Find(&incidents)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return incidents, nil
+}
+
+func (r *incidentRepository) GetOpenByLoan(ctx context.Context, loanID int) (*models.Incident, error) {
+	var incident models.Incident
+	result := r.db.WithContext(ctx).
+		Where("loan_id = ? AND status <> ?", loanID, models.IncidentResolved).
+		First(&incident)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &incident, nil
+}
+
+func (r *incidentRepository) Update(ctx context.Context, incident *models.Incident) (*models.Incident, error) {
+	result := r.db.WithContext(ctx).Omit("Evidence", "Loan", "Toolkit", "User").Save(incident)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return incident, nil
+}
+
+func (r *incidentRepository) AddEvidence(ctx context.Context, evidence *models.IncidentEvidence) (*models.IncidentEvidence, error) {
+	result := r.db.WithContext(ctx).Create(evidence)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return evidence, nil
+}
+
+func (r *incidentRepository) Resolve(ctx context.Context, incident *models.Incident, resolution IncidentResolution) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bersyarat supaya resolve bersamaan tidak mengubah stok dua kali
+		result := tx.Model(incident).Where("status = ?", models.IncidentAssessed).
+			Select("*").Omit("Evidence", "Loan", "Toolkit", "User").
+			Updates(incident)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrIncidentChanged
+		}
+
+		if resolution.Adjustment != nil {
+			change := -resolution.Adjustment.QuantityChange
+			result := tx.Model(&models.Toolkit{}).
+				Where("id = ? AND quantity >= ?", incident.ToolkitID, change).
+				Updates(map[string]interface{}{
+					"quantity": gorm.Expr("quantity - ?", change),
+					"status":   gorm.Expr("CASE WHEN quantity - ? = 0 THEN ? ELSE status END", change, "retired"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrInsufficientAvailable
+			}
+			if err := tx.Create(resolution.Adjustment).Error; err != nil {
+				return err
+			}
+			// Unit yang ditahan di lokasi ikut keluar dari stok lokasi
+			if incident.LocationID != nil {
+				if err := writeOffStock(tx, resolution.Adjustment, *incident.LocationID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !resolution.CloseLoan {
+			if resolution.Restock == 0 {
+				return nil
+			}
+			return restoreStock(tx, incident.ToolkitID, incident.LocationID, resolution.Restock)
+		}
+
+		// Bersyarat supaya loan yang sudah dikembalikan tidak menambah stok dua kali
+		result = tx.Model(&models.Loan{}).
+			Where("id = ? AND status = ?", incident.LoanID, resolution.LoanStatus).
+			Updates(map[string]interface{}{
+				"status":             "returned",
+				"return_date":        time.Now(),
+				"return_location_id": incident.LocationID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLoanChanged
+		}
+		if resolution.Restock == 0 {
+			return nil
+		}
+		return returnStock(tx, incident.LoanID, incident.ToolkitID, incident.LocationID, incident.LocationID, resolution.Restock)
+	})
+}
+
+func (r *incidentRepository) GetAdjustments(ctx context.Context, toolkitID int) ([]*models.StockAdjustment, error) {
+	var adjustments []*models.StockAdjustment
+	result := r.db.WithContext(ctx).Where("toolkit_id = ?", toolkitID).Order("created_at DESC").Find(&adjustments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return adjustments, nil
+}
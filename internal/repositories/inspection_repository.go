@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+type InspectionRepository interface {
+	CreateChecklistItem(ctx context.Context, item *models.ChecklistItem) (*models.ChecklistItem, error)
+	GetChecklistItem(ctx context.Context, id int) (*models.ChecklistItem, error)
+	// GetChecklist item checklist kategori, activeOnly untuk item yang wajib diisi saat inspeksi
+	GetChecklist(ctx context.Context, categoryID int, activeOnly bool) ([]*models.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) (*models.ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, id int) error
+
+	// Create simpan inspeksi beserta hasilnya, sekaligus sinkronkan kondisi di loan
+	// (dan di toolkit untuk inspeksi return) dalam satu transaksi. Inspeksi return hanya
+	// untuk loan yang masih returned, kalau tidak ErrLoanChanged.
+	Create(ctx context.Context, inspection *models.Inspection) (*models.Inspection, error)
+	GetByID(ctx context.Context, id int) (*models.Inspection, error)
+	GetByLoanAndKind(ctx context.Context, loanID int, kind string) (*models.Inspection, error)
+	GetAll(ctx context.Context, filter *models.InspectionFilterRequest) ([]*models.Inspection, error)
+	MarkReviewed(ctx context.Context, id int, reviewerID int, at time.Time, notes string) error
+	// CountPendingReturns loan toolkit yang sudah returned dengan inspeksi checkout tapi belum
+	// ada inspeksi return. Loan tanpa inspeksi checkout (sebelum inspeksi diwajibkan) tidak dihitung.
+	CountPendingReturns(ctx context.Context, toolkitID int) (int64, error)
+}
+
+type inspectionRepository struct {
+	db *gorm.DB
+}
+
+func NewInspectionRepository(db *gorm.DB) InspectionRepository {
+	return &inspectionRepository{db: db}
+}
+
+func (r *inspectionRepository) CreateChecklistItem(ctx context.Context, item *models.ChecklistItem) (*models.ChecklistItem, error) {
+	result := r.db.WithContext(ctx).Create(item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return item, nil
+}
+
+func (r *inspectionRepository) GetChecklistItem(ctx context.Context, id int) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	result := r.db.WithContext(ctx).First(&item, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+func (r *inspectionRepository) GetChecklist(ctx context.Context, categoryID int, activeOnly bool) ([]*models.ChecklistItem, error) {
+	var items []*models.ChecklistItem
+	query := r.db.WithContext(ctx).Where("category_id = ?", categoryID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	result := query.Order("sort_order ASC, id ASC").Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return items, nil
+}
+
+func (r *inspectionRepository) UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) (*models.ChecklistItem, error) {
+	result := r.db.WithContext(ctx).Save(item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return item, nil
+}
+
+func (r *inspectionRepository) DeleteChecklistItem(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.ChecklistItem{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *inspectionRepository) Create(ctx context.Context, inspection *models.Inspection) (*models.Inspection, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Loan", "Inspector").Create(inspection).Error; err != nil {
+			return err
+		}
+
+		if inspection.Kind == models.InspectionCheckout {
+			return tx.Model(&models.Loan{}).
+				Where("id = ?", inspection.LoanID).
+				Update("condition_checked", inspection.Condition).Error
+		}
+
+		// Kondisi toolkit hanya diubah kalau unit benar-benar sudah kembali
+		result := tx.Model(&models.Loan{}).
+			Where("id = ? AND status = ?", inspection.LoanID, "returned").
+			Update("condition_return", inspection.Condition)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLoanChanged
+		}
+		return tx.Model(&models.Toolkit{}).
+			Where("id = ?", inspection.ToolkitID).
+			Update("condition", inspection.Condition).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inspection, nil
+}
+
+func (r *inspectionRepository) GetByID(ctx context.Context, id int) (*models.Inspection, error) {
+	var inspection models.Inspection
+	result := r.db.WithContext(ctx).Preload("Results").Preload("Inspector").First(&inspection, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &inspection, nil
+}
+
+func (r *inspectionRepository) GetByLoanAndKind(ctx context.Context, loanID int, kind string) (*models.Inspection, error) {
+	var inspection models.Inspection
+	result := r.db.WithContext(ctx).Preload("Results").
+		Where("loan_id = ? AND kind = ?", loanID, kind).
+		First(&inspection)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &inspection, nil
+}
+
+func (r *inspectionRepository) GetAll(ctx context.Context, filter *models.InspectionFilterRequest) ([]*models.Inspection, error) {
+	var inspections []*models.Inspection
+
+	query := r.db.WithContext(ctx).Preload("Results").Preload("Inspector")
+	if filter.LoanID != 0 {
+		query = query.Where("loan_id = ?", filter.LoanID)
+	}
+	if filter.ToolkitID != 0 {
+		query = query.Where("toolkit_id = ?", filter.ToolkitID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.ReviewPending {
+		query = query.Where("needs_review = ? AND reviewed_at IS NULL", true)
+	}
+
+	result := query.Order("created_at DESC").Find(&inspections)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return inspections, nil
+}
+
+func (r *inspectionRepository) MarkReviewed(ctx context.Context, id int, reviewerID int, at time.Time, notes string) error {
+	result := r.db.WithContext(ctx).Model(&models.Inspection{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"reviewed_by": reviewerID, "reviewed_at": at, "review_notes": notes})
+	return result.Error
+}
+
+func (r *inspectionRepository) CountPendingReturns(ctx context.Context, toolkitID int) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.Loan{}).
+		Where("toolkit_id = ? AND status = ?", toolkitID, "returned").
+		Where("EXISTS (SELECT 1 FROM inspections WHERE inspections.loan_id = loans.id AND inspections.kind = ?)", models.InspectionCheckout).
+		Where("NOT EXISTS (SELECT 1 FROM inspections WHERE inspections.loan_id = loans.id AND inspections.kind = ?)", models.InspectionReturn).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
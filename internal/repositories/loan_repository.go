@@ -1,18 +1,37 @@
 package repositories
 
 import (
-	"gorm.io/gorm"
+	"context"
 	"time"
 
+	"gorm.io/gorm"
+
 	"toolkit-management/internal/models"
 )
 
+// LoanStockChange efek stok dari update loan, dijalankan dalam transaksi yang sama
+type LoanStockChange struct {
+	// PrevStatus status loan saat dibaca, update batal (ErrLoanChanged) kalau sudah berubah
+	PrevStatus string
+	// Take unit tambahan yang keluar dari stok di lokasi loan (LocationID)
+	Take int
+	// Release unit yang kembali tersedia, dari lokasi From ke lokasi To
+	Release  int
+	From, To *int
+	// Incident dibuat bersama update, untuk loan yang jadi damaged tanpa incident terbuka
+	Incident *models.Incident
+}
+
 type LoanRepository interface {
-	Create(loan *models.Loan) (*models.Loan, error)
-	GetByID(id int) (*models.Loan, error)
-	GetAll(filter *models.LoanFilterRequest) ([]*models.Loan, error)
-	Update(loan *models.Loan) (*models.Loan, error)
-	Delete(id int) error
+	// Create simpan loan dan keluarkan unitnya dari stok toolkit dan lokasi asal dalam satu
+	// transaksi (ErrInsufficientAvailable kalau tidak cukup)
+	Create(ctx context.Context, loan *models.Loan) (*models.Loan, error)
+	GetByID(ctx context.Context, id int) (*models.Loan, error)
+	GetAll(ctx context.Context, filter *models.LoanFilterRequest) ([]*models.Loan, error)
+	// GetActiveByUser loan borrowed/overdue milik user, terlama dulu
+	GetActiveByUser(ctx context.Context, userID int) ([]*models.Loan, error)
+	Update(ctx context.Context, loan *models.Loan, change LoanStockChange) (*models.Loan, error)
+	Delete(ctx context.Context, id int) error
 }
 
 type loanRepository struct {
@@ -23,26 +42,31 @@ func NewLoanRepository(db *gorm.DB) LoanRepository {
 	return &loanRepository{db: db}
 }
 
-func (r *loanRepository) Create(loan *models.Loan) (*models.Loan, error) {
-	result := r.db.Create(loan)
-	if result.Error != nil {
-		return nil, result.Error
+func (r *loanRepository) Create(ctx context.Context, loan *models.Loan) (*models.Loan, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Toolkit", "Location", "ReturnLocation").Create(loan).Error; err != nil {
+			return err
+		}
+		return checkoutStock(tx, loan.ID, loan.ToolkitID, loan.LocationID, loan.Quantity)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return loan, nil
 }
 
-func (r *loanRepository) GetByID(id int) (*models.Loan, error) {
+func (r *loanRepository) GetByID(ctx context.Context, id int) (*models.Loan, error) {
 	var loan models.Loan
-	result := r.db.First(&loan, id)
+	result := r.db.WithContext(ctx).First(&loan, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &loan, nil
 }
 
-func (r *loanRepository) GetAll(filter *models.LoanFilterRequest) ([]*models.Loan, error) {
+func (r *loanRepository) GetAll(ctx context.Context, filter *models.LoanFilterRequest) ([]*models.Loan, error) {
 	var loans []*models.Loan
-	query := r.db.Model(&models.Loan{})
+	query := r.db.WithContext(ctx).Model(&models.Loan{})
 
 	if filter.UserID != 0 {
 		query = query.Where("user_id = ?", filter.UserID)
@@ -83,15 +107,52 @@ func (r *loanRepository) GetAll(filter *models.LoanFilterRequest) ([]*models.Loa
 	return loans, nil
 }
 
-func (r *loanRepository) Update(loan *models.Loan) (*models.Loan, error) {
-	result := r.db.Save(loan)
+func (r *loanRepository) GetActiveByUser(ctx context.Context, userID int) ([]*models.Loan, error) {
+	var loans []*models.Loan
+	result := r.db.WithContext(ctx).Preload("Toolkit").
+		Where("user_id = ? AND status IN ?", userID, []string{"borrowed", "overdue"}).
+		Order("borrow_date ASC, id ASC").Find(&loans)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	return loans, nil
+}
+
+func (r *loanRepository) Update(ctx context.Context, loan *models.Loan, change LoanStockChange) (*models.Loan, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bersyarat supaya return/update ganda yang bersamaan tidak mengubah stok dua kali
+		result := tx.Model(loan).Where("status = ?", change.PrevStatus).
+			Select("*").Omit("User", "Toolkit", "Location", "ReturnLocation").
+			Updates(loan)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLoanChanged
+		}
+
+		if change.Take > 0 {
+			if err := checkoutStock(tx, loan.ID, loan.ToolkitID, loan.LocationID, change.Take); err != nil {
+				return err
+			}
+		}
+		if change.Release > 0 {
+			if err := returnStock(tx, loan.ID, loan.ToolkitID, change.From, change.To, change.Release); err != nil {
+				return err
+			}
+		}
+		if change.Incident != nil {
+			return tx.Omit("Loan", "Toolkit", "User").Create(change.Incident).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return loan, nil
 }
 
-func (r *loanRepository) Delete(id int) error {
-	result := r.db.Delete(&models.Loan{}, id)
+func (r *loanRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Loan{}, id)
 	return result.Error
 }
\ No newline at end of file
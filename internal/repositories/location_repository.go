@@ -0,0 +1,422 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"toolkit-management/internal/models"
+)
+
+// ErrStockExceedsTotal jumlah yang ditempatkan di semua lokasi melebihi Toolkit.Quantity
+var ErrStockExceedsTotal = errors.New("placed stock exceeds toolkit quantity")
+
+type LocationRepository interface {
+	Create(ctx context.Context, location *models.Location) (*models.Location, error)
+	// GetByID lokasi beserta sub-lokasi langsungnya
+	GetByID(ctx context.Context, id int) (*models.Location, error)
+	GetAll(ctx context.Context, filter *models.LocationFilterRequest) ([]*models.Location, error)
+	Update(ctx context.Context, location *models.Location) (*models.Location, error)
+	Delete(ctx context.Context, id int) error
+	// HasStock ada unit toolkit yang masih ditempatkan di lokasi
+	HasStock(ctx context.Context, id int) (bool, error)
+
+	// Stock stok per toolkit di lokasi, includeChildren menggabungkan semua sub-lokasi
+	Stock(ctx context.Context, locationID int, filter *models.LocationStockFilterRequest) ([]models.LocationStockLine, error)
+	// ToolkitStocks stok satu toolkit di semua lokasi
+	ToolkitStocks(ctx context.Context, toolkitID int) ([]models.ToolkitStock, error)
+	// Unassigned stok toolkit yang belum ditempatkan di lokasi mana pun, tanpa unit in_transit
+	Unassigned(ctx context.Context, toolkitID int) (models.StockLevel, error)
+	// InTransit jumlah unit toolkit yang sedang dalam transfer
+	InTransit(ctx context.Context, toolkitID int) (int, error)
+	// SetStock tempatkan quantity unit toolkit di lokasi. Tambahan diambil dari stok yang belum
+	// ditempatkan (ErrInsufficientAvailable kalau tidak cukup), dan quantity tidak boleh kurang
+	// dari unit yang sedang dipinjam dari lokasi itu. Perubahan dicatat di riwayat stok.
+	SetStock(ctx context.Context, toolkitID, locationID, quantity, actorID int) (*models.ToolkitStock, error)
+}
+
+type locationRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &locationRepository{db: db}
+}
+
+// locationSubtree subquery id lokasi beserta semua turunannya
+func locationSubtree(db *gorm.DB, id int) *gorm.DB {
+	return db.Raw(`WITH RECURSIVE subtree AS (
+		SELECT id FROM locations WHERE id = ?
+		UNION ALL
+		SELECT l.id FROM locations l JOIN subtree s ON l.parent_id = s.id
+	) SELECT id FROM subtree`, id)
+}
+
+func (r *locationRepository) Create(ctx context.Context, location *models.Location) (*models.Location, error) {
+	result := r.db.WithContext(ctx).Omit("Parent", "Children").Create(location)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return location, nil
+}
+
+func (r *locationRepository) GetByID(ctx context.Context, id int) (*models.Location, error) {
+	var location models.Location
+	result := r.db.WithContext(ctx).
+		Preload("Children", func(db *gorm.DB) *gorm.DB { return db.Order("code ASC") }).
+		First(&location, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &location, nil
+}
+
+func (r *locationRepository) GetAll(ctx context.Context, filter *models.LocationFilterRequest) ([]*models.Location, error) {
+	var locations []*models.Location
+
+	query := r.db.WithContext(ctx)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.ParentID != 0 {
+		query = query.Where("parent_id = ?", filter.ParentID)
+	}
+	if filter.SearchTerm != "" {
+		term := "%" + filter.SearchTerm + "%"
+		query = query.Where("name ILIKE ? OR code ILIKE ?", term, term)
+	}
+
+	result := query.Order("code ASC").Find(&locations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return locations, nil
+}
+
+func (r *locationRepository) Update(ctx context.Context, location *models.Location) (*models.Location, error) {
+	result := r.db.WithContext(ctx).Omit("Parent", "Children").Save(location)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return location, nil
+}
+
+func (r *locationRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Location{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *locationRepository) HasStock(ctx context.Context, id int) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.ToolkitStock{}).
+		Where("location_id = ? AND quantity > 0", id).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *locationRepository) Stock(ctx context.Context, locationID int, filter *models.LocationStockFilterRequest) ([]models.LocationStockLine, error) {
+	lines := []models.LocationStockLine{}
+
+	query := r.db.WithContext(ctx).Table("toolkit_stocks").
+		Select("toolkit_stocks.toolkit_id, toolkits.sku, toolkits.name, " +
+			"SUM(toolkit_stocks.quantity) AS quantity, SUM(toolkit_stocks.available) AS available").
+		Joins("JOIN toolkits ON toolkits.id = toolkit_stocks.toolkit_id AND toolkits.deleted_at IS NULL")
+	if filter.IncludeChildren {
+		query = query.Where("toolkit_stocks.location_id IN (?)", locationSubtree(r.db, locationID))
+	} else {
+		query = query.Where("toolkit_stocks.location_id = ?", locationID)
+	}
+	if filter.ToolkitID != 0 {
+		query = query.Where("toolkit_stocks.toolkit_id = ?", filter.ToolkitID)
+	}
+
+	result := query.
+		Group("toolkit_stocks.toolkit_id, toolkits.sku, toolkits.name").
+		Having("SUM(toolkit_stocks.quantity) > 0").
+		Order("toolkits.sku ASC").
+		Scan(&lines)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return lines, nil
+}
+
+func (r *locationRepository) ToolkitStocks(ctx context.Context, toolkitID int) ([]models.ToolkitStock, error) {
+	stocks := []models.ToolkitStock{}
+	result := r.db.WithContext(ctx).Preload("Location").
+		Where("toolkit_id = ? AND quantity > 0", toolkitID).
+		Order("location_id ASC").Find(&stocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return stocks, nil
+}
+
+func (r *locationRepository) Unassigned(ctx context.Context, toolkitID int) (models.StockLevel, error) {
+	var level models.StockLevel
+	result := r.db.WithContext(ctx).Table("toolkits").
+		Select("toolkits.quantity - COALESCE(SUM(toolkit_stocks.quantity), 0) - "+inTransitQuantity+" AS quantity, "+
+			"toolkits.available - COALESCE(SUM(toolkit_stocks.available), 0) AS available").
+		Joins("LEFT JOIN toolkit_stocks ON toolkit_stocks.toolkit_id = toolkits.id").
+		Where("toolkits.id = ?", toolkitID).
+		Group("toolkits.id").
+		Scan(&level)
+	if result.Error != nil {
+		return level, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return level, gorm.ErrRecordNotFound
+	}
+	return level, nil
+}
+
+func (r *locationRepository) InTransit(ctx context.Context, toolkitID int) (int, error) {
+	var quantity int
+	result := r.db.WithContext(ctx).Table("toolkits").
+		Select(inTransitQuantity).
+		Where("toolkits.id = ?", toolkitID).
+		Scan(&quantity)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return quantity, nil
+}
+
+func (r *locationRepository) SetStock(ctx context.Context, toolkitID, locationID, quantity, actorID int) (*models.ToolkitStock, error) {
+	stock := &models.ToolkitStock{ToolkitID: toolkitID, LocationID: locationID}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci baris toolkit supaya penempatan paralel tidak melebihi total
+		var toolkit models.Toolkit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&toolkit, toolkitID).Error; err != nil {
+			return err
+		}
+		var placed models.StockLevel
+		if err := tx.Model(&models.ToolkitStock{}).
+			Select("COALESCE(SUM(quantity), 0) AS quantity, COALESCE(SUM(available), 0) AS available").
+			Where("toolkit_id = ? AND location_id <> ?", toolkitID, locationID).
+			Scan(&placed).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("toolkit_id = ? AND location_id = ?", toolkitID, locationID).First(stock).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		onLoan := stock.Quantity - stock.Available
+		added := quantity - stock.Quantity
+
+		if placed.Quantity+quantity > toolkit.Quantity {
+			return ErrStockExceedsTotal
+		}
+		// Unit tambahan harus ada di rak (tersedia), bukan sedang dipinjam atau maintenance
+		unassignedAvailable := toolkit.Available - placed.Available - stock.Available
+		if quantity < onLoan || (added > 0 && added > unassignedAvailable) {
+			return ErrInsufficientAvailable
+		}
+
+		stock.Quantity = quantity
+		stock.Available = quantity - onLoan
+		if err := tx.Omit("Toolkit", "Location").Save(stock).Error; err != nil {
+			return err
+		}
+		if added == 0 {
+			return nil
+		}
+		return recordMovement(tx, &models.StockAdjustment{
+			ToolkitID:      toolkitID,
+			LocationID:     &locationID,
+			QuantityChange: added,
+			Reason:         models.MovementPlacement,
+			CreatedBy:      &actorID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+// unassignedAvailable unit tersedia yang belum ditempatkan di lokasi mana pun, untuk query toolkits
+const unassignedAvailable = "available - COALESCE((SELECT SUM(toolkit_stocks.available) FROM toolkit_stocks " +
+	"WHERE toolkit_stocks.toolkit_id = toolkits.id), 0)"
+
+// unassignedQuantity unit yang tidak ditempatkan di lokasi mana pun dan tidak dalam transfer,
+// untuk query toolkits
+const unassignedQuantity = "quantity - COALESCE((SELECT SUM(toolkit_stocks.quantity) FROM toolkit_stocks " +
+	"WHERE toolkit_stocks.toolkit_id = toolkits.id), 0) - " + inTransitQuantity
+
+// inTransitQuantity unit toolkit yang sedang dalam transfer in_transit, untuk query toolkits.
+// Unit ini masih dihitung di Toolkit.Quantity tapi tidak ada di stok lokasi mana pun.
+const inTransitQuantity = "COALESCE((SELECT SUM(transfer_lines.quantity) FROM transfer_lines " +
+	"JOIN transfers ON transfers.id = transfer_lines.transfer_id " +
+	"WHERE transfer_lines.toolkit_id = toolkits.id AND transfers.status = '" + models.TransferInTransit + "'), 0)"
+
+// takeStock keluarkan unit dari stok tersedia toolkit dan dari lokasi asalnya (nil = stok yang
+// belum ditempatkan), bersyarat (ErrInsufficientAvailable). status dipasang kalau stok tersedia habis.
+func takeStock(tx *gorm.DB, toolkitID int, locationID *int, quantity int, status string) error {
+	query := tx.Model(&models.Toolkit{}).Where("id = ? AND available >= ?", toolkitID, quantity)
+	if locationID != nil {
+		if err := reserveStock(tx, toolkitID, *locationID, quantity); err != nil {
+			return err
+		}
+	} else {
+		query = query.Where(unassignedAvailable+" >= ?", quantity)
+	}
+	result := query.Updates(map[string]interface{}{
+		"available": gorm.Expr("available - ?", quantity),
+		"status":    gorm.Expr("CASE WHEN available - ? = 0 THEN ? ELSE status END", quantity, status),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientAvailable
+	}
+	return nil
+}
+
+// checkoutStock takeStock untuk loan, unit yang keluar dari lokasi dicatat sebagai loan_checkout
+func checkoutStock(tx *gorm.DB, loanID, toolkitID int, locationID *int, quantity int) error {
+	if err := takeStock(tx, toolkitID, locationID, quantity, "borrowed"); err != nil {
+		return err
+	}
+	if locationID == nil {
+		return nil
+	}
+	return recordMovement(tx, &models.StockAdjustment{
+		ToolkitID:      toolkitID,
+		LocationID:     locationID,
+		QuantityChange: -quantity,
+		Reason:         models.MovementLoanCheckout,
+		ReferenceType:  "loan",
+		ReferenceID:    &loanID,
+	})
+}
+
+// restoreStock kebalikan takeStock: unit kembali tersedia di lokasi yang sama
+func restoreStock(tx *gorm.DB, toolkitID int, locationID *int, quantity int) error {
+	if err := tx.Model(&models.Toolkit{}).Where("id = ?", toolkitID).
+		Updates(map[string]interface{}{
+			"available": gorm.Expr("LEAST(available + ?, quantity)", quantity),
+			"status":    gorm.Expr("CASE WHEN status IN ('maintenance', 'borrowed') THEN 'available' ELSE status END"),
+		}).Error; err != nil {
+		return err
+	}
+	if locationID == nil {
+		return nil
+	}
+	return tx.Model(&models.ToolkitStock{}).
+		Where("toolkit_id = ? AND location_id = ?", toolkitID, *locationID).
+		Update("available", gorm.Expr("LEAST(available + ?, quantity)", quantity)).Error
+}
+
+// returnStock unit loan kembali tersedia, dari lokasi asal from ke lokasi pengembalian to
+func returnStock(tx *gorm.DB, loanID, toolkitID int, from, to *int, quantity int) error {
+	if err := tx.Model(&models.Toolkit{}).Where("id = ?", toolkitID).
+		Updates(map[string]interface{}{
+			"available": gorm.Expr("LEAST(available + ?, quantity)", quantity),
+			"status":    gorm.Expr("CASE WHEN status = 'borrowed' THEN 'available' ELSE status END"),
+		}).Error; err != nil {
+		return err
+	}
+	return releaseStock(tx, loanID, toolkitID, from, to, quantity)
+}
+
+// writeOffStock unit yang ditahan (tidak tersedia) di lokasi keluar dari stok lokasi itu
+func writeOffStock(tx *gorm.DB, adjustment *models.StockAdjustment, locationID int) error {
+	quantity := -adjustment.QuantityChange
+	result := tx.Model(&models.ToolkitStock{}).
+		Where("toolkit_id = ? AND location_id = ? AND quantity - available >= ?", adjustment.ToolkitID, locationID, quantity).
+		Update("quantity", gorm.Expr("quantity - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientAvailable
+	}
+	movement := *adjustment
+	movement.ID = 0
+	movement.LocationID = &locationID
+	return recordMovement(tx, &movement)
+}
+
+func reserveStock(tx *gorm.DB, toolkitID, locationID, quantity int) error {
+	result := tx.Model(&models.ToolkitStock{}).
+		Where("toolkit_id = ? AND location_id = ? AND available >= ?", toolkitID, locationID, quantity).
+		Update("available", gorm.Expr("available - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientAvailable
+	}
+	return nil
+}
+
+func releaseStock(tx *gorm.DB, loanID, toolkitID int, from, to *int, quantity int) error {
+	// Keluarnya unit dari lokasi asal sudah dicatat sebagai loan_checkout
+	if from != nil && to != nil && *from == *to {
+		if err := tx.Model(&models.ToolkitStock{}).
+			Where("toolkit_id = ? AND location_id = ?", toolkitID, *from).
+			Update("available", gorm.Expr("LEAST(available + ?, quantity)", quantity)).Error; err != nil {
+			return err
+		}
+	} else {
+		// Unit meninggalkan lokasi asal untuk selamanya
+		if from != nil {
+			if err := tx.Model(&models.ToolkitStock{}).
+				Where("toolkit_id = ? AND location_id = ?", toolkitID, *from).
+				Updates(map[string]interface{}{
+					"quantity":  gorm.Expr("GREATEST(quantity - ?, available)", quantity),
+					"available": gorm.Expr("LEAST(available, GREATEST(quantity - ?, available))", quantity),
+				}).Error; err != nil {
+				return err
+			}
+		}
+		if to == nil {
+			return nil
+		}
+		if err := addStock(tx, toolkitID, *to, quantity, quantity); err != nil {
+			return err
+		}
+	}
+	return recordMovement(tx, &models.StockAdjustment{
+		ToolkitID:      toolkitID,
+		LocationID:     to,
+		QuantityChange: quantity,
+		Reason:         models.MovementLoanReturn,
+		ReferenceType:  "loan",
+		ReferenceID:    &loanID,
+	})
+}
+
+// recordMovement catat perpindahan stok lokasi di riwayat stok (StockAdjustment)
+func recordMovement(tx *gorm.DB, movement *models.StockAdjustment) error {
+	return tx.Create(movement).Error
+}
+
+// addStock tambah quantity dan available di lokasi, baris dibuat kalau belum ada
+func addStock(tx *gorm.DB, toolkitID, locationID, quantity, available int) error {
+	stock := models.ToolkitStock{ToolkitID: toolkitID, LocationID: locationID, Quantity: quantity, Available: available}
+	return tx.Omit("Toolkit", "Location").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "toolkit_id"}, {Name: "location_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("toolkit_stocks.quantity + ?", quantity),
+			"available":  gorm.Expr("toolkit_stocks.available + ?", available),
+			"updated_at": gorm.Expr("NOW()"),
+		}),
+	}).Create(&stock).Error
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// ErrInsufficientAvailable stok tersedia tidak cukup saat update bersyarat
+var ErrInsufficientAvailable = errors.New("not enough available units")
+
+// ErrMaintenanceChanged status record sudah berubah sejak dibaca (start/close ganda)
+var ErrMaintenanceChanged = errors.New("maintenance record was changed concurrently")
+
+type MaintenanceRepository interface {
+	Create(ctx context.Context, record *models.MaintenanceRecord) (*models.MaintenanceRecord, error)
+	GetByID(ctx context.Context, id int) (*models.MaintenanceRecord, error)
+	GetAll(ctx context.Context, filter *models.MaintenanceFilterRequest) ([]*models.MaintenanceRecord, error)
+	// GetDue record scheduled dengan tanggal sampai until, urut tanggal
+	GetDue(ctx context.Context, until time.Time) ([]models.MaintenanceRecord, error)
+	// Start set in_progress dan keluarkan unit dari stok tersedia toolkit dan lokasi dalam satu transaksi.
+	// Record harus masih scheduled, kalau tidak ErrMaintenanceChanged.
+	Start(ctx context.Context, record *models.MaintenanceRecord) error
+	// Close simpan record completed/cancelled kalau statusnya masih prevStatus (ErrMaintenanceChanged).
+	// Record in_progress mengembalikan unit ke stok tersedia di lokasinya, condition (opsional)
+	// update kondisi toolkit, next (opsional) record jadwal berikutnya.
+	Close(ctx context.Context, record *models.MaintenanceRecord, prevStatus string, condition string, next *models.MaintenanceRecord) error
+
+	CreateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule, first *models.MaintenanceRecord) (*models.MaintenanceSchedule, error)
+	GetScheduleByID(ctx context.Context, id int) (*models.MaintenanceSchedule, error)
+	GetSchedulesByToolkit(ctx context.Context, toolkitID int) ([]*models.MaintenanceSchedule, error)
+	// UpdateSchedule simpan jadwal; pending dibuat kalau diisi (jadwal diaktifkan lagi)
+	UpdateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule, pending *models.MaintenanceRecord) (*models.MaintenanceSchedule, error)
+	// CancelPending batalkan record scheduled milik jadwal (yang in_progress dibiarkan)
+	CancelPending(ctx context.Context, scheduleID int) error
+	HasOpenRecord(ctx context.Context, scheduleID int) (bool, error)
+	DeleteSchedule(ctx context.Context, id int) error
+}
+
+type maintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
+}
+
+func (r *maintenanceRepository) Create(ctx context.Context, record *models.MaintenanceRecord) (*models.MaintenanceRecord, error) {
+	result := r.db.WithContext(ctx).Create(record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return record, nil
+}
+
+func (r *maintenanceRepository) GetByID(ctx context.Context, id int) (*models.MaintenanceRecord, error) {
+	var record models.MaintenanceRecord
+	result := r.db.WithContext(ctx).Preload("Toolkit").Preload("Technician").First(&record, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &record, nil
+}
+
+func (r *maintenanceRepository) GetAll(ctx context.Context, filter *models.MaintenanceFilterRequest) ([]*models.MaintenanceRecord, error) {
+	var records []*models.MaintenanceRecord
+
+	query := r.db.WithContext(ctx).Preload("Toolkit").Preload("Technician")
+	if filter.ToolkitID != 0 {
+		query = query.Where("toolkit_id = ?", filter.ToolkitID)
+	}
+	if filter.TechnicianID != 0 {
+		query = query.Where("technician_id = ?", filter.TechnicianID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	result := query.Order("scheduled_date DESC, id DESC").Find(&records)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return records, nil
+}
+
+func (r *maintenanceRepository) GetDue(ctx context.Context, until time.Time) ([]models.MaintenanceRecord, error) {
+	var records []models.MaintenanceRecord
+	result := r.db.WithContext(ctx).Preload("Toolkit").Preload("Technician").
+		Where("status = ? AND scheduled_date <= ?", models.MaintenanceScheduled, until).
+		Order("scheduled_date ASC, id ASC").
+		Find(&records)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return records, nil
+}
+
+func (r *maintenanceRepository) Start(ctx context.Context, record *models.MaintenanceRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Update bersyarat supaya tidak balapan dengan peminjaman yang mengurangi stok
+		if err := updateRecord(tx, record, models.MaintenanceScheduled); err != nil {
+			return err
+		}
+		return takeStock(tx, record.ToolkitID, record.LocationID, record.Quantity, "maintenance")
+	})
+}
+
+func (r *maintenanceRepository) Close(ctx context.Context, record *models.MaintenanceRecord, prevStatus string, condition string, next *models.MaintenanceRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateRecord(tx, record, prevStatus); err != nil {
+			return err
+		}
+
+		if prevStatus == models.MaintenanceInProgress {
+			if err := restoreStock(tx, record.ToolkitID, record.LocationID, record.Quantity); err != nil {
+				return err
+			}
+		}
+		if condition != "" {
+			if err := tx.Model(&models.Toolkit{}).Where("id = ?", record.ToolkitID).Update("condition", condition).Error; err != nil {
+				return err
+			}
+		}
+
+		if next != nil {
+			if err := tx.Omit("Toolkit", "Technician").Create(next).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.MaintenanceSchedule{}).
+				Where("id = ?", *next.ScheduleID).
+				Update("next_due_date", next.ScheduledDate).Error
+		}
+		return nil
+	})
+}
+
+// updateRecord simpan record hanya kalau statusnya di database masih prevStatus
+func updateRecord(tx *gorm.DB, record *models.MaintenanceRecord, prevStatus string) error {
+	result := tx.Model(record).Where("status = ?", prevStatus).
+		Select("*").Omit("Toolkit", "Technician").
+		Updates(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMaintenanceChanged
+	}
+	return nil
+}
+
+func (r *maintenanceRepository) CreateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule, first *models.MaintenanceRecord) (*models.MaintenanceSchedule, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Toolkit").Create(schedule).Error; err != nil {
+			return err
+		}
+		first.ScheduleID = &schedule.ID
+		return tx.Omit("Toolkit", "Technician").Create(first).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (r *maintenanceRepository) GetScheduleByID(ctx context.Context, id int) (*models.MaintenanceSchedule, error) {
+	var schedule models.MaintenanceSchedule
+	result := r.db.WithContext(ctx).First(&schedule, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &schedule, nil
+}
+
+func (r *maintenanceRepository) GetSchedulesByToolkit(ctx context.Context, toolkitID int) ([]*models.MaintenanceSchedule, error) {
+	var schedules []*models.MaintenanceSchedule
+	result := r.db.WithContext(ctx).Where("toolkit_id = ?", toolkitID).Order("next_due_date ASC").Find(&schedules)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return schedules, nil
+}
+
+func (r *maintenanceRepository) UpdateSchedule(ctx context.Context, schedule *models.MaintenanceSchedule, pending *models.MaintenanceRecord) (*models.MaintenanceSchedule, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Toolkit").Save(schedule).Error; err != nil {
+			return err
+		}
+		if pending != nil {
+			return tx.Omit("Toolkit", "Technician").Create(pending).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (r *maintenanceRepository) CancelPending(ctx context.Context, scheduleID int) error {
+	result := r.db.WithContext(ctx).Model(&models.MaintenanceRecord{}).
+		Where("schedule_id = ? AND status = ?", scheduleID, models.MaintenanceScheduled).
+		Update("status", models.MaintenanceCancelled)
+	return result.Error
+}
+
+func (r *maintenanceRepository) HasOpenRecord(ctx context.Context, scheduleID int) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.MaintenanceRecord{}).
+		Where("schedule_id = ? AND status IN ?", scheduleID,
+			[]string{models.MaintenanceScheduled, models.MaintenanceInProgress}).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// DeleteSchedule record riwayat tetap disimpan, hanya dilepas dari jadwal
+func (r *maintenanceRepository) DeleteSchedule(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MaintenanceRecord{}).
+			Where("schedule_id = ? AND status = ?", id, models.MaintenanceScheduled).
+			Update("status", models.MaintenanceCancelled).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.MaintenanceRecord{}).
+			Where("schedule_id = ?", id).
+			Update("schedule_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.MaintenanceSchedule{}, id).Error
+	})
+}
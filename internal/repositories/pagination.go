@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"toolkit-management/pkg/utils"
+)
+
+func orderClause(column string, desc bool) string {
+	if desc {
+		return column + " DESC, id DESC"
+	}
+	return column + " ASC, id ASC"
+}
+
+// decodeCursorFor decode cursor dan pastikan kolom dan urutannya sama dengan sort yang diminta
+func decodeCursorFor(req utils.CursorRequest) (*utils.Cursor, error) {
+	if req.Cursor == "" {
+		return nil, nil
+	}
+
+	cursor, err := utils.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if !cursor.Matches(req) {
+		return nil, utils.ErrInvalidCursor
+	}
+	return cursor, nil
+}
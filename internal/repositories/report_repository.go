@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+type ReportRepository interface {
+	// GetValuedToolkits toolkit yang sudah dibeli per asOf dan belum dihapus saat itu, dengan kategori
+	GetValuedToolkits(ctx context.Context, asOf time.Time, categoryID int) ([]models.Toolkit, error)
+	// GetAdjustmentsSince penyesuaian stok setelah since, urut waktu
+	GetAdjustmentsSince(ctx context.Context, since time.Time) ([]models.StockAdjustment, error)
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) GetValuedToolkits(ctx context.Context, asOf time.Time, categoryID int) ([]models.Toolkit, error) {
+	var toolkits []models.Toolkit
+
+	// Unscoped: toolkit yang dihapus setelah asOf masih dinilai
+	query := r.db.WithContext(ctx).Unscoped().Preload("Category").
+		Where("purchase_date IS NULL OR purchase_date <= ?", asOf).
+		Where("deleted_at IS NULL OR deleted_at > ?", asOf)
+	if categoryID != 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	result := query.Order("category_id ASC, name ASC").Find(&toolkits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return toolkits, nil
+}
+
+func (r *reportRepository) GetAdjustmentsSince(ctx context.Context, since time.Time) ([]models.StockAdjustment, error) {
+	var adjustments []models.StockAdjustment
+	// Perpindahan antar lokasi tidak mengubah jumlah unit
+	result := r.db.WithContext(ctx).Where("created_at > ? AND location_id IS NULL", since).
+		Order("created_at ASC").Find(&adjustments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/pkg/database"
+)
+
+// testDB transaksi di database yang ditunjuk TEST_DATABASE_DSN, di-rollback setelah test, mis.
+//
+//	TEST_DATABASE_DSN="host=localhost user=postgres dbname=toolkit_test sslmode=disable" \
+//		go test ./internal/repositories
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	if err := database.Migrate(tx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return tx
+}
+
+func valuedIDs(t *testing.T, repo ReportRepository, asOf time.Time, categoryID int) map[int]bool {
+	t.Helper()
+	toolkits, err := repo.GetValuedToolkits(context.Background(), asOf, categoryID)
+	if err != nil {
+		t.Fatalf("GetValuedToolkits: %v", err)
+	}
+	ids := make(map[int]bool, len(toolkits))
+	for _, toolkit := range toolkits {
+		ids[toolkit.ID] = true
+	}
+	return ids
+}
+
+func TestValuationIncludesToolkitDeletedAfterAsOf(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	category := models.Category{Name: "valuation-soft-delete"}
+	if err := db.Create(&category).Error; err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	purchased := time.Now().AddDate(-1, 0, 0)
+	toolkits := NewToolkitRepository(db)
+	toolkit, err := toolkits.Create(ctx, &models.Toolkit{
+		Name: "Bor listrik", SKU: "VAL-DEL-1", CategoryID: category.ID, Quantity: 2, Available: 2,
+		PurchaseDate: &purchased, PurchasePrice: 1500000,
+	})
+	if err != nil {
+		t.Fatalf("create toolkit: %v", err)
+	}
+
+	earlier := time.Now().Add(-time.Hour)
+	if err := toolkits.Delete(ctx, toolkit.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := toolkits.GetByID(ctx, toolkit.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetByID after delete: err = %v, want not found", err)
+	}
+	reports := NewReportRepository(db)
+	if !valuedIDs(t, reports, earlier, category.ID)[toolkit.ID] {
+		t.Error("report before the deletion leaves out the deleted toolkit")
+	}
+	if valuedIDs(t, reports, time.Now().Add(time.Hour), category.ID)[toolkit.ID] {
+		t.Error("report after the deletion still values the deleted toolkit")
+	}
+
+	// SKU toolkit yang dihapus boleh dipakai lagi
+	if _, err := toolkits.Create(ctx, &models.Toolkit{Name: "Bor baru", SKU: "VAL-DEL-1", CategoryID: category.ID, Quantity: 1, Available: 1}); err != nil {
+		t.Errorf("reuse SKU of a deleted toolkit: %v", err)
+	}
+}
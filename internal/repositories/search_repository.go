@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// Opsi ts_headline untuk snippet yang di-highlight
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+type SearchRepository interface {
+	SearchToolkits(ctx context.Context, query string, limit int) ([]models.SearchHit, error)
+	SearchCategories(ctx context.Context, query string, limit int) ([]models.SearchHit, error)
+	SearchUsers(ctx context.Context, query string, limit int) ([]models.SearchHit, error)
+	SearchLoans(ctx context.Context, query string, userID int, limit int) ([]models.SearchHit, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// SearchToolkits gabungan full-text (search_vector) dan trigram (search_text) supaya typo tetap ketemu
+func (r *searchRepository) SearchToolkits(ctx context.Context, query string, limit int) ([]models.SearchHit, error) {
+	var hits []models.SearchHit
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT id, 'toolkit' AS type, name AS title, sku AS subtitle,
+			ts_headline('simple', coalesce(name, '') || ' ' || coalesce(description, ''),
+				websearch_to_tsquery('simple', @q), @opts) AS snippet,
+			ts_rank(search_vector, websearch_to_tsquery('simple', @q)) + word_similarity(@q, search_text) AS rank
+		FROM toolkits
+		WHERE deleted_at IS NULL
+			AND (search_vector @@ websearch_to_tsquery('simple', @q) OR @q <% search_text)
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`,
+		map[string]interface{}{"q": query, "opts": headlineOptions, "limit": limit},
+	).Scan(&hits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return hits, nil
+}
+
+func (r *searchRepository) SearchCategories(ctx context.Context, query string, limit int) ([]models.SearchHit, error) {
+	var hits []models.SearchHit
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT id, 'category' AS type, name AS title,
+			ts_headline('simple', coalesce(description, ''), websearch_to_tsquery('simple', @q), @opts) AS snippet,
+			GREATEST(word_similarity(@q, name), word_similarity(@q, coalesce(description, ''))) AS rank
+		FROM categories
+		WHERE deleted_at IS NULL
+			AND (@q <% name OR @q <% coalesce(description, ''))
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`,
+		map[string]interface{}{"q": query, "opts": headlineOptions, "limit": limit},
+	).Scan(&hits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return hits, nil
+}
+
+func (r *searchRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.SearchHit, error) {
+	var hits []models.SearchHit
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT id, 'user' AS type, full_name AS title, username AS subtitle, email AS snippet,
+			GREATEST(word_similarity(@q, username), word_similarity(@q, full_name), word_similarity(@q, email)) AS rank
+		FROM users
+		WHERE deleted_at IS NULL
+			AND (@q <% username OR @q <% full_name OR @q <% email)
+		ORDER BY rank DESC, id ASC
+		LIMIT @limit`,
+		map[string]interface{}{"q": query, "limit": limit},
+	).Scan(&hits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return hits, nil
+}
+
+// SearchLoans, userID != 0 membatasi ke pinjaman milik user tersebut
+func (r *searchRepository) SearchLoans(ctx context.Context, query string, userID int, limit int) ([]models.SearchHit, error) {
+	var hits []models.SearchHit
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT loans.id, 'loan' AS type, toolkits.name AS title, users.full_name AS subtitle,
+			ts_headline('simple', coalesce(loans.purpose, ''), websearch_to_tsquery('simple', @q), @opts) AS snippet,
+			GREATEST(word_similarity(@q, loans.purpose), word_similarity(@q, toolkits.name),
+				word_similarity(@q, users.full_name)) AS rank
+		FROM loans
+		JOIN users ON loans.user_id = users.id
+		JOIN toolkits ON loans.toolkit_id = toolkits.id
+		WHERE loans.deleted_at IS NULL
+			AND (@user_id = 0 OR loans.user_id = @user_id)
+			AND (@q <% loans.purpose OR @q <% toolkits.name OR @q <% users.full_name)
+		ORDER BY rank DESC, loans.id DESC
+		LIMIT @limit`,
+		map[string]interface{}{"q": query, "opts": headlineOptions, "user_id": userID, "limit": limit},
+	).Scan(&hits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return hits, nil
+}
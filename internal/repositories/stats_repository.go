@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+type StatsRepository interface {
+	GetInventoryStats(ctx context.Context) (*models.InventoryStats, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+func (r *statsRepository) GetInventoryStats(ctx context.Context) (*models.InventoryStats, error) {
+	var stats models.InventoryStats
+	db := r.db.WithContext(ctx)
+
+	result := db.Model(&models.Loan{}).
+		Where("status != ?", "returned").
+		Count(&stats.ActiveLoans)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = db.Model(&models.Loan{}).
+		Where("status != ? AND due_date < ?", "returned", time.Now()).
+		Count(&stats.OverdueLoans)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = db.Model(&models.Toolkit{}).
+		Where("available <= 0 AND status != ?", "retired").
+		Count(&stats.ToolkitsOutOfStock)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = db.Model(&models.Toolkit{}).
+		Select("categories.id AS category_id, categories.name AS category_name, COALESCE(SUM(toolkits.available), 0) AS available").
+		Joins("JOIN categories ON categories.id = toolkits.category_id").
+		Group("categories.id, categories.name").
+		Scan(&stats.AvailableByCategory)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &stats, nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 
 	"toolkit-management/internal/models"
@@ -8,11 +10,51 @@ import (
 )
 
 type ToolkitRepository interface {
-	Create(toolkit *models.Toolkit) (*models.Toolkit, error)
-	GetByID(id int) (*models.Toolkit, error)
-	GetAll(filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error)
-	Update(toolkit *models.Toolkit) (*models.Toolkit, error)
-	Delete(id int) error
+	Create(ctx context.Context, toolkit *models.Toolkit) (*models.Toolkit, error)
+	GetByID(ctx context.Context, id int) (*models.Toolkit, error)
+	// GetByIDs toolkit beserta kategori, urutan hasil tidak dijamin
+	GetByIDs(ctx context.Context, ids []int) ([]models.Toolkit, error)
+	// GetByCode toolkit dengan SKU (case-insensitive) atau serial number sama dengan code, maksimal 2
+	GetByCode(ctx context.Context, code string) ([]models.Toolkit, error)
+	GetAll(ctx context.Context, filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error)
+	Update(ctx context.Context, toolkit *models.Toolkit) (*models.Toolkit, error)
+	// AdjustStock ubah Quantity dan Available sebesar adjustment.QuantityChange dan catat
+	// adjustment-nya dalam satu transaksi. Pengurangan hanya dari unit yang belum ditempatkan
+	// di lokasi dan masih tersedia, kalau tidak ErrInsufficientAvailable.
+	AdjustStock(ctx context.Context, adjustment *models.StockAdjustment) (*models.Toolkit, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// Kolom yang boleh dipakai untuk sort / keyset (sort_by -> nama kolom)
+var toolkitSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"sku":        "sku",
+	"quantity":   "quantity",
+	"available":  "available",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func toolkitSortKey(column string) func(models.Toolkit) (any, int) {
+	return func(t models.Toolkit) (any, int) {
+		switch column {
+		case "name":
+			return t.Name, t.ID
+		case "sku":
+			return t.SKU, t.ID
+		case "quantity":
+			return t.Quantity, t.ID
+		case "available":
+			return t.Available, t.ID
+		case "created_at":
+			return t.CreatedAt, t.ID
+		case "updated_at":
+			return t.UpdatedAt, t.ID
+		default:
+			return t.ID, t.ID
+		}
+	}
 }
 
 type toolkitRepository struct {
@@ -23,33 +65,54 @@ func NewToolkitRepository(db *gorm.DB) ToolkitRepository {
 	return &toolkitRepository{db: db}
 }
 
-func (r *toolkitRepository) Create(toolkit *models.Toolkit) (*models.Toolkit, error) {
-	result := r.db.Create(toolkit)
+func (r *toolkitRepository) Create(ctx context.Context, toolkit *models.Toolkit) (*models.Toolkit, error) {
+	result := r.db.WithContext(ctx).Create(toolkit)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return toolkit, nil
 }
 
-func (r *toolkitRepository) GetByID(id int) (*models.Toolkit, error) {
+func (r *toolkitRepository) GetByID(ctx context.Context, id int) (*models.Toolkit, error) {
 	var toolkit models.Toolkit
-	result := r.db.First(&toolkit, id)
+	result := r.db.WithContext(ctx).First(&toolkit, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &toolkit, nil
 }
 
-func (r *toolkitRepository) GetAll(filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error) {
+func (r *toolkitRepository) GetByIDs(ctx context.Context, ids []int) ([]models.Toolkit, error) {
+	var toolkits []models.Toolkit
+	result := r.db.WithContext(ctx).Preload("Category").Where("id IN ?", ids).Find(&toolkits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return toolkits, nil
+}
+
+func (r *toolkitRepository) GetByCode(ctx context.Context, code string) ([]models.Toolkit, error) {
+	var toolkits []models.Toolkit
+	result := r.db.WithContext(ctx).Preload("Category").
+		Where("LOWER(sku) = LOWER(?) OR serial_number = ?", code, code).
+		Order("id ASC").Limit(2).Find(&toolkits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return toolkits, nil
+}
+
+func (r *toolkitRepository) GetAll(ctx context.Context, filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error) {
 	var toolkits []models.Toolkit
 	var totalItems int64
 
 	// Build query with filters
-	query := r.db.Model(&models.Toolkit{})
+	query := r.db.WithContext(ctx).Model(&models.Toolkit{})
 
 	if filter.SearchTerm != "" {
-		query = query.Where("name ILIKE ? OR sku ILIKE ? OR description ILIKE ?",
-			"%"+filter.SearchTerm+"%", "%"+filter.SearchTerm+"%", "%"+filter.SearchTerm+"%")
+		// Full-text (search_vector) + trigram (search_text), lihat database.migrateSearch
+		query = query.Where("search_vector @@ websearch_to_tsquery('simple', ?) OR ? <% search_text",
+			filter.SearchTerm, filter.SearchTerm)
 	}
 
 	if filter.CategoryID != 0 {
@@ -76,6 +139,18 @@ func (r *toolkitRepository) GetAll(filter *models.ToolkitFilterRequest) (*models
 		query = query.Where("quantity <= ?", filter.MaxQuantity)
 	}
 
+	if filter.LocationID != 0 {
+		// Toolkit yang punya stok di lokasi ini atau sub-lokasinya
+		query = query.Where("id IN (?)", r.db.Model(&models.ToolkitStock{}).Select("toolkit_id").
+			Where("quantity > 0 AND location_id IN (?)", locationSubtree(r.db, filter.LocationID)))
+	}
+
+	column, desc := utils.ResolveSort(filter.SortBy, filter.SortOrder, toolkitSortColumns, "id")
+
+	if utils.IsCursorMode(filter.PaginationMode, filter.Cursor) {
+		return r.getAllByCursor(query, filter, column, desc)
+	}
+
 	// Get total count
 	countResult := query.Count(&totalItems)
 	if countResult.Error != nil {
@@ -84,6 +159,7 @@ func (r *toolkitRepository) GetAll(filter *models.ToolkitFilterRequest) (*models
 
 	// Apply pagination using GORM scope
 	result := query.Scopes(utils.Paginate(filter.Page, filter.PageSize)).
+		Order(orderClause(column, desc)).
 		Preload("Category").
 		Find(&toolkits)
 
@@ -96,19 +172,98 @@ func (r *toolkitRepository) GetAll(filter *models.ToolkitFilterRequest) (*models
 
 	return &models.ToolkitListResponse{
 		Data:       toolkits,
-		Pagination: paginationResponse,
+		Pagination: &paginationResponse,
 	}, nil
 }
 
-func (r *toolkitRepository) Update(toolkit *models.Toolkit) (*models.Toolkit, error) {
-	result := r.db.Save(toolkit)
+func (r *toolkitRepository) getAllByCursor(query *gorm.DB, filter *models.ToolkitFilterRequest, column string, desc bool) (*models.ToolkitListResponse, error) {
+	var toolkits []models.Toolkit
+
+	req := utils.CursorRequest{Cursor: filter.Cursor, PageSize: filter.PageSize, Column: column, Desc: desc}
+	cursor, err := decodeCursorFor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count opsional, mahal di tabel besar
+	var totalItems *int64
+	if filter.IncludeTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		totalItems = &total
+	}
+
+	result := query.Scopes(utils.KeysetPaginate(req, cursor)).
+		Preload("Category").
+		Find(&toolkits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	toolkits, page := utils.BuildCursorPage(toolkits, req, cursor, toolkitSortKey(column))
+	page.TotalItems = totalItems
+
+	return &models.ToolkitListResponse{
+		Data:             toolkits,
+		CursorPagination: &page,
+	}, nil
+}
+
+func (r *toolkitRepository) Update(ctx context.Context, toolkit *models.Toolkit) (*models.Toolkit, error) {
+	// Stok hanya berubah lewat update bersyarat (AdjustStock, loan, transfer), foto utama lewat attachment
+	result := r.db.WithContext(ctx).Omit("Quantity", "Available", "PrimaryImageID").Save(toolkit)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return toolkit, nil
 }
 
-func (r *toolkitRepository) Delete(id int) error {
-	result := r.db.Delete(&models.Toolkit{}, id)
-	return result.Error
+func (r *toolkitRepository) AdjustStock(ctx context.Context, adjustment *models.StockAdjustment) (*models.Toolkit, error) {
+	var toolkit models.Toolkit
+	change := adjustment.QuantityChange
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bersyarat supaya checkout yang bersamaan tidak tertimpa dan stok lokasi tetap <= total
+		query := tx.Model(&models.Toolkit{}).Where("id = ? AND available + ? >= 0", adjustment.ToolkitID, change)
+		if change < 0 {
+			query = query.Where(unassignedQuantity+" + ? >= 0", change).
+				Where(unassignedAvailable+" + ? >= 0", change)
+		}
+		result := query.Updates(map[string]interface{}{
+			"quantity":  gorm.Expr("quantity + ?", change),
+			"available": gorm.Expr("available + ?", change),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if err := tx.First(&toolkit, adjustment.ToolkitID).Error; err != nil {
+				return err
+			}
+			return ErrInsufficientAvailable
+		}
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		return tx.First(&toolkit, adjustment.ToolkitID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &toolkit, nil
+}
+
+// Delete soft delete. Baris yang dulu ikut terhapus lewat cascade dihapus di transaksi yang sama,
+// riwayat (loan, maintenance, stock adjustment) tetap.
+func (r *toolkitRepository) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := []interface{}{&models.ToolkitStock{}, &models.Attachment{}, &models.BundleComponent{}, &models.MaintenanceSchedule{}}
+		for _, model := range owned {
+			if err := tx.Where("toolkit_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Toolkit{}, id).Error
+	})
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"toolkit-management/internal/models"
+)
+
+func TestAdjustStockKeepsPlacedStock(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	category := models.Category{Name: "adjust-stock"}
+	if err := db.Create(&category).Error; err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	location := models.Location{Name: "Gudang", Code: "ADJ-WH", Type: models.LocationWarehouse}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("create location: %v", err)
+	}
+	toolkits := NewToolkitRepository(db)
+	toolkit, err := toolkits.Create(ctx, &models.Toolkit{Name: "Kunci torsi", SKU: "ADJ-1", CategoryID: category.ID, Quantity: 5, Available: 5})
+	if err != nil {
+		t.Fatalf("create toolkit: %v", err)
+	}
+	if _, err := NewLocationRepository(db).SetStock(ctx, toolkit.ID, location.ID, 3, 1); err != nil {
+		t.Fatalf("SetStock: %v", err)
+	}
+
+	// 3 dari 5 unit ada di gudang, hanya 2 yang boleh dikurangi
+	_, err = toolkits.AdjustStock(ctx, &models.StockAdjustment{ToolkitID: toolkit.ID, QuantityChange: -3, Reason: models.AdjustmentWriteOff})
+	if !errors.Is(err, ErrInsufficientAvailable) {
+		t.Fatalf("removing placed units: err = %v, want ErrInsufficientAvailable", err)
+	}
+	adjusted, err := toolkits.AdjustStock(ctx, &models.StockAdjustment{ToolkitID: toolkit.ID, QuantityChange: -2, Reason: models.AdjustmentWriteOff})
+	if err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if adjusted.Quantity != 3 || adjusted.Available != 3 {
+		t.Errorf("toolkit = %d/%d, want 3/3", adjusted.Quantity, adjusted.Available)
+	}
+
+	var adjustments []models.StockAdjustment
+	if err := db.Where("toolkit_id = ? AND location_id IS NULL", toolkit.ID).Find(&adjustments).Error; err != nil {
+		t.Fatalf("find adjustments: %v", err)
+	}
+	if len(adjustments) != 1 || adjustments[0].QuantityChange != -2 {
+		t.Errorf("adjustments = %+v, want one write-off of 2 units", adjustments)
+	}
+
+	// Update biasa tidak boleh menimpa stok
+	adjusted.Quantity, adjusted.Available = 99, 99
+	if _, err := toolkits.Update(ctx, adjusted); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	reloaded, err := toolkits.GetByID(ctx, toolkit.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if reloaded.Quantity != 3 || reloaded.Available != 3 {
+		t.Errorf("after Update = %d/%d, want stock unchanged at 3/3", reloaded.Quantity, reloaded.Available)
+	}
+}
@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// ErrTransferChanged status transfer sudah diubah request lain sejak dibaca
+var ErrTransferChanged = errors.New("transfer was changed concurrently")
+
+// TransferReceipt jumlah yang benar-benar diterima untuk satu line
+type TransferReceipt struct {
+	Line     *models.TransferLine
+	Received int
+	Notes    string
+}
+
+type TransferRepository interface {
+	Create(ctx context.Context, transfer *models.Transfer) (*models.Transfer, error)
+	// GetByID transfer beserta lokasi dan line-nya
+	GetByID(ctx context.Context, id int) (*models.Transfer, error)
+	GetAll(ctx context.Context, filter *models.TransferFilterRequest) ([]*models.Transfer, error)
+	// Update simpan draft; lines non-nil menggantikan seluruh line lama
+	Update(ctx context.Context, transfer *models.Transfer, lines []models.TransferLine) (*models.Transfer, error)
+	Delete(ctx context.Context, id int) error
+
+	// Dispatch draft → in_transit: stok tiap line keluar dari lokasi asal dan dari Toolkit.Available
+	// dalam satu transaksi. Satu line kurang = semuanya batal (ErrInsufficientAvailable).
+	Dispatch(ctx context.Context, transfer *models.Transfer, actorID int) error
+	// Receive in_transit → received: unit yang diterima masuk ke lokasi tujuan, unit yang
+	// tidak sampai mengurangi Toolkit.Quantity (TransferShortage).
+	Receive(ctx context.Context, transfer *models.Transfer, receipts []TransferReceipt, actorID int) error
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(ctx context.Context, transfer *models.Transfer) (*models.Transfer, error) {
+	result := r.db.WithContext(ctx).Omit("Source", "Destination", "Lines.Toolkit").Create(transfer)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transfer, nil
+}
+
+func (r *transferRepository) GetByID(ctx context.Context, id int) (*models.Transfer, error) {
+	var transfer models.Transfer
+	result := r.db.WithContext(ctx).
+		Preload("Source").
+		Preload("Destination").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Lines.Toolkit").
+		First(&transfer, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) GetAll(ctx context.Context, filter *models.TransferFilterRequest) ([]*models.Transfer, error) {
+	var transfers []*models.Transfer
+
+	query := r.db.WithContext(ctx).
+		Preload("Source").
+		Preload("Destination").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Lines.Toolkit")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.LocationID != 0 {
+		query = query.Where("source_location_id = ? OR destination_location_id = ?", filter.LocationID, filter.LocationID)
+	}
+	if filter.ToolkitID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM transfer_lines WHERE transfer_lines.transfer_id = transfers.id AND transfer_lines.toolkit_id = ?)", filter.ToolkitID)
+	}
+
+	result := query.Order("created_at DESC").Find(&transfers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transfers, nil
+}
+
+func (r *transferRepository) Update(ctx context.Context, transfer *models.Transfer, lines []models.TransferLine) (*models.Transfer, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(transfer).Where("status = ?", models.TransferDraft).
+			Updates(map[string]interface{}{
+				"source_location_id":      transfer.SourceLocationID,
+				"destination_location_id": transfer.DestinationLocationID,
+				"notes":                   transfer.Notes,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferChanged
+		}
+		if lines == nil {
+			return nil
+		}
+		if err := tx.Where("transfer_id = ?", transfer.ID).Delete(&models.TransferLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].TransferID = transfer.ID
+		}
+		return tx.Omit("Toolkit").Create(&lines).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (r *transferRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("status = ?", models.TransferDraft).Delete(&models.Transfer{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTransferChanged
+	}
+	return nil
+}
+
+func (r *transferRepository) Dispatch(ctx context.Context, transfer *models.Transfer, actorID int) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Transfer{}).
+			Where("id = ? AND status = ?", transfer.ID, models.TransferDraft).
+			Updates(map[string]interface{}{
+				"status":        models.TransferInTransit,
+				"dispatched_by": actorID,
+				"dispatched_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferChanged
+		}
+
+		for _, line := range transfer.Lines {
+			// Hanya unit yang tersedia di rak yang bisa dikirim
+			result := tx.Model(&models.ToolkitStock{}).
+				Where("toolkit_id = ? AND location_id = ? AND available >= ?", line.ToolkitID, transfer.SourceLocationID, line.Quantity).
+				Updates(map[string]interface{}{
+					"quantity":   gorm.Expr("quantity - ?", line.Quantity),
+					"available":  gorm.Expr("available - ?", line.Quantity),
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("toolkit %d: %w", line.ToolkitID, ErrInsufficientAvailable)
+			}
+
+			result = tx.Model(&models.Toolkit{}).
+				Where("id = ? AND available >= ?", line.ToolkitID, line.Quantity).
+				Update("available", gorm.Expr("available - ?", line.Quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("toolkit %d: %w", line.ToolkitID, ErrInsufficientAvailable)
+			}
+
+			if err := recordMovement(tx, &models.StockAdjustment{
+				ToolkitID:      line.ToolkitID,
+				LocationID:     &transfer.SourceLocationID,
+				QuantityChange: -line.Quantity,
+				Reason:         models.MovementTransferOut,
+				ReferenceType:  "transfer",
+				ReferenceID:    &transfer.ID,
+				CreatedBy:      &actorID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *transferRepository) Receive(ctx context.Context, transfer *models.Transfer, receipts []TransferReceipt, actorID int) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"status":      models.TransferReceived,
+			"received_by": actorID,
+			"received_at": now,
+		}
+		if transfer.Notes != "" {
+			updates["notes"] = transfer.Notes
+		}
+		result := tx.Model(&models.Transfer{}).
+			Where("id = ? AND status = ?", transfer.ID, models.TransferInTransit).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferChanged
+		}
+
+		for _, receipt := range receipts {
+			line := receipt.Line
+			if err := tx.Model(line).Updates(map[string]interface{}{
+				"received_quantity": receipt.Received,
+				"discrepancy_notes": receipt.Notes,
+			}).Error; err != nil {
+				return err
+			}
+
+			if receipt.Received > 0 {
+				if err := addStock(tx, line.ToolkitID, transfer.DestinationLocationID, receipt.Received, receipt.Received); err != nil {
+					return err
+				}
+				if err := tx.Model(&models.Toolkit{}).Where("id = ?", line.ToolkitID).
+					Update("available", gorm.Expr("LEAST(available + ?, quantity)", receipt.Received)).Error; err != nil {
+					return err
+				}
+				if err := recordMovement(tx, &models.StockAdjustment{
+					ToolkitID:      line.ToolkitID,
+					LocationID:     &transfer.DestinationLocationID,
+					QuantityChange: receipt.Received,
+					Reason:         models.MovementTransferIn,
+					ReferenceType:  "transfer",
+					ReferenceID:    &transfer.ID,
+					CreatedBy:      &actorID,
+				}); err != nil {
+					return err
+				}
+			}
+
+			// Unit yang tidak sampai keluar dari total stok
+			if shortage := line.Quantity - receipt.Received; shortage > 0 {
+				if err := tx.Model(&models.Toolkit{}).Where("id = ?", line.ToolkitID).
+					Update("quantity", gorm.Expr("GREATEST(quantity - ?, available)", shortage)).Error; err != nil {
+					return err
+				}
+				if err := tx.Create(&models.StockAdjustment{
+					ToolkitID:      line.ToolkitID,
+					QuantityChange: -shortage,
+					Reason:         models.TransferShortage,
+					ReferenceType:  "transfer",
+					ReferenceID:    &transfer.ID,
+					Notes:          receipt.Notes,
+					CreatedBy:      &actorID,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"toolkit-management/internal/models"
+)
+
+type TwoFactorRepository interface {
+	GetByUserID(ctx context.Context, userID int) (*models.TwoFactor, error)
+	// Save buat atau timpa enrolment (setup ulang sebelum dikonfirmasi)
+	Save(ctx context.Context, twoFactor *models.TwoFactor) error
+	// Enable aktifkan enrolment dan ganti seluruh recovery code dalam satu transaksi
+	Enable(ctx context.Context, userID int, at time.Time, step int64, codeHashes []string) error
+	Delete(ctx context.Context, userID int) error
+	// ConsumeStep false kalau periode TOTP ini (atau yang lebih baru) sudah dipakai
+	ConsumeStep(ctx context.Context, userID int, step int64) (bool, error)
+	// UseRecoveryCode false kalau kode tidak ada atau sudah dipakai
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string, at time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int) (int64, error)
+	// StartChallenge challenge login baru menggantikan yang lama, hitungan gagal direset
+	StartChallenge(ctx context.Context, userID int, challengeID string) error
+	// FailChallenge catat kode salah; setelah maxAttempts challenge dibatalkan (false)
+	FailChallenge(ctx context.Context, userID int, challengeID string, maxAttempts int) (bool, error)
+	// EndChallenge challenge sekali pakai, false kalau sudah tidak berlaku
+	EndChallenge(ctx context.Context, userID int, challengeID string) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) GetByUserID(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactor)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(ctx context.Context, twoFactor *models.TwoFactor) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled_at", "last_used_step", "updated_at"}),
+	}).Create(twoFactor)
+	return result.Error
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID int, at time.Time, step int64, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": at, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, userID int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// ConsumeStep update bersyarat supaya dua request paralel dengan kode sama tidak sama-sama lolos
+func (r *twoFactorRepository) ConsumeStep(ctx context.Context, userID int, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		UpdateColumn("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count)
+	return count, result.Error
+}
+
+func (r *twoFactorRepository) StartChallenge(ctx context.Context, userID int, challengeID string) error {
+	return r.db.WithContext(ctx).Model(&models.TwoFactor{}).
+		Where("user_id = ?", userID).
+		UpdateColumns(map[string]interface{}{"challenge_id": challengeID, "failed_attempts": 0}).Error
+}
+
+// FailChallenge update bersyarat supaya tebakan paralel tetap terhitung semua
+func (r *twoFactorRepository) FailChallenge(ctx context.Context, userID int, challengeID string, maxAttempts int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TwoFactor{}).
+		Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		UpdateColumns(map[string]interface{}{
+			"failed_attempts": gorm.Expr("failed_attempts + 1"),
+			"challenge_id":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN '' ELSE challenge_id END", maxAttempts),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	var twoFactor models.TwoFactor
+	if err := r.db.WithContext(ctx).Select("challenge_id").Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return false, err
+	}
+	return twoFactor.ChallengeID == challengeID, nil
+}
+
+func (r *twoFactorRepository) EndChallenge(ctx context.Context, userID int, challengeID string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TwoFactor{}).
+		Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		UpdateColumns(map[string]interface{}{"challenge_id": "", "failed_attempts": 0})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 
 	"toolkit-management/internal/models"
@@ -8,12 +10,38 @@ import (
 )
 
 type UserRepository interface {
-	Create(user *models.User) (*models.User, error)
-	GetByID(id int) (*models.User, error)
-	GetByUsername(username string) (*models.User, error)
-	GetAll(filter *models.UserFilterRequest) (*models.UserListResponse, error)
-	Update(user *models.User) (*models.User, error)
-	Delete(id int) error
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByExternalID(ctx context.Context, externalID string) (*models.User, error)
+	GetByBadgeNumber(ctx context.Context, badge string) (*models.User, error)
+	GetAll(ctx context.Context, filter *models.UserFilterRequest) (*models.UserListResponse, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// Kolom yang boleh dipakai untuk sort / keyset (sort_by -> nama kolom)
+var userSortColumns = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"full_name":  "full_name",
+	"created_at": "created_at",
+}
+
+func userSortKey(column string) func(models.User) (any, int) {
+	return func(u models.User) (any, int) {
+		switch column {
+		case "username":
+			return u.Username, u.ID
+		case "full_name":
+			return u.FullName, u.ID
+		case "created_at":
+			return u.CreatedAt, u.ID
+		default:
+			return u.ID, u.ID
+		}
+	}
 }
 
 type userRepository struct {
@@ -24,38 +52,65 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *models.User) (*models.User, error) {
-	result := r.db.Create(user)
+func (r *userRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	result := r.db.WithContext(ctx).Create(user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return user, nil
 }
 
-func (r *userRepository) GetByID(id int) (*models.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	var user models.User
-	result := r.db.First(&user, id)
+	result := r.db.WithContext(ctx).First(&user, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByUsername(username string) (*models.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("username = ?", username).First(&user)
+	result := r.db.WithContext(ctx).Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetAll(filter *models.UserFilterRequest) (*models.UserListResponse, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Where("LOWER(email) = LOWER(?)", email).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByBadgeNumber(ctx context.Context, badge string) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Where("badge_number = ?", badge).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByExternalID(ctx context.Context, externalID string) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Where("external_id = ?", externalID).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context, filter *models.UserFilterRequest) (*models.UserListResponse, error) {
 	var users []models.User
 	var totalItems int64
 
 	// Build query with filters
-	query := r.db.Model(&models.User{})
+	query := r.db.WithContext(ctx).Model(&models.User{})
 
 	if filter.SearchTerm != "" {
 		query = query.Where("username ILIKE ? OR email ILIKE ? OR full_name ILIKE ?",
@@ -74,6 +129,12 @@ func (r *userRepository) GetAll(filter *models.UserFilterRequest) (*models.UserL
 		query = query.Where("is_active = ?", *filter.IsActive)
 	}
 
+	column, desc := utils.ResolveSort(filter.SortBy, filter.SortOrder, userSortColumns, "id")
+
+	if utils.IsCursorMode(filter.PaginationMode, filter.Cursor) {
+		return r.getAllByCursor(query, filter, column, desc)
+	}
+
 	// Get total count
 	countResult := query.Count(&totalItems)
 	if countResult.Error != nil {
@@ -82,6 +143,7 @@ func (r *userRepository) GetAll(filter *models.UserFilterRequest) (*models.UserL
 
 	// Apply pagination using GORM scope
 	result := query.Scopes(utils.Paginate(filter.Page, filter.PageSize)).
+		Order(orderClause(column, desc)).
 		Find(&users)
 
 	if result.Error != nil {
@@ -93,19 +155,52 @@ func (r *userRepository) GetAll(filter *models.UserFilterRequest) (*models.UserL
 
 	return &models.UserListResponse{
 		Data:       users,
-		Pagination: paginationResponse,
+		Pagination: &paginationResponse,
+	}, nil
+}
+
+func (r *userRepository) getAllByCursor(query *gorm.DB, filter *models.UserFilterRequest, column string, desc bool) (*models.UserListResponse, error) {
+	var users []models.User
+
+	req := utils.CursorRequest{Cursor: filter.Cursor, PageSize: filter.PageSize, Column: column, Desc: desc}
+	cursor, err := decodeCursorFor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count opsional, mahal di tabel besar
+	var totalItems *int64
+	if filter.IncludeTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		totalItems = &total
+	}
+
+	result := query.Scopes(utils.KeysetPaginate(req, cursor)).Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	users, page := utils.BuildCursorPage(users, req, cursor, userSortKey(column))
+	page.TotalItems = totalItems
+
+	return &models.UserListResponse{
+		Data:             users,
+		CursorPagination: &page,
 	}, nil
 }
 
-func (r *userRepository) Update(user *models.User) (*models.User, error) {
-	result := r.db.Save(user)
+func (r *userRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	result := r.db.WithContext(ctx).Save(user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return user, nil
 }
 
-func (r *userRepository) Delete(id int) error {
-	result := r.db.Delete(&models.User{}, id)
+func (r *userRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.User{}, id)
 	return result.Error
 }
\ No newline at end of file
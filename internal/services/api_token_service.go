@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+// lastUsedInterval batas seberapa sering last_used_at ditulis ke DB per token
+const lastUsedInterval = time.Minute
+
+type APITokenService interface {
+	Create(ctx context.Context, owner *auth.JWTClaim, req *models.APITokenCreateRequest) (*models.APITokenCreateResponse, error)
+	List(ctx context.Context, userID int) ([]*models.APIToken, error)
+	Revoke(ctx context.Context, id int, actor *auth.JWTClaim) error
+	auth.APITokenAuthenticator
+}
+
+type apiTokenService struct {
+	repo       repositories.APITokenRepository
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewAPITokenService(repo repositories.APITokenRepository, defaultTTL, maxTTL time.Duration) APITokenService {
+	return &apiTokenService{repo: repo, defaultTTL: defaultTTL, maxTTL: maxTTL}
+}
+
+func (s *apiTokenService) Create(ctx context.Context, owner *auth.JWTClaim, req *models.APITokenCreateRequest) (*models.APITokenCreateResponse, error) {
+	// Token hanya bisa dibuat dari sesi login, supaya token bocor tidak bisa memperbanyak diri
+	if owner.IsAPIToken() {
+		return nil, NewForbiddenError("API tokens cannot be used to create API tokens")
+	}
+	if slices.Contains(req.Scopes, models.ScopeAdmin) && owner.Role != "admin" {
+		return nil, NewValidationError("admin scope requires an admin account",
+			FieldError{Field: "scopes", Message: "admin scope not allowed for this user"})
+	}
+
+	ttl := s.defaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > s.maxTTL {
+		maxDays := int(s.maxTTL.Hours() / 24)
+		return nil, NewValidationError("token lifetime too long",
+			FieldError{Field: "expires_in_days", Message: fmt.Sprintf("must be at most %d", maxDays)})
+	}
+
+	plaintext, err := generateAPIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	token := &models.APIToken{
+		UserID:    owner.UserID,
+		Name:      req.Name,
+		Prefix:    plaintext[:len(auth.APITokenPrefix)+8],
+		TokenHash: hashAPIToken(plaintext),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	created, err := s.repo.Create(ctx, token)
+	if err != nil {
+		return nil, translateError(err, "api token")
+	}
+	return &models.APITokenCreateResponse{APIToken: *created, Token: plaintext}, nil
+}
+
+func (s *apiTokenService) List(ctx context.Context, userID int) ([]*models.APIToken, error) {
+	tokens, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, translateError(err, "api token")
+	}
+	return tokens, nil
+}
+
+// Revoke oleh pemilik token atau admin. Token milik user lain dianggap tidak ada.
+func (s *apiTokenService) Revoke(ctx context.Context, id int, actor *auth.JWTClaim) error {
+	token, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return translateError(err, "api token")
+	}
+	if token.UserID != actor.UserID && actor.Role != "admin" {
+		return NewNotFoundError("api token")
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+	return translateError(s.repo.Revoke(ctx, id, time.Now()), "api token")
+}
+
+// AuthenticateAPIToken dipanggil RequireAuth untuk header "Bearer tkm_..."
+func (s *apiTokenService) AuthenticateAPIToken(ctx context.Context, plaintext, clientIP string) (*auth.JWTClaim, error) {
+	token, err := s.repo.GetByHash(ctx, hashAPIToken(plaintext))
+	if err != nil {
+		return nil, translateError(err, "api token")
+	}
+
+	now := time.Now()
+	switch {
+	case token.RevokedAt != nil:
+		return nil, NewUnauthorizedError("api token revoked")
+	case !now.Before(token.ExpiresAt):
+		return nil, NewUnauthorizedError("api token expired")
+	case !token.User.IsActive:
+		return nil, NewUnauthorizedError("user is inactive")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval || token.LastUsedIP != clientIP {
+		if err := s.repo.TouchLastUsed(ctx, token.ID, now, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tanpa scope admin, token admin diperlakukan seperti user biasa
+	role := token.User.Role
+	if role == "admin" && !token.HasScope(models.ScopeAdmin) {
+		role = "user"
+	}
+
+	return &auth.JWTClaim{
+		UserID:   token.UserID,
+		Username: token.User.Username,
+		Role:     role,
+		TokenID:  token.ID,
+		Scopes:   token.Scopes,
+	}, nil
+}
+
+func generateAPIToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return auth.APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAPIToken SHA-256 cukup karena token acak 256-bit (bukan password), dan lookup harus cepat
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/storage"
+)
+
+// maxImagePixels tolak gambar yang kalau di-decode makan memori berlebihan (decompression bomb)
+const maxImagePixels = 40_000_000
+
+// Content type hasil sniffing yang diterima per jenis upload
+var (
+	photoContentTypes    = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+	documentContentTypes = []string{"application/pdf", "image/jpeg", "image/png", "image/webp"}
+)
+
+var contentTypeExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentPolicy batas ukuran per jenis upload (byte) dan sisi terpanjang thumbnail (pixel)
+type AttachmentPolicy struct {
+	MaxPhotoSize    int64
+	MaxDocumentSize int64
+	ThumbnailSize   int
+}
+
+type AttachmentService interface {
+	Upload(ctx context.Context, toolkitID, actorID int, req *models.AttachmentUploadRequest) (*models.Attachment, error)
+	GetByID(ctx context.Context, id int) (*models.Attachment, error)
+	GetByToolkit(ctx context.Context, toolkitID int) ([]models.Attachment, error)
+	// Open isi file (atau thumbnail-nya), caller wajib menutup reader
+	Open(ctx context.Context, id int, thumbnail bool) (*models.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, id int) error
+	// RemoveFiles hapus object storage milik attachment yang barisnya sudah terhapus
+	RemoveFiles(ctx context.Context, attachments []models.Attachment)
+	// MaxUploadSize ukuran file terbesar yang mungkin diterima, untuk membatasi body request
+	MaxUploadSize() int64
+}
+
+type attachmentService struct {
+	repo        repositories.AttachmentRepository
+	toolkitRepo repositories.ToolkitRepository
+	storage     storage.Storage
+	policy      AttachmentPolicy
+}
+
+func NewAttachmentService(repo repositories.AttachmentRepository, toolkitRepo repositories.ToolkitRepository,
+	store storage.Storage, policy AttachmentPolicy) AttachmentService {
+	return &attachmentService{
+		repo:        repo,
+		toolkitRepo: toolkitRepo,
+		storage:     store,
+		policy:      policy,
+	}
+}
+
+func (s *attachmentService) Upload(ctx context.Context, toolkitID, actorID int, req *models.AttachmentUploadRequest) (*models.Attachment, error) {
+	toolkit, err := s.toolkitRepo.GetByID(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+
+	limit, allowed := s.policy.MaxDocumentSize, documentContentTypes
+	if req.Kind == models.AttachmentPhoto {
+		limit, allowed = s.policy.MaxPhotoSize, photoContentTypes
+	}
+	if req.Kind == models.AttachmentPhoto && req.DocumentType != "" {
+		return nil, NewValidationError("document_type is only valid for documents",
+			FieldError{Field: "document_type", Message: "must be empty for photos"})
+	}
+	if req.File.Size > limit {
+		return nil, NewTooLargeError("file", limit)
+	}
+
+	data, err := readUpload(req, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, NewValidationError("file is empty", FieldError{Field: "file", Message: "must not be empty"})
+	}
+
+	// Content-Type dari client tidak dipercaya, tipe ditentukan dari isi file
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !slices.Contains(allowed, contentType) {
+		return nil, NewUnsupportedMediaError("file", contentType, allowed)
+	}
+
+	attachment := &models.Attachment{
+		ToolkitID:    toolkitID,
+		Kind:         req.Kind,
+		DocumentType: req.DocumentType,
+		Title:        strings.TrimSpace(req.Title),
+		FileName:     filepath.Base(req.File.Filename),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		UploadedBy:   &actorID,
+	}
+	if attachment.Kind == models.AttachmentDocument && attachment.DocumentType == "" {
+		attachment.DocumentType = models.DocumentOther
+	}
+
+	var thumbnail []byte
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, attachment.Width, attachment.Height, err = s.thumbnail(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	attachment.StorageKey = fmt.Sprintf("toolkits/%d/%s%s", toolkitID, name, contentTypeExtensions[contentType])
+	if err := s.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, fmt.Errorf("store attachment: %w", err)
+	}
+	if thumbnail != nil {
+		attachment.ThumbnailKey = fmt.Sprintf("toolkits/%d/%s_thumb.jpg", toolkitID, name)
+		attachment.ThumbnailSize = int64(len(thumbnail))
+		if err := s.storage.Put(ctx, attachment.ThumbnailKey, bytes.NewReader(thumbnail), attachment.ThumbnailSize, "image/jpeg"); err != nil {
+			s.RemoveFiles(ctx, []models.Attachment{*attachment})
+			return nil, fmt.Errorf("store thumbnail: %w", err)
+		}
+	}
+
+	if _, err := s.repo.Create(ctx, attachment); err != nil {
+		s.RemoveFiles(ctx, []models.Attachment{*attachment})
+		return nil, translateError(err, "attachment")
+	}
+	withURLs(attachment)
+
+	// Foto pertama otomatis jadi gambar utama toolkit
+	if attachment.Kind == models.AttachmentPhoto && (req.Primary || toolkit.PrimaryImageID == nil) {
+		if err := s.repo.SetToolkitImage(ctx, toolkitID, attachment.ID); err != nil {
+			return nil, translateError(err, "toolkit")
+		}
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) GetByID(ctx context.Context, id int) (*models.Attachment, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "attachment")
+	}
+	withURLs(attachment)
+	return attachment, nil
+}
+
+func (s *attachmentService) GetByToolkit(ctx context.Context, toolkitID int) ([]models.Attachment, error) {
+	if _, err := s.toolkitRepo.GetByID(ctx, toolkitID); err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	attachments, err := s.repo.GetByToolkit(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "attachment")
+	}
+	for i := range attachments {
+		withURLs(&attachments[i])
+	}
+	return attachments, nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, id int, thumbnail bool) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, NewNotFoundError("thumbnail")
+		}
+		key = attachment.ThumbnailKey
+		attachment.ContentType = "image/jpeg"
+		attachment.Size = attachment.ThumbnailSize
+	}
+
+	reader, err := s.storage.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, NewNotFoundError("file")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open attachment: %w", err)
+	}
+	return attachment, reader, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, id int) error {
+	attachment, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return translateError(err, "attachment")
+	}
+	s.RemoveFiles(ctx, []models.Attachment{*attachment})
+
+	// Foto utama dihapus: pakai foto terbaru yang tersisa, atau kosongkan
+	if attachment.Kind == models.AttachmentPhoto {
+		remaining, err := s.repo.GetByToolkit(ctx, attachment.ToolkitID)
+		if err != nil {
+			return translateError(err, "attachment")
+		}
+		var next *int
+		for _, other := range remaining {
+			if other.Kind == models.AttachmentPhoto {
+				next = &other.ID
+				break
+			}
+		}
+		if err := s.repo.ReplaceToolkitImage(ctx, attachment.ToolkitID, attachment.ID, next); err != nil {
+			return translateError(err, "toolkit")
+		}
+	}
+	return nil
+}
+
+func (s *attachmentService) RemoveFiles(ctx context.Context, attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			// Object yatim tidak merusak data, cukup di-log supaya bisa dibersihkan manual
+			if err := s.storage.Delete(ctx, key); err != nil {
+				slog.WarnContext(ctx, "Failed to delete stored file", "key", key, "error", err)
+			}
+		}
+	}
+}
+
+func (s *attachmentService) MaxUploadSize() int64 {
+	return max(s.policy.MaxPhotoSize, s.policy.MaxDocumentSize)
+}
+
+// thumbnail JPEG dengan sisi terpanjang ThumbnailSize, transparansi diganti latar putih
+func (s *attachmentService) thumbnail(data []byte) ([]byte, int, int, error) {
+	invalid := NewValidationError("file is not a valid image", FieldError{Field: "file", Message: "could not be decoded"})
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, invalid
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, 0, 0, NewValidationError("image dimensions are too large",
+			FieldError{Field: "file", Message: fmt.Sprintf("must be at most %d megapixels", maxImagePixels/1_000_000)})
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, 0, 0, invalid
+	}
+	bounds := img.Bounds()
+
+	thumb := imaging.Fit(img, s.policy.ThumbnailSize, s.policy.ThumbnailSize, imaging.Lanczos)
+	background := imaging.New(thumb.Bounds().Dx(), thumb.Bounds().Dy(), color.White)
+	thumb = imaging.Overlay(background, thumb, image.Pt(0, 0), 1)
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, thumb, imaging.JPEG, imaging.JPEGQuality(80)); err != nil {
+		return nil, 0, 0, fmt.Errorf("encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), bounds.Dx(), bounds.Dy(), nil
+}
+
+// readUpload baca seluruh file, maksimal limit byte
+func readUpload(req *models.AttachmentUploadRequest, limit int64) ([]byte, error) {
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open upload: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read upload: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, NewTooLargeError("file", limit)
+	}
+	return data, nil
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func withURLs(attachment *models.Attachment) {
+	attachment.URL = models.AttachmentContentURL(attachment.ID, false)
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL = models.AttachmentContentURL(attachment.ID, true)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+// Authenticator backend login username/password yang dipakai userService.Login secara berurutan.
+// auth.ErrInvalidCredentials berarti backend berikutnya dicoba.
+type Authenticator interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*models.User, error)
+}
+
+type localAuthenticator struct {
+	userRepo repositories.UserRepository
+}
+
+// NewLocalAuthenticator password bcrypt di tabel users
+func NewLocalAuthenticator(repo repositories.UserRepository) Authenticator {
+	return &localAuthenticator{userRepo: repo}
+}
+
+func (a *localAuthenticator) Name() string {
+	return "local"
+}
+
+func (a *localAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := a.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// User dari SSO/direktori tidak punya password lokal
+	if user.AuthProvider != "" && user.AuthProvider != "local" {
+		return nil, auth.ErrInvalidCredentials
+	}
+	if err := models.CheckPassword(user.Password, password); err != nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+type ldapAuthenticator struct {
+	directory *auth.LDAPDirectory
+	userRepo  repositories.UserRepository
+}
+
+// NewLDAPAuthenticator bind ke LDAP/Active Directory. User dibuat saat login pertama dan
+// nama, email, role serta department disinkronkan dari direktori setiap login.
+func NewLDAPAuthenticator(directory *auth.LDAPDirectory, repo repositories.UserRepository) Authenticator {
+	return &ldapAuthenticator{directory: directory, userRepo: repo}
+}
+
+func (a *ldapAuthenticator) Name() string {
+	return "ldap"
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	identity, err := a.directory.Authenticate(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveExternalUser(ctx, a.userRepo, &models.ExternalIdentity{
+		Provider: "ldap",
+		Subject:  identity.ID,
+		Username: identity.Username,
+		Email:    identity.Email,
+		// Email di direktori dikelola admin, cukup untuk membuat user baru. Akun lokal dengan
+		// email yang sama tetap tidak ditautkan (lihat provisionExternal).
+		EmailVerified: true,
+		FullName:      identity.FullName,
+		Role:          identity.Role,
+		DefaultRole:   identity.DefaultRole,
+		Department:    identity.Department,
+	})
+}
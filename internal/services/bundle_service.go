@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+)
+
+type BundleService interface {
+	Create(ctx context.Context, req *models.BundleCreateRequest) (*models.Bundle, error)
+	GetByID(ctx context.Context, id int) (*models.Bundle, error)
+	GetAll(ctx context.Context, filter *models.BundleFilterRequest) ([]*models.Bundle, error)
+	Update(ctx context.Context, id int, req *models.BundleUpdateRequest) (*models.Bundle, error)
+	Delete(ctx context.Context, id int) error
+
+	// Checkout pinjam satu atau beberapa set bundle: satu loan induk plus satu loan per komponen
+	Checkout(ctx context.Context, id int, req *models.BundleCheckoutRequest) (*models.BundleLoan, error)
+	GetLoanByID(ctx context.Context, id int) (*models.BundleLoan, error)
+	GetLoans(ctx context.Context, filter *models.BundleLoanFilterRequest) ([]*models.BundleLoan, error)
+	// Return kembalikan semua atau sebagian komponen bundle loan
+	Return(ctx context.Context, id int, req *models.BundleReturnRequest) (*models.BundleLoan, error)
+}
+
+type bundleService struct {
+	repo        repositories.BundleRepository
+	toolkitRepo repositories.ToolkitRepository
+	inspections inspectionGate
+}
+
+func NewBundleService(repo repositories.BundleRepository, toolkitRepo repositories.ToolkitRepository,
+	inspectionRepo repositories.InspectionRepository, inspectionPolicy InspectionPolicy) BundleService {
+	return &bundleService{repo: repo, toolkitRepo: toolkitRepo,
+		inspections: inspectionGate{repo: inspectionRepo, policy: inspectionPolicy}}
+}
+
+func (s *bundleService) Create(ctx context.Context, req *models.BundleCreateRequest) (*models.Bundle, error) {
+	components, err := s.components(ctx, req.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.Bundle{
+		Name:        strings.TrimSpace(req.Name),
+		Code:        strings.TrimSpace(req.Code),
+		Description: req.Description,
+		IsActive:    true,
+		Components:  components,
+	}
+	if _, err := s.repo.Create(ctx, bundle); err != nil {
+		return nil, translateError(err, "bundle")
+	}
+	return s.GetByID(ctx, bundle.ID)
+}
+
+func (s *bundleService) GetByID(ctx context.Context, id int) (*models.Bundle, error) {
+	bundle, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "bundle")
+	}
+	bundle.Available = bundleAvailable(bundle)
+	return bundle, nil
+}
+
+func (s *bundleService) GetAll(ctx context.Context, filter *models.BundleFilterRequest) ([]*models.Bundle, error) {
+	bundles, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "bundle")
+	}
+	for _, bundle := range bundles {
+		bundle.Available = bundleAvailable(bundle)
+	}
+	return bundles, nil
+}
+
+func (s *bundleService) Update(ctx context.Context, id int, req *models.BundleUpdateRequest) (*models.Bundle, error) {
+	bundle, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "bundle")
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		bundle.Name = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		bundle.Code = code
+	}
+	if req.Description != "" {
+		bundle.Description = req.Description
+	}
+	if req.IsActive != nil {
+		bundle.IsActive = *req.IsActive
+	}
+
+	var components []models.BundleComponent
+	if req.Components != nil {
+		if components, err = s.components(ctx, req.Components); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.repo.Update(ctx, bundle, components); err != nil {
+		return nil, translateError(err, "bundle")
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *bundleService) Delete(ctx context.Context, id int) error {
+	// Loan induk yang sudah selesai tetap disimpan sebagai riwayat (bundle_id jadi NULL)
+	active, err := s.repo.HasActiveLoans(ctx, id)
+	if err != nil {
+		return translateError(err, "bundle")
+	}
+	if active {
+		return NewConflictError("bundle_in_use", "bundle has components that are still on loan")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return translateError(err, "bundle")
+	}
+	return nil
+}
+
+func (s *bundleService) Checkout(ctx context.Context, id int, req *models.BundleCheckoutRequest) (*models.BundleLoan, error) {
+	bundle, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !bundle.IsActive {
+		return nil, NewConflictError("bundle_inactive", "bundle is deactivated and cannot be checked out")
+	}
+
+	sets := max(req.Quantity, 1)
+	if bundle.Available < sets {
+		return nil, bundleShortage(bundle, sets)
+	}
+	for _, component := range bundle.Components {
+		if err := s.inspections.checkout(ctx, component.ToolkitID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	parent := &models.BundleLoan{
+		BundleID:   &bundle.ID,
+		BundleName: bundle.Name,
+		UserID:     req.UserID,
+		Quantity:   sets,
+		Purpose:    req.Purpose,
+		BorrowDate: now,
+		DueDate:    req.DueDate,
+		ApprovedBy: req.ApprovedBy,
+		Notes:      req.Notes,
+	}
+	loans := make([]models.Loan, 0, len(bundle.Components))
+	for _, component := range bundle.Components {
+		loans = append(loans, models.Loan{
+			UserID:           req.UserID,
+			ToolkitID:        component.ToolkitID,
+			Quantity:         component.Quantity * sets,
+			Purpose:          req.Purpose,
+			BorrowDate:       now,
+			DueDate:          req.DueDate,
+			Status:           "borrowed",
+			ApprovedBy:       req.ApprovedBy,
+			Notes:            req.Notes,
+			ConditionChecked: req.ConditionChecked,
+			LocationID:       req.LocationID,
+		})
+	}
+
+	if err := s.repo.Checkout(ctx, parent, loans); err != nil {
+		// Stok berubah di antara pengecekan dan transaksi: hitung ulang untuk pesan error
+		if errors.Is(err, repositories.ErrInsufficientAvailable) {
+			if fresh, getErr := s.GetByID(ctx, id); getErr == nil {
+				if fresh.Available >= sets {
+					return nil, locationShortage(req.LocationID, sets)
+				}
+				return nil, bundleShortage(fresh, sets)
+			}
+			return nil, NewInsufficientStockError(0, sets)
+		}
+		return nil, translateError(err, "loan")
+	}
+	return s.GetLoanByID(ctx, parent.ID)
+}
+
+func (s *bundleService) GetLoanByID(ctx context.Context, id int) (*models.BundleLoan, error) {
+	loan, err := s.repo.GetLoanByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "bundle_loan")
+	}
+	loan.Status = bundleLoanStatus(loan)
+	return loan, nil
+}
+
+func (s *bundleService) GetLoans(ctx context.Context, filter *models.BundleLoanFilterRequest) ([]*models.BundleLoan, error) {
+	loans, err := s.repo.GetLoans(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "bundle_loan")
+	}
+	for _, loan := range loans {
+		loan.Status = bundleLoanStatus(loan)
+	}
+	return loans, nil
+}
+
+func (s *bundleService) Return(ctx context.Context, id int, req *models.BundleReturnRequest) (*models.BundleLoan, error) {
+	parent, err := s.GetLoanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := make(map[int]*models.Loan)
+	for i := range parent.Loans {
+		if loan := &parent.Loans[i]; loan.Status == "borrowed" || loan.Status == "overdue" {
+			outstanding[loan.ID] = loan
+		}
+	}
+
+	var returns []repositories.BundleReturn
+	if len(req.Items) == 0 {
+		for i := range parent.Loans {
+			if loan, ok := outstanding[parent.Loans[i].ID]; ok {
+				returns = append(returns, repositories.BundleReturn{Loan: loan, Quantity: loan.Quantity})
+			}
+		}
+	}
+	seen := make(map[int]bool, len(req.Items))
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		loan, ok := outstanding[item.LoanID]
+		if !ok {
+			return nil, NewValidationError("loan is not an outstanding component of this bundle loan",
+				FieldError{Field: field + ".loan_id", Message: "must be a borrowed or overdue loan of this bundle loan"})
+		}
+		if seen[item.LoanID] {
+			return nil, NewValidationError("duplicate loan in return items",
+				FieldError{Field: field + ".loan_id", Message: "is listed more than once"})
+		}
+		seen[item.LoanID] = true
+
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = loan.Quantity
+		}
+		if quantity > loan.Quantity {
+			return nil, NewValidationError("cannot return more units than are on loan",
+				FieldError{Field: field + ".quantity", Message: fmt.Sprintf("must be at most %d", loan.Quantity)})
+		}
+		returns = append(returns, repositories.BundleReturn{Loan: loan, Quantity: quantity})
+	}
+	if len(returns) == 0 {
+		return nil, NewConflictError("bundle_loan_returned", "bundle loan has no outstanding components")
+	}
+	for _, ret := range returns {
+		if err := s.inspections.returned(ctx, ret.Loan.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Return(ctx, returns, req.ReturnLocationID, req.ConditionReturn, req.Notes); err != nil {
+		if errors.Is(err, repositories.ErrLoanChanged) {
+			return nil, NewConflictError("loan_changed", "a component loan was changed by another request, reload and try again")
+		}
+		return nil, translateError(err, "loan")
+	}
+	return s.GetLoanByID(ctx, id)
+}
+
+// components validasi daftar komponen: toolkit harus ada dan tidak boleh dobel
+func (s *bundleService) components(ctx context.Context, reqs []models.BundleComponentRequest) ([]models.BundleComponent, error) {
+	ids := make([]int, 0, len(reqs))
+	seen := make(map[int]bool, len(reqs))
+	for i, req := range reqs {
+		if seen[req.ToolkitID] {
+			return nil, NewValidationError("duplicate toolkit in components",
+				FieldError{Field: fmt.Sprintf("components[%d].toolkit_id", i), Message: "is listed more than once"})
+		}
+		seen[req.ToolkitID] = true
+		ids = append(ids, req.ToolkitID)
+	}
+
+	toolkits, err := s.toolkitRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	found := make(map[int]bool, len(toolkits))
+	for _, toolkit := range toolkits {
+		found[toolkit.ID] = true
+	}
+
+	components := make([]models.BundleComponent, 0, len(reqs))
+	for i, req := range reqs {
+		if !found[req.ToolkitID] {
+			return nil, NewValidationError("toolkit not found",
+				FieldError{Field: fmt.Sprintf("components[%d].toolkit_id", i), Message: "does not exist"})
+		}
+		components = append(components, models.BundleComponent{ToolkitID: req.ToolkitID, Quantity: req.Quantity})
+	}
+	return components, nil
+}
+
+// bundleAvailable jumlah set lengkap yang bisa dibentuk dari stok tersedia komponen
+func bundleAvailable(bundle *models.Bundle) int {
+	if len(bundle.Components) == 0 {
+		return 0
+	}
+	available := -1
+	for _, component := range bundle.Components {
+		sets := 0
+		if component.Toolkit != nil && component.Quantity > 0 && component.Toolkit.Status != "retired" {
+			sets = component.Toolkit.Available / component.Quantity
+		}
+		if available < 0 || sets < available {
+			available = sets
+		}
+	}
+	return available
+}
+
+// bundleShortage error stok kurang dengan daftar komponen yang jadi penyebab
+func bundleShortage(bundle *models.Bundle, sets int) error {
+	err := NewInsufficientStockError(bundle.Available, sets)
+	err.Message = fmt.Sprintf("insufficient bundle sets available (available %d, requested %d)", bundle.Available, sets)
+	for i, component := range bundle.Components {
+		need := component.Quantity * sets
+		if component.Toolkit == nil || component.Toolkit.Available >= need {
+			continue
+		}
+		err.Fields = append(err.Fields, FieldError{
+			Field:   fmt.Sprintf("components[%d]", i),
+			Message: fmt.Sprintf("%s needs %d, only %d available", component.Toolkit.SKU, need, component.Toolkit.Available),
+		})
+	}
+	return err
+}
+
+// locationShortage total stok cukup, tapi tidak di lokasi yang diminta (atau di luar lokasi)
+func locationShortage(locationID *int, sets int) error {
+	if locationID == nil {
+		return NewValidationError("not enough component stock outside of locations",
+			FieldError{Field: "location_id", Message: "is required when stock is held at locations"})
+	}
+	err := NewInsufficientStockError(0, sets)
+	err.Message = "insufficient component stock at location"
+	err.Fields = []FieldError{{Field: "location_id", Message: "does not hold enough units of every component"}}
+	return err
+}
+
+// bundleLoanStatus returned kalau semua loan komponen kembali, partially_returned kalau sebagian
+func bundleLoanStatus(parent *models.BundleLoan) string {
+	returned := 0
+	for _, loan := range parent.Loans {
+		if loan.Status == "returned" {
+			returned++
+		}
+	}
+	switch {
+	case len(parent.Loans) > 0 && returned == len(parent.Loans):
+		return models.BundleLoanReturned
+	case returned > 0:
+		return models.BundleLoanPartiallyReturned
+	default:
+		return models.BundleLoanBorrowed
+	}
+}
@@ -1,17 +1,20 @@
 package services
 
 import (
+	"context"
+	"errors"
+
 	"toolkit-management/internal/models"
 	. "toolkit-management/internal/repositories"
 )
 
 type CategoryService interface {
-	Create(req *models.CategoryCreateRequest) (*models.Category, error)
-	GetByID(id int) (*models.Category, error)
-	GetAll(filter *models.CategoryFilterRequest) ([]models.Category, error)
-	Update(id int, req *models.CategoryUpdateRequest) (*models.Category, error)
-	Delete(id int) error
-	GetTree() ([]models.Category, error)
+	Create(ctx context.Context, req *models.CategoryCreateRequest) (*models.Category, error)
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	GetAll(ctx context.Context, filter *models.CategoryFilterRequest) ([]models.Category, error)
+	Update(ctx context.Context, id int, req *models.CategoryUpdateRequest) (*models.Category, error)
+	Delete(ctx context.Context, id int) error
+	GetTree(ctx context.Context) ([]models.Category, error)
 }
 
 type categoryService struct {
@@ -22,27 +25,49 @@ func NewCategoryService(repo CategoryRepository) CategoryService {
 	return &categoryService{categoryRepo: repo}
 }
 
-func (s *categoryService) Create(req *models.CategoryCreateRequest) (*models.Category, error) {
+func (s *categoryService) Create(ctx context.Context, req *models.CategoryCreateRequest) (*models.Category, error) {
 	category := &models.Category{
 		Name:        req.Name,
 		Description: req.Description,
 		SortOrder:   req.SortOrder,
 		IsActive:    true,
+
+		DepreciationMethod: req.DepreciationMethod,
+		UsefulLifeMonths:   req.UsefulLifeMonths,
+		SalvagePercent:     req.SalvagePercent,
+	}
+	if category.DepreciationMethod == "" {
+		category.DepreciationMethod = models.DepreciationStraightLine
+	}
+	if category.UsefulLifeMonths == 0 {
+		category.UsefulLifeMonths = models.DefaultUsefulLifeMonths
 	}
 
-	return s.categoryRepo.Create(category)
+	result, err := s.categoryRepo.Create(ctx, category)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return result, nil
 }
 
-func (s *categoryService) GetByID(id int) (*models.Category, error) {
-	return s.categoryRepo.GetByID(id)
+func (s *categoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return category, nil
 }
 
-func (s *categoryService) GetAll(filter *models.CategoryFilterRequest) ([]models.Category, error) {
-	return s.categoryRepo.GetAll(filter)
+func (s *categoryService) GetAll(ctx context.Context, filter *models.CategoryFilterRequest) ([]models.Category, error) {
+	categories, err := s.categoryRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return categories, nil
 }
 
-func (s *categoryService) Update(id int, req *models.CategoryUpdateRequest) (*models.Category, error) {
-	category, err := s.categoryRepo.GetByID(id)
+func (s *categoryService) Update(ctx context.Context, id int, req *models.CategoryUpdateRequest) (*models.Category, error) {
+	category, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -59,14 +84,36 @@ func (s *categoryService) Update(id int, req *models.CategoryUpdateRequest) (*mo
 	if req.IsActive != nil {
 		category.IsActive = *req.IsActive
 	}
+	if req.DepreciationMethod != "" {
+		category.DepreciationMethod = req.DepreciationMethod
+	}
+	if req.UsefulLifeMonths != 0 {
+		category.UsefulLifeMonths = req.UsefulLifeMonths
+	}
+	if req.SalvagePercent != nil {
+		category.SalvagePercent = *req.SalvagePercent
+	}
 
-	return s.categoryRepo.Update(category)
+	result, err := s.categoryRepo.Update(ctx, category)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return result, nil
 }
 
-func (s *categoryService) Delete(id int) error {
-	return s.categoryRepo.Delete(id)
+func (s *categoryService) Delete(ctx context.Context, id int) error {
+	// Category masih dipakai toolkit -> foreign key violation -> conflict
+	err := translateError(s.categoryRepo.Delete(ctx, id), "category")
+	if errors.Is(err, ErrValidation) {
+		return NewConflictError("category_in_use", "category is still used by toolkits")
+	}
+	return err
 }
 
-func (s *categoryService) GetTree() ([]models.Category, error) {
-	return s.categoryRepo.GetTree()
+func (s *categoryService) GetTree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.categoryRepo.GetTree(ctx)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return categories, nil
 }
\ No newline at end of file
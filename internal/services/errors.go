@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"toolkit-management/pkg/utils"
+)
+
+type ErrorKind string
+
+const (
+	ErrKindNotFound          ErrorKind = "not_found"
+	ErrKindConflict          ErrorKind = "conflict"
+	ErrKindValidation        ErrorKind = "validation_failed"
+	ErrKindForbidden         ErrorKind = "forbidden"
+	ErrKindUnauthorized      ErrorKind = "unauthorized"
+	ErrKindInsufficientStock ErrorKind = "insufficient_stock"
+	ErrKindTooLarge          ErrorKind = "payload_too_large"
+	ErrKindUnsupportedMedia  ErrorKind = "unsupported_media_type"
+)
+
+// Sentinel untuk errors.Is, dicocokkan berdasarkan Kind
+var (
+	ErrNotFound          = &Error{Kind: ErrKindNotFound}
+	ErrConflict          = &Error{Kind: ErrKindConflict}
+	ErrValidation        = &Error{Kind: ErrKindValidation}
+	ErrForbidden         = &Error{Kind: ErrKindForbidden}
+	ErrUnauthorized      = &Error{Kind: ErrKindUnauthorized}
+	ErrInsufficientStock = &Error{Kind: ErrKindInsufficientStock}
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error domain error dari layer service. Code stabil untuk client, Message untuk manusia.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind && (t.Code == "" || t.Code == e.Code)
+}
+
+func NewNotFoundError(resource string) *Error {
+	return &Error{
+		Kind:    ErrKindNotFound,
+		Code:    resource + "_not_found",
+		Message: resource + " not found",
+	}
+}
+
+func NewConflictError(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrKindConflict, Code: code, Message: message, Fields: fields}
+}
+
+func NewValidationError(message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrKindValidation, Code: string(ErrKindValidation), Message: message, Fields: fields}
+}
+
+func NewForbiddenError(message string) *Error {
+	return &Error{Kind: ErrKindForbidden, Code: string(ErrKindForbidden), Message: message}
+}
+
+func NewUnauthorizedError(message string) *Error {
+	return &Error{Kind: ErrKindUnauthorized, Code: string(ErrKindUnauthorized), Message: message}
+}
+
+func NewInsufficientStockError(available, requested int) *Error {
+	return &Error{
+		Kind:    ErrKindInsufficientStock,
+		Code:    string(ErrKindInsufficientStock),
+		Message: fmt.Sprintf("insufficient toolkit quantity available (available %d, requested %d)", available, requested),
+		Fields:  []FieldError{{Field: "quantity", Message: fmt.Sprintf("only %d available", available)}},
+	}
+}
+
+func NewTooLargeError(field string, maxBytes int64) *Error {
+	message := fmt.Sprintf("must be at most %d MB", maxBytes>>20)
+	return &Error{
+		Kind:    ErrKindTooLarge,
+		Code:    string(ErrKindTooLarge),
+		Message: field + " " + message,
+		Fields:  []FieldError{{Field: field, Message: message}},
+	}
+}
+
+func NewUnsupportedMediaError(field, contentType string, allowed []string) *Error {
+	message := "must be one of: " + strings.Join(allowed, ", ")
+	return &Error{
+		Kind:    ErrKindUnsupportedMedia,
+		Code:    string(ErrKindUnsupportedMedia),
+		Message: fmt.Sprintf("unsupported file type %s", contentType),
+		Fields:  []FieldError{{Field: field, Message: message}},
+	}
+}
+
+// translateError mengubah error repository/database menjadi domain error
+func translateError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewNotFoundError(resource)
+	}
+
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		return NewValidationError("invalid pagination cursor", FieldError{Field: "cursor", Message: "invalid cursor"})
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		field := constraintField(pgErr)
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return &Error{
+				Kind:    ErrKindConflict,
+				Code:    "duplicate_" + field,
+				Message: fmt.Sprintf("%s with this %s already exists", resource, field),
+				Fields:  []FieldError{{Field: field, Message: "already exists"}},
+				Err:     err,
+			}
+		case "23503": // foreign_key_violation
+			return &Error{
+				Kind:    ErrKindValidation,
+				Code:    string(ErrKindValidation),
+				Message: "referenced record does not exist",
+				Fields:  []FieldError{{Field: field, Message: "does not exist"}},
+				Err:     err,
+			}
+		}
+	}
+
+	return err
+}
+
+// constraintField ambil nama kolom dari detail Postgres, mis. "Key (sku)=(X) already exists."
+func constraintField(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+
+	if start := strings.Index(pgErr.Detail, "Key ("); start >= 0 {
+		rest := pgErr.Detail[start+len("Key ("):]
+		if end := strings.Index(rest, ")"); end > 0 {
+			return rest[:end]
+		}
+	}
+
+	// Fallback ke nama constraint, mis. uni_toolkits_sku -> sku
+	name := strings.TrimPrefix(pgErr.ConstraintName, "uni_")
+	name = strings.TrimPrefix(name, pgErr.TableName+"_")
+	if name == "" {
+		return "value"
+	}
+	return name
+}
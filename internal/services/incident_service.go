@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+type IncidentService interface {
+	Create(ctx context.Context, loanID int, req *models.IncidentCreateRequest, actor *auth.JWTClaim) (*models.Incident, error)
+	GetByID(ctx context.Context, id int) (*models.Incident, error)
+	GetAll(ctx context.Context, filter *models.IncidentFilterRequest) ([]*models.Incident, error)
+	AddEvidence(ctx context.Context, id int, req *models.IncidentEvidenceRequest, actor *auth.JWTClaim) (*models.IncidentEvidence, error)
+	Assess(ctx context.Context, id int, req *models.IncidentAssessRequest, actor *auth.JWTClaim) (*models.Incident, error)
+	Resolve(ctx context.Context, id int, req *models.IncidentResolveRequest, actor *auth.JWTClaim) (*models.Incident, error)
+	GetAdjustments(ctx context.Context, toolkitID int) ([]*models.StockAdjustment, error)
+}
+
+type incidentService struct {
+	repo        repositories.IncidentRepository
+	loanRepo    repositories.LoanRepository
+	toolkitRepo repositories.ToolkitRepository
+}
+
+func NewIncidentService(repo repositories.IncidentRepository, loanRepo repositories.LoanRepository, toolkitRepo repositories.ToolkitRepository) IncidentService {
+	return &incidentService{repo: repo, loanRepo: loanRepo, toolkitRepo: toolkitRepo}
+}
+
+func (s *incidentService) Create(ctx context.Context, loanID int, req *models.IncidentCreateRequest, actor *auth.JWTClaim) (*models.Incident, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, translateError(err, "loan")
+	}
+	if err := ensureNoOpenIncident(ctx, s.repo, loan.ID); err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = loan.Quantity
+	}
+	if quantity > loan.Quantity {
+		return nil, NewValidationError("quantity exceeds loan quantity",
+			FieldError{Field: "quantity", Message: fmt.Sprintf("loan only has %d units", loan.Quantity)})
+	}
+
+	toolkit, err := s.toolkitRepo.GetByID(ctx, loan.ToolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+
+	incident := newIncident(loan, toolkit, req.Type, quantity, req.Description)
+	incident.ReportedBy = &actor.UserID
+	// Kerusakan ditemukan setelah loan returned: unit sudah masuk stok, ditarik lagi
+	// dari lokasi tempat unit dikembalikan
+	incident.HeldFromStock = loan.Status == "returned"
+	if incident.HeldFromStock {
+		incident.LocationID = loan.ReturnLocationID
+	}
+
+	if _, err := s.repo.Create(ctx, incident); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientAvailable) {
+			return nil, NewInsufficientStockError(toolkit.Available, quantity)
+		}
+		return nil, translateError(err, "incident")
+	}
+	return s.GetByID(ctx, incident.ID)
+}
+
+func (s *incidentService) GetByID(ctx context.Context, id int) (*models.Incident, error) {
+	incident, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "incident")
+	}
+	return incident, nil
+}
+
+func (s *incidentService) GetAll(ctx context.Context, filter *models.IncidentFilterRequest) ([]*models.Incident, error) {
+	incidents, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "incident")
+	}
+	return incidents, nil
+}
+
+// AddEvidence hanya staff, pelapor atau peminjam pada loan incident
+func (s *incidentService) AddEvidence(ctx context.Context, id int, req *models.IncidentEvidenceRequest, actor *auth.JWTClaim) (*models.IncidentEvidence, error) {
+	incident, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	staff := actor.Role == "admin" || actor.Role == "technician"
+	reporter := incident.ReportedBy != nil && *incident.ReportedBy == actor.UserID
+	if !staff && !reporter && incident.UserID != actor.UserID {
+		return nil, NewForbiddenError("only staff, the reporter or the borrower can add evidence to this incident")
+	}
+
+	evidence := &models.IncidentEvidence{
+		IncidentID:  id,
+		URL:         req.URL,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		Description: req.Description,
+		UploadedBy:  actor.UserID,
+	}
+	result, err := s.repo.AddEvidence(ctx, evidence)
+	if err != nil {
+		return nil, translateError(err, "evidence")
+	}
+	return result, nil
+}
+
+// Assess boleh diulang selama incident belum resolved
+func (s *incidentService) Assess(ctx context.Context, id int, req *models.IncidentAssessRequest, actor *auth.JWTClaim) (*models.Incident, error) {
+	incident, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.Status == models.IncidentResolved {
+		return nil, NewConflictError("incident_resolved", "incident is already resolved")
+	}
+
+	cost := incident.ReplacementCost
+	if req.AssessedCost != nil {
+		cost = *req.AssessedCost
+	}
+	if incident.ReplacementCost > 0 && cost > incident.ReplacementCost {
+		return nil, NewValidationError("assessed cost exceeds replacement cost, write the units off instead",
+			FieldError{Field: "assessed_cost", Message: fmt.Sprintf("must be at most %.2f", incident.ReplacementCost)})
+	}
+
+	now := time.Now()
+	incident.AssessedCost = &cost
+	incident.AssessedBy = &actor.UserID
+	incident.AssessedAt = &now
+	incident.AssessmentNotes = req.Notes
+	incident.Status = models.IncidentAssessed
+
+	if _, err := s.repo.Update(ctx, incident); err != nil {
+		return nil, translateError(err, "incident")
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *incidentService) Resolve(ctx context.Context, id int, req *models.IncidentResolveRequest, actor *auth.JWTClaim) (*models.Incident, error) {
+	incident, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.Status != models.IncidentAssessed {
+		return nil, NewConflictError("incident_not_assessed", "incident must be assessed before it can be resolved")
+	}
+	if incident.Type == models.IncidentLost && req.Resolution == models.ResolutionRepaired {
+		return nil, NewValidationError("lost units cannot be repaired",
+			FieldError{Field: "resolution", Message: "must be written_off or charged_to_department for lost units"})
+	}
+	if req.Resolution == models.ResolutionChargedToDepartment {
+		if incident.User == nil || incident.User.Department == "" {
+			return nil, NewValidationError("borrower has no department to charge",
+				FieldError{Field: "resolution", Message: "borrower department is empty"})
+		}
+		incident.ChargedDepartment = incident.User.Department
+	}
+
+	var resolution repositories.IncidentResolution
+	// Unit loan di luar incident ikut kembali saat loan ditutup
+	if !incident.HeldFromStock && incident.Loan != nil && incident.Loan.Status != "returned" {
+		resolution.CloseLoan = true
+		resolution.LoanStatus = incident.Loan.Status
+		resolution.Restock = incident.Loan.Quantity - incident.Quantity
+	}
+	if req.Resolution == models.ResolutionRepaired {
+		resolution.Restock += incident.Quantity
+	} else {
+		resolution.Adjustment = &models.StockAdjustment{
+			ToolkitID:      incident.ToolkitID,
+			QuantityChange: -incident.Quantity,
+			Reason:         req.Resolution,
+			ReferenceType:  "incident",
+			ReferenceID:    &incident.ID,
+			Notes:          req.Notes,
+			CreatedBy:      &actor.UserID,
+		}
+	}
+
+	now := time.Now()
+	incident.Status = models.IncidentResolved
+	incident.Resolution = req.Resolution
+	incident.ResolutionNotes = req.Notes
+	incident.ResolvedBy = &actor.UserID
+	incident.ResolvedAt = &now
+
+	if err := s.repo.Resolve(ctx, incident, resolution); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrInsufficientAvailable):
+			return nil, NewConflictError("insufficient_quantity", "toolkit quantity is lower than the units to write off")
+		case errors.Is(err, repositories.ErrIncidentChanged):
+			return nil, NewConflictError("incident_changed", "incident was changed by another request, reload and try again")
+		case errors.Is(err, repositories.ErrLoanChanged):
+			return nil, NewConflictError("loan_changed", "loan was changed by another request, reload and try again")
+		}
+		return nil, translateError(err, "incident")
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *incidentService) GetAdjustments(ctx context.Context, toolkitID int) ([]*models.StockAdjustment, error) {
+	if _, err := s.toolkitRepo.GetByID(ctx, toolkitID); err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	adjustments, err := s.repo.GetAdjustments(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "stock adjustment")
+	}
+	return adjustments, nil
+}
+
+func ensureNoOpenIncident(ctx context.Context, repo repositories.IncidentRepository, loanID int) error {
+	_, err := repo.GetOpenByLoan(ctx, loanID)
+	if err == nil {
+		return NewConflictError("incident_open", "loan already has an unresolved incident")
+	}
+	if errors.Is(translateError(err, "incident"), ErrNotFound) {
+		return nil
+	}
+	return translateError(err, "incident")
+}
+
+func newIncident(loan *models.Loan, toolkit *models.Toolkit, incidentType string, quantity int, description string) *models.Incident {
+	return &models.Incident{
+		LoanID:          loan.ID,
+		ToolkitID:       toolkit.ID,
+		UserID:          loan.UserID,
+		LocationID:      loan.LocationID,
+		Type:            incidentType,
+		Quantity:        quantity,
+		Description:     description,
+		Status:          models.IncidentOpen,
+		ReplacementCost: toolkit.PurchasePrice * float64(quantity),
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+// fakeEvidenceRepo satu incident: dipinjam user 7, dilaporkan user 8
+type fakeEvidenceRepo struct {
+	repositories.IncidentRepository
+	added int
+}
+
+func (r *fakeEvidenceRepo) GetByID(_ context.Context, id int) (*models.Incident, error) {
+	reporter := 8
+	return &models.Incident{ID: id, LoanID: 1, UserID: 7, ReportedBy: &reporter}, nil
+}
+
+func (r *fakeEvidenceRepo) AddEvidence(_ context.Context, evidence *models.IncidentEvidence) (*models.IncidentEvidence, error) {
+	r.added++
+	return evidence, nil
+}
+
+func TestAddEvidenceOnlyStaffReporterOrBorrower(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   auth.JWTClaim
+		allowed bool
+	}{
+		{name: "borrower", actor: auth.JWTClaim{UserID: 7, Role: "user"}, allowed: true},
+		{name: "reporter", actor: auth.JWTClaim{UserID: 8, Role: "user"}, allowed: true},
+		{name: "technician", actor: auth.JWTClaim{UserID: 9, Role: "technician"}, allowed: true},
+		{name: "other user", actor: auth.JWTClaim{UserID: 10, Role: "user"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEvidenceRepo{}
+			svc := NewIncidentService(repo, nil, nil)
+
+			_, err := svc.AddEvidence(context.Background(), 1,
+				&models.IncidentEvidenceRequest{URL: "https://files.example/foto.jpg"}, &tt.actor)
+			if tt.allowed {
+				if err != nil || repo.added != 1 {
+					t.Fatalf("err = %v, added = %d; want evidence stored", err, repo.added)
+				}
+				return
+			}
+			if !errors.Is(err, ErrForbidden) || repo.added != 0 {
+				t.Fatalf("err = %v, added = %d; want forbidden", err, repo.added)
+			}
+		})
+	}
+}
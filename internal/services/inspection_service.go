@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+type InspectionService interface {
+	GetChecklist(ctx context.Context, categoryID int) ([]*models.ChecklistItem, error)
+	CreateChecklistItem(ctx context.Context, categoryID int, req *models.ChecklistItemCreateRequest) (*models.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, id int, req *models.ChecklistItemUpdateRequest) (*models.ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, id int) error
+
+	Create(ctx context.Context, loanID int, req *models.InspectionCreateRequest, actor *auth.JWTClaim) (*models.Inspection, error)
+	GetByID(ctx context.Context, id int) (*models.Inspection, error)
+	GetAll(ctx context.Context, filter *models.InspectionFilterRequest) ([]*models.Inspection, error)
+	Review(ctx context.Context, id int, req *models.InspectionReviewRequest, actor *auth.JWTClaim) (*models.Inspection, error)
+}
+
+// InspectionPolicy kapan inspeksi wajib di siklus peminjaman
+type InspectionPolicy struct {
+	// Required loan hanya bisa dikembalikan setelah inspeksi checkout dicatat, dan toolkit tidak
+	// bisa dipinjam lagi selama masih ada pengembalian yang belum diinspeksi
+	Required bool
+}
+
+// inspectionGate terapkan InspectionPolicy di checkout dan return (loan, scan, bundle)
+type inspectionGate struct {
+	repo   repositories.InspectionRepository
+	policy InspectionPolicy
+}
+
+// checkout tolak peminjaman toolkit yang unit kembaliannya belum diinspeksi
+func (g inspectionGate) checkout(ctx context.Context, toolkitID int) error {
+	if !g.policy.Required {
+		return nil
+	}
+	pending, err := g.repo.CountPendingReturns(ctx, toolkitID)
+	if err != nil {
+		return translateError(err, "inspection")
+	}
+	if pending > 0 {
+		return NewConflictError("return_inspection_pending",
+			fmt.Sprintf("%d returned loans of this toolkit still need a return inspection", pending))
+	}
+	return nil
+}
+
+// returned tolak pengembalian loan yang belum punya inspeksi checkout
+func (g inspectionGate) returned(ctx context.Context, loanID int) error {
+	if !g.policy.Required {
+		return nil
+	}
+	_, err := g.repo.GetByLoanAndKind(ctx, loanID, models.InspectionCheckout)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(translateError(err, "inspection"), ErrNotFound) {
+		return NewConflictError("inspection_required",
+			fmt.Sprintf("loan %d needs a checkout inspection before it can be returned", loanID))
+	}
+	return translateError(err, "inspection")
+}
+
+type inspectionService struct {
+	repo         repositories.InspectionRepository
+	loanRepo     repositories.LoanRepository
+	toolkitRepo  repositories.ToolkitRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewInspectionService(repo repositories.InspectionRepository, loanRepo repositories.LoanRepository,
+	toolkitRepo repositories.ToolkitRepository, categoryRepo repositories.CategoryRepository) InspectionService {
+	return &inspectionService{repo: repo, loanRepo: loanRepo, toolkitRepo: toolkitRepo, categoryRepo: categoryRepo}
+}
+
+func (s *inspectionService) GetChecklist(ctx context.Context, categoryID int) ([]*models.ChecklistItem, error) {
+	if _, err := s.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return nil, translateError(err, "category")
+	}
+	items, err := s.repo.GetChecklist(ctx, categoryID, false)
+	if err != nil {
+		return nil, translateError(err, "checklist item")
+	}
+	return items, nil
+}
+
+func (s *inspectionService) CreateChecklistItem(ctx context.Context, categoryID int, req *models.ChecklistItemCreateRequest) (*models.ChecklistItem, error) {
+	if _, err := s.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return nil, translateError(err, "category")
+	}
+
+	item := &models.ChecklistItem{
+		CategoryID:  categoryID,
+		Label:       strings.TrimSpace(req.Label),
+		Description: req.Description,
+		SortOrder:   req.SortOrder,
+		IsActive:    true,
+	}
+	if item.Label == "" {
+		return nil, NewValidationError("label is required", FieldError{Field: "label", Message: "is required"})
+	}
+
+	result, err := s.repo.CreateChecklistItem(ctx, item)
+	if err != nil {
+		return nil, translateError(err, "checklist item")
+	}
+	return result, nil
+}
+
+func (s *inspectionService) UpdateChecklistItem(ctx context.Context, id int, req *models.ChecklistItemUpdateRequest) (*models.ChecklistItem, error) {
+	item, err := s.repo.GetChecklistItem(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "checklist item")
+	}
+
+	if label := strings.TrimSpace(req.Label); label != "" {
+		item.Label = label
+	}
+	if req.Description != "" {
+		item.Description = req.Description
+	}
+	if req.SortOrder != nil {
+		item.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+
+	result, err := s.repo.UpdateChecklistItem(ctx, item)
+	if err != nil {
+		return nil, translateError(err, "checklist item")
+	}
+	return result, nil
+}
+
+func (s *inspectionService) DeleteChecklistItem(ctx context.Context, id int) error {
+	return translateError(s.repo.DeleteChecklistItem(ctx, id), "checklist item")
+}
+
+func (s *inspectionService) Create(ctx context.Context, loanID int, req *models.InspectionCreateRequest, actor *auth.JWTClaim) (*models.Inspection, error) {
+	loan, err := s.loanRepo.GetByID(ctx, loanID)
+	if err != nil {
+		return nil, translateError(err, "loan")
+	}
+	if req.Kind == models.InspectionCheckout && loan.Status == "returned" {
+		return nil, NewConflictError("loan_already_returned", "checkout inspection is not possible after the loan was returned")
+	}
+	// Inspeksi return mengubah kondisi toolkit, jadi hanya setelah unit benar-benar kembali
+	if req.Kind == models.InspectionReturn && loan.Status != "returned" {
+		return nil, NewConflictError("loan_not_returned", "return inspection is only possible after the loan was returned")
+	}
+
+	if _, err := s.repo.GetByLoanAndKind(ctx, loan.ID, req.Kind); err == nil {
+		return nil, NewConflictError("inspection_exists",
+			fmt.Sprintf("%s inspection for this loan was already recorded", req.Kind))
+	} else if !errors.Is(translateError(err, "inspection"), ErrNotFound) {
+		return nil, translateError(err, "inspection")
+	}
+
+	toolkit, err := s.toolkitRepo.GetByID(ctx, loan.ToolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	checklist, err := s.repo.GetChecklist(ctx, toolkit.CategoryID, true)
+	if err != nil {
+		return nil, translateError(err, "checklist item")
+	}
+
+	results, err := checklistResults(checklist, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &models.Inspection{
+		LoanID:      loan.ID,
+		ToolkitID:   toolkit.ID,
+		Kind:        req.Kind,
+		Condition:   req.Condition,
+		Passed:      true,
+		InspectorID: actor.UserID,
+		Notes:       req.Notes,
+		Results:     results,
+	}
+	for _, result := range results {
+		if !result.Passed {
+			inspection.Passed = false
+		}
+	}
+
+	if req.Kind == models.InspectionReturn {
+		needsReview, err := s.needsReview(ctx, loan.ID, toolkit.Condition, inspection)
+		if err != nil {
+			return nil, err
+		}
+		inspection.NeedsReview = needsReview
+	}
+
+	if _, err := s.repo.Create(ctx, inspection); err != nil {
+		if errors.Is(err, repositories.ErrLoanChanged) {
+			return nil, NewConflictError("loan_changed", "loan was changed by another request, reload and try again")
+		}
+		return nil, translateError(err, "inspection")
+	}
+	return s.GetByID(ctx, inspection.ID)
+}
+
+// needsReview bandingkan inspeksi return dengan inspeksi checkout; tanpa inspeksi checkout,
+// pembanding kondisi adalah kondisi toolkit saat ini dan setiap item gagal dianggap baru
+func (s *inspectionService) needsReview(ctx context.Context, loanID int, currentCondition string, inspection *models.Inspection) (bool, error) {
+	baseline := currentCondition
+	passedAtCheckout := map[int]bool{}
+
+	checkout, err := s.repo.GetByLoanAndKind(ctx, loanID, models.InspectionCheckout)
+	switch {
+	case err == nil:
+		baseline = checkout.Condition
+		for _, result := range checkout.Results {
+			passedAtCheckout[result.ChecklistItemID] = result.Passed
+		}
+	case !errors.Is(translateError(err, "inspection"), ErrNotFound):
+		return false, translateError(err, "inspection")
+	}
+
+	if models.ConditionDropped(baseline, inspection.Condition) {
+		return true, nil
+	}
+	for _, result := range inspection.Results {
+		passed, known := passedAtCheckout[result.ChecklistItemID]
+		if !result.Passed && (passed || !known) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *inspectionService) GetByID(ctx context.Context, id int) (*models.Inspection, error) {
+	inspection, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "inspection")
+	}
+	return inspection, nil
+}
+
+func (s *inspectionService) GetAll(ctx context.Context, filter *models.InspectionFilterRequest) ([]*models.Inspection, error) {
+	inspections, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "inspection")
+	}
+	return inspections, nil
+}
+
+func (s *inspectionService) Review(ctx context.Context, id int, req *models.InspectionReviewRequest, actor *auth.JWTClaim) (*models.Inspection, error) {
+	inspection, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !inspection.NeedsReview {
+		return nil, NewConflictError("review_not_required", "inspection is not flagged for review")
+	}
+	if inspection.ReviewedAt != nil {
+		return nil, NewConflictError("already_reviewed", "inspection was already reviewed")
+	}
+
+	if err := s.repo.MarkReviewed(ctx, id, actor.UserID, time.Now(), req.Notes); err != nil {
+		return nil, translateError(err, "inspection")
+	}
+	return s.GetByID(ctx, id)
+}
+
+// checklistResults setiap item aktif wajib dijawab tepat satu kali
+func checklistResults(checklist []*models.ChecklistItem, items []models.InspectionItemRequest) ([]models.InspectionResult, error) {
+	answers := make(map[int]models.InspectionItemRequest, len(items))
+	var fields []FieldError
+	for i, item := range items {
+		if _, dup := answers[item.ChecklistItemID]; dup {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].checklist_item_id", i), Message: "answered more than once"})
+			continue
+		}
+		answers[item.ChecklistItemID] = item
+	}
+
+	results := make([]models.InspectionResult, 0, len(checklist))
+	for _, entry := range checklist {
+		answer, ok := answers[entry.ID]
+		if !ok {
+			fields = append(fields, FieldError{Field: "items", Message: fmt.Sprintf("missing checklist item %d (%s)", entry.ID, entry.Label)})
+			continue
+		}
+		delete(answers, entry.ID)
+		results = append(results, models.InspectionResult{
+			ChecklistItemID: entry.ID,
+			Label:           entry.Label,
+			Passed:          answer.Passed,
+			Notes:           answer.Notes,
+		})
+	}
+	for i, item := range items {
+		if _, unknown := answers[item.ChecklistItemID]; unknown {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].checklist_item_id", i), Message: "not an active checklist item of this toolkit's category"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, NewValidationError("inspection checklist incomplete or invalid", fields...)
+	}
+	return results, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+// fakeLoanRepo loan di memori; Update mencatat perubahan tanpa menyentuh stok
+type fakeLoanRepo struct {
+	repositories.LoanRepository
+	loans   map[int]*models.Loan
+	updates int
+}
+
+func (r *fakeLoanRepo) GetByID(_ context.Context, id int) (*models.Loan, error) {
+	loan, ok := r.loans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *loan
+	return &copied, nil
+}
+
+func (r *fakeLoanRepo) Update(_ context.Context, loan *models.Loan, _ repositories.LoanStockChange) (*models.Loan, error) {
+	r.updates++
+	copied := *loan
+	r.loans[loan.ID] = &copied
+	return loan, nil
+}
+
+type fakeToolkitRepo struct {
+	repositories.ToolkitRepository
+	toolkit *models.Toolkit
+}
+
+func (r fakeToolkitRepo) GetByID(context.Context, int) (*models.Toolkit, error) {
+	copied := *r.toolkit
+	return &copied, nil
+}
+
+// fakeInspectionRepo inspeksi per loan dan jenis, checklist kategori kosong
+type fakeInspectionRepo struct {
+	repositories.InspectionRepository
+	inspections    map[int]map[string]*models.Inspection
+	pendingReturns int64
+	created        []*models.Inspection
+}
+
+func (r *fakeInspectionRepo) GetByLoanAndKind(_ context.Context, loanID int, kind string) (*models.Inspection, error) {
+	if inspection, ok := r.inspections[loanID][kind]; ok {
+		return inspection, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeInspectionRepo) GetChecklist(context.Context, int, bool) ([]*models.ChecklistItem, error) {
+	return nil, nil
+}
+
+func (r *fakeInspectionRepo) Create(_ context.Context, inspection *models.Inspection) (*models.Inspection, error) {
+	r.created = append(r.created, inspection)
+	return inspection, nil
+}
+
+func (r *fakeInspectionRepo) GetByID(_ context.Context, id int) (*models.Inspection, error) {
+	return &models.Inspection{ID: id}, nil
+}
+
+func (r *fakeInspectionRepo) CountPendingReturns(context.Context, int) (int64, error) {
+	return r.pendingReturns, nil
+}
+
+func newInspectionFixture(status string) (*fakeLoanRepo, *fakeInspectionRepo, fakeToolkitRepo) {
+	loans := &fakeLoanRepo{loans: map[int]*models.Loan{
+		1: {ID: 1, UserID: 7, ToolkitID: 3, Quantity: 1, Status: status},
+	}}
+	inspections := &fakeInspectionRepo{inspections: map[int]map[string]*models.Inspection{}}
+	toolkits := fakeToolkitRepo{toolkit: &models.Toolkit{ID: 3, CategoryID: 2, Quantity: 5, Available: 4, Condition: "good"}}
+	return loans, inspections, toolkits
+}
+
+func TestInspectionCreateRejectsReturnBeforeLoanReturned(t *testing.T) {
+	for _, status := range []string{"borrowed", "overdue", "damaged"} {
+		t.Run(status, func(t *testing.T) {
+			loans, inspections, toolkits := newInspectionFixture(status)
+			svc := NewInspectionService(inspections, loans, toolkits, nil)
+
+			_, err := svc.Create(context.Background(), 1,
+				&models.InspectionCreateRequest{Kind: models.InspectionReturn, Condition: "poor"}, &auth.JWTClaim{UserID: 9})
+			if !errors.Is(err, ErrConflict) {
+				t.Fatalf("err = %v, want conflict", err)
+			}
+			if len(inspections.created) != 0 {
+				t.Error("return inspection stored while the unit is still out")
+			}
+		})
+	}
+}
+
+func TestInspectionCreateReturnAfterLoanReturned(t *testing.T) {
+	loans, inspections, toolkits := newInspectionFixture("returned")
+	svc := NewInspectionService(inspections, loans, toolkits, nil)
+
+	if _, err := svc.Create(context.Background(), 1,
+		&models.InspectionCreateRequest{Kind: models.InspectionReturn, Condition: "poor"}, &auth.JWTClaim{UserID: 9}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(inspections.created) != 1 || !inspections.created[0].NeedsReview {
+		t.Errorf("created = %+v, want one inspection flagged for review (good → poor)", inspections.created)
+	}
+}
+
+func TestLoanReturnRequiresCheckoutInspection(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		inspected  bool
+		wantReturn bool
+	}{
+		{name: "not required", required: false, wantReturn: true},
+		{name: "required without inspection", required: true},
+		{name: "required with inspection", required: true, inspected: true, wantReturn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans, inspections, toolkits := newInspectionFixture("borrowed")
+			if tt.inspected {
+				inspections.inspections[1] = map[string]*models.Inspection{
+					models.InspectionCheckout: {LoanID: 1, Kind: models.InspectionCheckout},
+				}
+			}
+			svc := NewLoanService(loans, toolkits, nil, nil, inspections, InspectionPolicy{Required: tt.required})
+
+			_, err := svc.Update(context.Background(), 1, &models.LoanUpdateRequest{Status: "returned"})
+			if tt.wantReturn {
+				if err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+				if loans.loans[1].Status != "returned" {
+					t.Errorf("status = %q, want returned", loans.loans[1].Status)
+				}
+				return
+			}
+			if !errors.Is(err, ErrConflict) {
+				t.Fatalf("err = %v, want conflict", err)
+			}
+			if loans.updates != 0 {
+				t.Error("loan returned without a checkout inspection")
+			}
+		})
+	}
+}
+
+func TestLoanCheckoutBlockedByPendingReturnInspection(t *testing.T) {
+	loans, inspections, toolkits := newInspectionFixture("borrowed")
+	inspections.pendingReturns = 1
+	svc := NewLoanService(loans, toolkits, nil, nil, inspections, InspectionPolicy{Required: true})
+
+	_, err := svc.Create(context.Background(), &models.LoanCreateRequest{UserID: 7, ToolkitID: 3, Quantity: 1})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/label"
+)
+
+const (
+	// maxBatchLabels batas total label (termasuk copies) dalam satu PDF
+	maxBatchLabels = 1000
+	// maxBatchPixels batas total pixel label unik dalam satu PDF. Copies hanya dirender sekali,
+	// jadi yang dihitung toolkit berbeda x ukuran label x DPI². 150 juta pixel cukup untuk
+	// 200 label 62x29 pada 300 DPI atau 200 label 102x152 pada 150 DPI.
+	maxBatchPixels = 150_000_000
+)
+
+type LabelService interface {
+	// Toolkit label satu toolkit sebagai PNG atau PDF
+	Toolkit(ctx context.Context, id int, req *models.LabelRequest) ([]byte, error)
+	// Batch banyak label dalam satu PDF
+	Batch(ctx context.Context, req *models.LabelBatchRequest) ([]byte, error)
+}
+
+type labelService struct {
+	toolkitRepo repositories.ToolkitRepository
+}
+
+func NewLabelService(toolkitRepo repositories.ToolkitRepository) LabelService {
+	return &labelService{toolkitRepo: toolkitRepo}
+}
+
+func (s *labelService) Toolkit(ctx context.Context, id int, req *models.LabelRequest) ([]byte, error) {
+	opts, err := labelOptions(&req.LabelOptions)
+	if err != nil {
+		return nil, err
+	}
+	toolkits, err := s.toolkitRepo.GetByIDs(ctx, []int{id})
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	if len(toolkits) == 0 {
+		return nil, NewNotFoundError("toolkit")
+	}
+	item, err := labelItem(&toolkits[0], req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if req.Format == "pdf" {
+		err = label.WritePDF(&buf, []label.Item{item}, opts, label.LayoutRoll)
+	} else {
+		err = label.WritePNG(&buf, item, opts)
+	}
+	if err != nil {
+		return nil, labelError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *labelService) Batch(ctx context.Context, req *models.LabelBatchRequest) ([]byte, error) {
+	opts, err := labelOptions(&req.LabelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(req.Items))
+	distinct := make(map[int]bool, len(req.Items))
+	total := 0
+	for _, entry := range req.Items {
+		ids = append(ids, entry.ToolkitID)
+		distinct[entry.ToolkitID] = true
+		total += max(entry.Copies, 1)
+	}
+	if total > maxBatchLabels {
+		return nil, NewValidationError(fmt.Sprintf("at most %d labels per batch", maxBatchLabels),
+			FieldError{Field: "items", Message: fmt.Sprintf("total copies must be at most %d", maxBatchLabels)})
+	}
+	if len(distinct)*opts.PixelCount() > maxBatchPixels {
+		return nil, NewValidationError("batch is too large to render at this size and DPI",
+			FieldError{Field: "items", Message: fmt.Sprintf("at most %d different toolkits at this size and dpi; lower the dpi, choose a smaller label or split the batch",
+				maxBatchPixels/opts.PixelCount())})
+	}
+
+	toolkits, err := s.toolkitRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	byID := make(map[int]*models.Toolkit, len(toolkits))
+	for i := range toolkits {
+		byID[toolkits[i].ID] = &toolkits[i]
+	}
+
+	// Urutan label mengikuti urutan items di request
+	items := make([]label.Item, 0, total)
+	for i, entry := range req.Items {
+		toolkit, ok := byID[entry.ToolkitID]
+		if !ok {
+			return nil, NewValidationError("toolkit not found",
+				FieldError{Field: fmt.Sprintf("items[%d].toolkit_id", i), Message: "does not exist"})
+		}
+		item, err := labelItem(toolkit, req.Content)
+		if err != nil {
+			return nil, err
+		}
+		for c := 0; c < max(entry.Copies, 1); c++ {
+			items = append(items, item)
+		}
+	}
+
+	layout := req.Layout
+	if layout == "" {
+		layout = label.LayoutRoll
+	}
+	var buf bytes.Buffer
+	if err := label.WritePDF(&buf, items, opts, layout); err != nil {
+		return nil, labelError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// labelOptions isi default: preset 62x29, QR, 300 DPI
+func labelOptions(req *models.LabelOptions) (label.Options, error) {
+	opts := label.Options{DPI: req.DPI, Symbology: req.Symbology}
+	if opts.DPI == 0 {
+		opts.DPI = label.DefaultDPI
+	}
+	if opts.Symbology == "" {
+		opts.Symbology = label.SymbologyQR
+	}
+
+	switch {
+	case req.WidthMM > 0 && req.HeightMM > 0:
+		opts.Size = label.Size{WidthMM: req.WidthMM, HeightMM: req.HeightMM}
+	case req.WidthMM > 0 || req.HeightMM > 0:
+		return opts, NewValidationError("width_mm and height_mm must be set together",
+			FieldError{Field: "height_mm", Message: "is required when width_mm is set"})
+	default:
+		name := req.Size
+		if name == "" {
+			name = label.DefaultPreset
+		}
+		size, ok := label.Presets[name]
+		if !ok {
+			return opts, NewValidationError("unknown label size",
+				FieldError{Field: "size", Message: "must be one of: " + strings.Join(label.PresetNames(), " ")})
+		}
+		opts.Size = size
+	}
+	return opts, nil
+}
+
+func labelItem(toolkit *models.Toolkit, content string) (label.Item, error) {
+	code := toolkit.SKU
+	if content == models.LabelContentSerialNumber {
+		code = toolkit.SerialNumber
+		if code == "" {
+			return label.Item{}, NewValidationError(fmt.Sprintf("toolkit %s has no serial number", toolkit.SKU),
+				FieldError{Field: "content", Message: "toolkit has no serial number"})
+		}
+	}
+	return label.Item{Code: code, Title: toolkit.Name, Subtitle: toolkit.Category.Name}, nil
+}
+
+// labelError kode yang tidak muat atau tidak bisa di-encode adalah kesalahan input; error lain
+// (menulis PDF/PNG) dikembalikan apa adanya sebagai error server
+func labelError(err error) error {
+	switch {
+	case errors.Is(err, label.ErrTooSmall):
+		return NewValidationError("label is too small for the code at this size and DPI",
+			FieldError{Field: "size", Message: "choose a larger label, a higher dpi or the qr symbology"})
+	case errors.Is(err, label.ErrPageTooSmall):
+		return NewValidationError("label does not fit on an A4 sheet",
+			FieldError{Field: "size", Message: "choose a smaller label or the roll layout"})
+	case errors.Is(err, label.ErrEncode):
+		return NewValidationError("label could not be rendered: " + err.Error())
+	}
+	return err
+}
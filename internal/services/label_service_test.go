@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+)
+
+// fakeLabelRepo setiap id yang diminta ada sebagai toolkit
+type fakeLabelRepo struct {
+	repositories.ToolkitRepository
+}
+
+func (fakeLabelRepo) GetByIDs(_ context.Context, ids []int) ([]models.Toolkit, error) {
+	toolkits := make([]models.Toolkit, 0, len(ids))
+	for _, id := range ids {
+		toolkits = append(toolkits, models.Toolkit{ID: id, Name: "Kunci", SKU: fmt.Sprintf("LBL-%d", id)})
+	}
+	return toolkits, nil
+}
+
+func TestBatchRejectsTooManyPixels(t *testing.T) {
+	service := NewLabelService(fakeLabelRepo{})
+	batch := func(distinct, copies int, size string, dpi int) *models.LabelBatchRequest {
+		req := &models.LabelBatchRequest{LabelOptions: models.LabelOptions{Size: size, DPI: dpi}}
+		for i := 1; i <= distinct; i++ {
+			req.Items = append(req.Items, models.LabelBatchItem{ToolkitID: i, Copies: copies})
+		}
+		return req
+	}
+
+	if _, err := service.Batch(context.Background(), batch(200, 1, "102x152", 600)); !errors.Is(err, ErrValidation) {
+		t.Errorf("200 labels 102x152 at 600 dpi: err = %v, want validation error", err)
+	}
+	// Copies tidak dihitung karena hanya dirender sekali
+	if _, err := service.Batch(context.Background(), batch(2, 100, "102x152", 600)); err != nil {
+		t.Errorf("2 toolkits x 100 copies: %v", err)
+	}
+}
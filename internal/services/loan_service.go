@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"toolkit-management/internal/models"
@@ -9,31 +11,39 @@ import (
 )
 
 type LoanService interface {
-	Create(req *models.LoanCreateRequest) (*models.Loan, error)
-	GetByID(id int) (*models.Loan, error)
-	GetAll(filter *models.LoanFilterRequest) ([]*models.Loan, error)
-	Update(id int, req *models.LoanUpdateRequest) (*models.Loan, error)
-	Delete(id int) error
+	Create(ctx context.Context, req *models.LoanCreateRequest) (*models.Loan, error)
+	GetByID(ctx context.Context, id int) (*models.Loan, error)
+	GetAll(ctx context.Context, filter *models.LoanFilterRequest) ([]*models.Loan, error)
+	Update(ctx context.Context, id int, req *models.LoanUpdateRequest) (*models.Loan, error)
+	Delete(ctx context.Context, id int) error
 }
 
 type loanService struct {
-	repo        repositories.LoanRepository
-	toolkitRepo repositories.ToolkitRepository
+	repo         repositories.LoanRepository
+	toolkitRepo  repositories.ToolkitRepository
+	incidentRepo repositories.IncidentRepository
+	locationRepo repositories.LocationRepository
+	inspections  inspectionGate
 }
 
-func NewLoanService(repo repositories.LoanRepository, toolkitRepo repositories.ToolkitRepository) LoanService {
-	return &loanService{repo: repo, toolkitRepo: toolkitRepo}
+func NewLoanService(repo repositories.LoanRepository, toolkitRepo repositories.ToolkitRepository, incidentRepo repositories.IncidentRepository,
+	locationRepo repositories.LocationRepository, inspectionRepo repositories.InspectionRepository, inspectionPolicy InspectionPolicy) LoanService {
+	return &loanService{repo: repo, toolkitRepo: toolkitRepo, incidentRepo: incidentRepo, locationRepo: locationRepo,
+		inspections: inspectionGate{repo: inspectionRepo, policy: inspectionPolicy}}
 }
 
-func (s *loanService) Create(req *models.LoanCreateRequest) (*models.Loan, error) {
+func (s *loanService) Create(ctx context.Context, req *models.LoanCreateRequest) (*models.Loan, error) {
 	// Check toolkit availability
-	toolkit, err := s.toolkitRepo.GetByID(req.ToolkitID)
+	toolkit, err := s.toolkitRepo.GetByID(ctx, req.ToolkitID)
 	if err != nil {
-		return nil, errors.New("toolkit not found")
+		return nil, translateError(err, "toolkit")
 	}
 
 	if toolkit.Available < req.Quantity {
-		return nil, errors.New("insufficient toolkit quantity available")
+		return nil, NewInsufficientStockError(toolkit.Available, req.Quantity)
+	}
+	if err := s.inspections.checkout(ctx, toolkit.ID); err != nil {
+		return nil, err
 	}
 
 	// Create loan
@@ -48,46 +58,45 @@ func (s *loanService) Create(req *models.LoanCreateRequest) (*models.Loan, error
 		ApprovedBy:       req.ApprovedBy,
 		Notes:            req.Notes,
 		ConditionChecked: req.ConditionChecked,
+		LocationID:       req.LocationID,
 	}
 
-	// Create loan
-	createdLoan, err := s.repo.Create(loan)
+	// Stok toolkit dan lokasi asal berkurang dalam transaksi yang sama dengan loan
+	createdLoan, err := s.repo.Create(ctx, loan)
 	if err != nil {
-		return nil, err
-	}
-
-	// Update toolkit availability
-	toolkit.Available -= req.Quantity
-	if toolkit.Available == 0 {
-		toolkit.Status = "borrowed"
-	}
-	_, err = s.toolkitRepo.Update(toolkit)
-	if err != nil {
-		//delete loan if toolkit update fails
-		_ = s.repo.Delete(createdLoan.ID)
-		return nil, errors.New("failed to update toolkit availability")
+		if errors.Is(err, repositories.ErrInsufficientAvailable) {
+			return nil, s.stockError(ctx, toolkit.ID, req.LocationID, req.Quantity)
+		}
+		return nil, translateError(err, "loan")
 	}
-
 	return createdLoan, nil
 }
 
-func (s *loanService) GetByID(id int) (*models.Loan, error) {
-	return s.repo.GetByID(id)
+func (s *loanService) GetByID(ctx context.Context, id int) (*models.Loan, error) {
+	loan, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "loan")
+	}
+	return loan, nil
 }
 
-func (s *loanService) GetAll(filter *models.LoanFilterRequest) ([]*models.Loan, error) {
-	return s.repo.GetAll(filter)
+func (s *loanService) GetAll(ctx context.Context, filter *models.LoanFilterRequest) ([]*models.Loan, error) {
+	loans, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "loan")
+	}
+	return loans, nil
 }
 
-func (s *loanService) Update(id int, req *models.LoanUpdateRequest) (*models.Loan, error) {
-	loan, err := s.repo.GetByID(id)
+func (s *loanService) Update(ctx context.Context, id int, req *models.LoanUpdateRequest) (*models.Loan, error) {
+	loan, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	toolkit, err := s.toolkitRepo.GetByID(loan.ToolkitID)
+	toolkit, err := s.toolkitRepo.GetByID(ctx, loan.ToolkitID)
 	if err != nil {
-		return nil, errors.New("toolkit not found")
+		return nil, translateError(err, "toolkit")
 	}
 
 	// Handle status changes and qty updates
@@ -131,47 +140,115 @@ func (s *loanService) Update(id int, req *models.LoanUpdateRequest) (*models.Loa
 		loan.ConditionReturn = req.ConditionReturn
 	}
 
+	// Loan damaged dengan incident terbuka hanya ditutup lewat resolusi incident
+	if oldStatus == "damaged" && loan.Status != "damaged" {
+		if err := ensureNoOpenIncident(ctx, s.incidentRepo, loan.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Stok toolkit dan lokasi berubah dalam transaksi yang sama dengan update loan
+	change := repositories.LoanStockChange{PrevStatus: oldStatus}
+
 	// Handle qty and availability updates
 	if oldStatus != "returned" && loan.Status == "returned" {
-		// Item is being returned
-		toolkit.Available += loan.Quantity
-		if toolkit.Available == toolkit.Quantity {
-			toolkit.Status = "available"
-		} else if toolkit.Available > 0 {
-			toolkit.Status = "available"
+		if err := s.inspections.returned(ctx, loan.ID); err != nil {
+			return nil, err
 		}
+		// Item is being returned, default ke lokasi asal
+		change.Release = loan.Quantity
+		change.From, change.To = loan.LocationID, loan.LocationID
+		if req.ReturnLocationID != nil {
+			change.To = req.ReturnLocationID
+		}
+		loan.ReturnLocationID = change.To
 	} else if oldStatus == "returned" && loan.Status != "returned" {
 		if toolkit.Available < loan.Quantity {
-			return nil, errors.New("insufficient toolkit quantity available")
+			return nil, NewInsufficientStockError(toolkit.Available, loan.Quantity)
 		}
-		toolkit.Available -= loan.Quantity
-		if toolkit.Available == 0 {
-			toolkit.Status = "borrowed"
+		if err := s.inspections.checkout(ctx, toolkit.ID); err != nil {
+			return nil, err
 		}
+		// Dipinjam lagi dari lokasi tempat unit dikembalikan
+		change.Take = loan.Quantity
+		loan.LocationID, loan.ReturnLocationID = loan.ReturnLocationID, nil
 	} else if oldQuantity != loan.Quantity && oldStatus != "returned" {
 		// update qty if borowed
 		quantityDiff := loan.Quantity - oldQuantity
 		if quantityDiff > 0 && toolkit.Available < quantityDiff {
-			return nil, errors.New("insufficient toolkit quantity available")
+			return nil, NewInsufficientStockError(toolkit.Available, quantityDiff)
 		}
-		toolkit.Available -= quantityDiff
-		if toolkit.Available == 0 {
-			toolkit.Status = "borrowed"
-		} else if toolkit.Available > 0 {
-			toolkit.Status = "available"
+		if quantityDiff > 0 {
+			change.Take = quantityDiff
+		} else {
+			change.Release = -quantityDiff
+			change.From, change.To = loan.LocationID, loan.LocationID
 		}
 	}
 
-	// Update toolkit
-	_, err = s.toolkitRepo.Update(toolkit)
-	if err != nil {
-		return nil, errors.New("failed to update toolkit availability")
+	// Status damaged tanpa incident: buat incident supaya stok tertahan sampai diselesaikan
+	if oldStatus != "damaged" && loan.Status == "damaged" {
+		switch err := ensureNoOpenIncident(ctx, s.incidentRepo, loan.ID); {
+		case err == nil:
+			description := loan.Notes
+			if description == "" {
+				description = "Loan marked as damaged"
+			}
+			change.Incident = newIncident(loan, toolkit, models.IncidentDamaged, loan.Quantity, description)
+		case !errors.Is(err, ErrConflict):
+			return nil, err
+		}
 	}
 
 	// Update loan
-	return s.repo.Update(loan)
+	result, err := s.repo.Update(ctx, loan, change)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrInsufficientAvailable):
+			return nil, s.stockError(ctx, toolkit.ID, loan.LocationID, change.Take)
+		case errors.Is(err, repositories.ErrLoanChanged):
+			return nil, NewConflictError("loan_changed", "loan was changed by another request, reload and try again")
+		}
+		return nil, translateError(err, "loan")
+	}
+	return result, nil
+}
+
+// stockError jelaskan kenapa stok tidak cukup setelah update bersyarat gagal. Tanpa lokasi,
+// unit hanya boleh diambil dari stok yang belum ditempatkan di lokasi mana pun.
+func (s *loanService) stockError(ctx context.Context, toolkitID int, locationID *int, quantity int) error {
+	if locationID == nil {
+		unassigned, err := s.locationRepo.Unassigned(ctx, toolkitID)
+		if err != nil {
+			return translateError(err, "toolkit")
+		}
+		if unassigned.Available < quantity {
+			return NewValidationError(
+				fmt.Sprintf("only %d units are not assigned to a location", max(unassigned.Available, 0)),
+				FieldError{Field: "location_id", Message: "is required when stock is held at locations"},
+			)
+		}
+		return NewConflictError("insufficient_quantity", "toolkit units are no longer available")
+	}
+
+	available := 0
+	stocks, err := s.locationRepo.ToolkitStocks(ctx, toolkitID)
+	if err != nil {
+		return translateError(err, "location")
+	}
+	for _, stock := range stocks {
+		if stock.LocationID == *locationID {
+			available = stock.Available
+		}
+	}
+	if available >= quantity {
+		return NewConflictError("insufficient_quantity", "toolkit units are no longer available")
+	}
+	e := NewInsufficientStockError(available, quantity)
+	e.Message = fmt.Sprintf("insufficient stock at location (available %d, requested %d)", available, quantity)
+	return e
 }
 
-func (s *loanService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *loanService) Delete(ctx context.Context, id int) error {
+	return translateError(s.repo.Delete(ctx, id), "loan")
 }
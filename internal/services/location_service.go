@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+type LocationService interface {
+	Create(ctx context.Context, req *models.LocationCreateRequest) (*models.Location, error)
+	GetByID(ctx context.Context, id int) (*models.Location, error)
+	GetAll(ctx context.Context, filter *models.LocationFilterRequest) ([]*models.Location, error)
+	Update(ctx context.Context, id int, req *models.LocationUpdateRequest) (*models.Location, error)
+	Delete(ctx context.Context, id int) error
+
+	// Stock stok per toolkit di lokasi, opsional termasuk semua sub-lokasi
+	Stock(ctx context.Context, id int, filter *models.LocationStockFilterRequest) ([]models.LocationStockLine, error)
+	// SetStock tempatkan unit toolkit di lokasi (hasil stock opname / penataan gudang)
+	SetStock(ctx context.Context, id, toolkitID int, req *models.LocationStockRequest, actor *auth.JWTClaim) (*models.ToolkitStock, error)
+	// ToolkitAvailability stok toolkit per lokasi dan totalnya
+	ToolkitAvailability(ctx context.Context, toolkitID int) (*models.ToolkitAvailability, error)
+}
+
+type locationService struct {
+	repo        repositories.LocationRepository
+	toolkitRepo repositories.ToolkitRepository
+}
+
+func NewLocationService(repo repositories.LocationRepository, toolkitRepo repositories.ToolkitRepository) LocationService {
+	return &locationService{repo: repo, toolkitRepo: toolkitRepo}
+}
+
+// locationRank urutan hierarki: warehouse > shelf > bin
+var locationRank = map[string]int{
+	models.LocationWarehouse: 0,
+	models.LocationShelf:     1,
+	models.LocationBin:       2,
+}
+
+func (s *locationService) Create(ctx context.Context, req *models.LocationCreateRequest) (*models.Location, error) {
+	if err := s.checkParent(ctx, 0, req.Type, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	location := &models.Location{
+		Name:        strings.TrimSpace(req.Name),
+		Code:        strings.TrimSpace(req.Code),
+		Type:        req.Type,
+		ParentID:    req.ParentID,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if _, err := s.repo.Create(ctx, location); err != nil {
+		return nil, translateError(err, "location")
+	}
+	return location, nil
+}
+
+func (s *locationService) GetByID(ctx context.Context, id int) (*models.Location, error) {
+	location, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "location")
+	}
+	return location, nil
+}
+
+func (s *locationService) GetAll(ctx context.Context, filter *models.LocationFilterRequest) ([]*models.Location, error) {
+	locations, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "location")
+	}
+	return locations, nil
+}
+
+func (s *locationService) Update(ctx context.Context, id int, req *models.LocationUpdateRequest) (*models.Location, error) {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		location.Name = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		location.Code = code
+	}
+	if req.Description != "" {
+		location.Description = req.Description
+	}
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	if req.Type != "" || req.ParentID != nil {
+		if req.Type != "" {
+			location.Type = req.Type
+		}
+		if req.ParentID != nil {
+			location.ParentID = req.ParentID
+		}
+		if err := s.checkParent(ctx, id, location.Type, location.ParentID); err != nil {
+			return nil, err
+		}
+		// Sub-lokasi yang ada harus tetap lebih sempit dari jenis baru
+		for _, child := range location.Children {
+			if locationRank[child.Type] <= locationRank[location.Type] {
+				return nil, NewValidationError("location has children that cannot be placed under this type",
+					FieldError{Field: "type", Message: fmt.Sprintf("child %s is a %s", child.Code, child.Type)})
+			}
+		}
+	}
+
+	if _, err := s.repo.Update(ctx, location); err != nil {
+		return nil, translateError(err, "location")
+	}
+	return location, nil
+}
+
+func (s *locationService) Delete(ctx context.Context, id int) error {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(location.Children) > 0 {
+		return NewConflictError("location_has_children", "location still contains other locations")
+	}
+	stocked, err := s.repo.HasStock(ctx, id)
+	if err != nil {
+		return translateError(err, "location")
+	}
+	if stocked {
+		return NewConflictError("location_has_stock", "location still holds toolkit stock")
+	}
+	return translateError(s.repo.Delete(ctx, id), "location")
+}
+
+func (s *locationService) Stock(ctx context.Context, id int, filter *models.LocationStockFilterRequest) ([]models.LocationStockLine, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.Stock(ctx, id, filter)
+	if err != nil {
+		return nil, translateError(err, "location")
+	}
+	return lines, nil
+}
+
+func (s *locationService) SetStock(ctx context.Context, id, toolkitID int, req *models.LocationStockRequest, actor *auth.JWTClaim) (*models.ToolkitStock, error) {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !location.IsActive && req.Quantity > 0 {
+		return nil, NewConflictError("location_inactive", "location is deactivated and cannot receive stock")
+	}
+
+	stock, err := s.repo.SetStock(ctx, toolkitID, id, req.Quantity, actor.UserID)
+	switch {
+	case errors.Is(err, repositories.ErrStockExceedsTotal):
+		return nil, NewValidationError("placed stock would exceed the toolkit quantity",
+			FieldError{Field: "quantity", Message: "exceeds the units not placed at other locations"})
+	case errors.Is(err, repositories.ErrInsufficientAvailable):
+		return nil, NewValidationError("units on loan or not available cannot be placed or removed",
+			FieldError{Field: "quantity", Message: "must cover units on loan from this location and not exceed available unassigned units"})
+	case err != nil:
+		return nil, translateError(err, "toolkit")
+	}
+	stock.Location = location
+	return stock, nil
+}
+
+func (s *locationService) ToolkitAvailability(ctx context.Context, toolkitID int) (*models.ToolkitAvailability, error) {
+	toolkit, err := s.toolkitRepo.GetByID(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	stocks, err := s.repo.ToolkitStocks(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "location")
+	}
+	inTransit, err := s.repo.InTransit(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+
+	availability := &models.ToolkitAvailability{
+		ToolkitID: toolkit.ID,
+		SKU:       toolkit.SKU,
+		Total:     models.StockLevel{Quantity: toolkit.Quantity, Available: toolkit.Available},
+		Unassigned: models.StockLevel{
+			Quantity:  toolkit.Quantity,
+			Available: toolkit.Available,
+		},
+		InTransit: inTransit,
+		Locations: stocks,
+	}
+	availability.Unassigned.Quantity -= inTransit
+	for _, stock := range stocks {
+		availability.Unassigned.Quantity -= stock.Quantity
+		availability.Unassigned.Available -= stock.Available
+	}
+	// Unit maintenance/incident mengurangi Toolkit.Available tanpa tahu lokasinya
+	availability.Unassigned.Available = max(availability.Unassigned.Available, 0)
+	return availability, nil
+}
+
+// checkParent root harus warehouse, lokasi lain harus di bawah jenis yang lebih luas.
+// Karena rank selalu naik ke bawah, hierarki tidak mungkin membentuk siklus.
+func (s *locationService) checkParent(ctx context.Context, id int, locationType string, parentID *int) error {
+	if parentID == nil {
+		if locationType != models.LocationWarehouse {
+			return NewValidationError("only warehouses can be top-level locations",
+				FieldError{Field: "parent_id", Message: "is required for " + locationType})
+		}
+		return nil
+	}
+	if *parentID == id {
+		return NewValidationError("location cannot be its own parent",
+			FieldError{Field: "parent_id", Message: "must be another location"})
+	}
+
+	parent, err := s.repo.GetByID(ctx, *parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NewValidationError("parent location not found",
+				FieldError{Field: "parent_id", Message: "must be an existing location"})
+		}
+		return translateError(err, "location")
+	}
+	if locationRank[parent.Type] >= locationRank[locationType] {
+		return NewValidationError(fmt.Sprintf("a %s cannot be placed under a %s", locationType, parent.Type),
+			FieldError{Field: "parent_id", Message: "must be a broader location type"})
+	}
+	return nil
+}
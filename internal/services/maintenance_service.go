@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+const defaultDueWindowDays = 30
+
+type MaintenanceService interface {
+	Create(ctx context.Context, toolkitID int, req *models.MaintenanceCreateRequest, actor *auth.JWTClaim) (*models.MaintenanceRecord, error)
+	GetByID(ctx context.Context, id int) (*models.MaintenanceRecord, error)
+	GetAll(ctx context.Context, filter *models.MaintenanceFilterRequest) ([]*models.MaintenanceRecord, error)
+	Start(ctx context.Context, id int, actor *auth.JWTClaim) (*models.MaintenanceRecord, error)
+	Complete(ctx context.Context, id int, req *models.MaintenanceCompleteRequest) (*models.MaintenanceRecord, error)
+	Cancel(ctx context.Context, id int, req *models.MaintenanceCancelRequest) (*models.MaintenanceRecord, error)
+	Due(ctx context.Context, req *models.MaintenanceDueRequest) (*models.MaintenanceDueResponse, error)
+
+	CreateSchedule(ctx context.Context, toolkitID int, req *models.MaintenanceScheduleCreateRequest, actor *auth.JWTClaim) (*models.MaintenanceSchedule, error)
+	GetSchedules(ctx context.Context, toolkitID int) ([]*models.MaintenanceSchedule, error)
+	UpdateSchedule(ctx context.Context, id int, req *models.MaintenanceScheduleUpdateRequest) (*models.MaintenanceSchedule, error)
+	DeleteSchedule(ctx context.Context, id int) error
+}
+
+type maintenanceService struct {
+	repo        repositories.MaintenanceRepository
+	toolkitRepo repositories.ToolkitRepository
+	userRepo    repositories.UserRepository
+}
+
+func NewMaintenanceService(repo repositories.MaintenanceRepository, toolkitRepo repositories.ToolkitRepository, userRepo repositories.UserRepository) MaintenanceService {
+	return &maintenanceService{repo: repo, toolkitRepo: toolkitRepo, userRepo: userRepo}
+}
+
+func (s *maintenanceService) Create(ctx context.Context, toolkitID int, req *models.MaintenanceCreateRequest, actor *auth.JWTClaim) (*models.MaintenanceRecord, error) {
+	toolkit, err := s.toolkitRepo.GetByID(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity > toolkit.Quantity {
+		return nil, NewValidationError("quantity exceeds toolkit stock",
+			FieldError{Field: "quantity", Message: fmt.Sprintf("toolkit only has %d units", toolkit.Quantity)})
+	}
+	if err := s.checkTechnician(ctx, req.TechnicianID); err != nil {
+		return nil, err
+	}
+
+	scheduledDate := req.ScheduledDate
+	if scheduledDate.IsZero() {
+		scheduledDate = time.Now()
+	}
+
+	record := &models.MaintenanceRecord{
+		ToolkitID:     toolkit.ID,
+		Type:          req.Type,
+		Status:        models.MaintenanceScheduled,
+		Quantity:      quantity,
+		LocationID:    req.LocationID,
+		ScheduledDate: scheduledDate,
+		TechnicianID:  req.TechnicianID,
+		Notes:         req.Notes,
+		CreatedBy:     actor.UserID,
+	}
+	if _, err := s.repo.Create(ctx, record); err != nil {
+		return nil, translateError(err, "maintenance")
+	}
+
+	if req.StartNow {
+		return s.Start(ctx, record.ID, actor)
+	}
+	return s.GetByID(ctx, record.ID)
+}
+
+func (s *maintenanceService) GetByID(ctx context.Context, id int) (*models.MaintenanceRecord, error) {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "maintenance")
+	}
+	return record, nil
+}
+
+func (s *maintenanceService) GetAll(ctx context.Context, filter *models.MaintenanceFilterRequest) ([]*models.MaintenanceRecord, error) {
+	records, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "maintenance")
+	}
+	return records, nil
+}
+
+func (s *maintenanceService) Start(ctx context.Context, id int, actor *auth.JWTClaim) (*models.MaintenanceRecord, error) {
+	record, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.MaintenanceScheduled {
+		return nil, invalidTransition(record.Status, models.MaintenanceInProgress)
+	}
+
+	now := time.Now()
+	record.Status = models.MaintenanceInProgress
+	record.StartedAt = &now
+	if record.TechnicianID == nil {
+		record.TechnicianID = &actor.UserID
+	}
+
+	if err := s.repo.Start(ctx, record); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientAvailable) {
+			return nil, NewInsufficientStockError(record.Toolkit.Available, record.Quantity)
+		}
+		return nil, maintenanceError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Complete record scheduled boleh langsung diselesaikan (pekerjaan singkat tanpa menahan stok)
+func (s *maintenanceService) Complete(ctx context.Context, id int, req *models.MaintenanceCompleteRequest) (*models.MaintenanceRecord, error) {
+	record, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.MaintenanceScheduled && record.Status != models.MaintenanceInProgress {
+		return nil, invalidTransition(record.Status, models.MaintenanceCompleted)
+	}
+
+	now := time.Now()
+	prevStatus := record.Status
+	record.Status = models.MaintenanceCompleted
+	record.CompletedAt = &now
+	if record.StartedAt == nil {
+		record.StartedAt = &now
+	}
+	record.Cost = req.Cost
+	if req.Notes != "" {
+		record.Notes = req.Notes
+	}
+
+	next, err := s.nextOccurrence(ctx, record, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Close(ctx, record, prevStatus, req.Condition, next); err != nil {
+		return nil, maintenanceError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *maintenanceService) Cancel(ctx context.Context, id int, req *models.MaintenanceCancelRequest) (*models.MaintenanceRecord, error) {
+	record, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.MaintenanceScheduled && record.Status != models.MaintenanceInProgress {
+		return nil, invalidTransition(record.Status, models.MaintenanceCancelled)
+	}
+
+	prevStatus := record.Status
+	record.Status = models.MaintenanceCancelled
+	if req.Notes != "" {
+		record.Notes = req.Notes
+	}
+
+	// Jadwal tetap berjalan, dihitung dari tanggal yang dibatalkan supaya tidak bergeser
+	next, err := s.nextOccurrence(ctx, record, record.ScheduledDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Close(ctx, record, prevStatus, "", next); err != nil {
+		return nil, maintenanceError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *maintenanceService) Due(ctx context.Context, req *models.MaintenanceDueRequest) (*models.MaintenanceDueResponse, error) {
+	within := req.WithinDays
+	if within == 0 {
+		within = defaultDueWindowDays
+	}
+
+	now := time.Now()
+	records, err := s.repo.GetDue(ctx, now.AddDate(0, 0, within))
+	if err != nil {
+		return nil, translateError(err, "maintenance")
+	}
+
+	response := &models.MaintenanceDueResponse{
+		Overdue:  []models.MaintenanceRecord{},
+		Upcoming: []models.MaintenanceRecord{},
+	}
+	for _, record := range records {
+		if record.ScheduledDate.Before(now) {
+			response.Overdue = append(response.Overdue, record)
+		} else {
+			response.Upcoming = append(response.Upcoming, record)
+		}
+	}
+	return response, nil
+}
+
+func (s *maintenanceService) CreateSchedule(ctx context.Context, toolkitID int, req *models.MaintenanceScheduleCreateRequest, actor *auth.JWTClaim) (*models.MaintenanceSchedule, error) {
+	toolkit, err := s.toolkitRepo.GetByID(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity > toolkit.Quantity {
+		return nil, NewValidationError("quantity exceeds toolkit stock",
+			FieldError{Field: "quantity", Message: fmt.Sprintf("toolkit only has %d units", toolkit.Quantity)})
+	}
+	if err := s.checkTechnician(ctx, req.TechnicianID); err != nil {
+		return nil, err
+	}
+
+	dueDate := time.Now().AddDate(0, 0, req.IntervalDays)
+	if req.FirstDueDate != nil {
+		dueDate = *req.FirstDueDate
+	}
+
+	schedule := &models.MaintenanceSchedule{
+		ToolkitID:    toolkit.ID,
+		Type:         req.Type,
+		IntervalDays: req.IntervalDays,
+		Quantity:     quantity,
+		LocationID:   req.LocationID,
+		NextDueDate:  dueDate,
+		TechnicianID: req.TechnicianID,
+		Notes:        req.Notes,
+		IsActive:     true,
+	}
+	first := scheduledRecord(schedule, dueDate)
+	first.CreatedBy = actor.UserID
+
+	if _, err := s.repo.CreateSchedule(ctx, schedule, first); err != nil {
+		return nil, translateError(err, "maintenance schedule")
+	}
+	return schedule, nil
+}
+
+func (s *maintenanceService) GetSchedules(ctx context.Context, toolkitID int) ([]*models.MaintenanceSchedule, error) {
+	if _, err := s.toolkitRepo.GetByID(ctx, toolkitID); err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	schedules, err := s.repo.GetSchedulesByToolkit(ctx, toolkitID)
+	if err != nil {
+		return nil, translateError(err, "maintenance schedule")
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule perubahan interval berlaku mulai siklus berikutnya
+func (s *maintenanceService) UpdateSchedule(ctx context.Context, id int, req *models.MaintenanceScheduleUpdateRequest) (*models.MaintenanceSchedule, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "maintenance schedule")
+	}
+
+	if req.IntervalDays != 0 {
+		schedule.IntervalDays = req.IntervalDays
+	}
+	if req.Quantity != 0 {
+		toolkit, err := s.toolkitRepo.GetByID(ctx, schedule.ToolkitID)
+		if err != nil {
+			return nil, translateError(err, "toolkit")
+		}
+		if req.Quantity > toolkit.Quantity {
+			return nil, NewValidationError("quantity exceeds toolkit stock",
+				FieldError{Field: "quantity", Message: fmt.Sprintf("toolkit only has %d units", toolkit.Quantity)})
+		}
+		schedule.Quantity = req.Quantity
+	}
+	if req.TechnicianID != nil {
+		if err := s.checkTechnician(ctx, req.TechnicianID); err != nil {
+			return nil, err
+		}
+		schedule.TechnicianID = req.TechnicianID
+	}
+	if req.Notes != "" {
+		schedule.Notes = req.Notes
+	}
+
+	var pending *models.MaintenanceRecord
+	if req.IsActive != nil && *req.IsActive != schedule.IsActive {
+		schedule.IsActive = *req.IsActive
+		if !schedule.IsActive {
+			if err := s.repo.CancelPending(ctx, schedule.ID); err != nil {
+				return nil, translateError(err, "maintenance")
+			}
+		} else {
+			open, err := s.repo.HasOpenRecord(ctx, schedule.ID)
+			if err != nil {
+				return nil, translateError(err, "maintenance")
+			}
+			if !open {
+				// Diaktifkan lagi: jadwal yang terlewat selama nonaktif langsung jatuh tempo hari ini
+				if schedule.NextDueDate.Before(time.Now()) {
+					schedule.NextDueDate = time.Now()
+				}
+				pending = scheduledRecord(schedule, schedule.NextDueDate)
+			}
+		}
+	}
+
+	if _, err := s.repo.UpdateSchedule(ctx, schedule, pending); err != nil {
+		return nil, translateError(err, "maintenance schedule")
+	}
+	return schedule, nil
+}
+
+func (s *maintenanceService) DeleteSchedule(ctx context.Context, id int) error {
+	if _, err := s.repo.GetScheduleByID(ctx, id); err != nil {
+		return translateError(err, "maintenance schedule")
+	}
+	if err := s.repo.DeleteSchedule(ctx, id); err != nil {
+		return translateError(err, "maintenance schedule")
+	}
+	return nil
+}
+
+// nextOccurrence record berikutnya untuk jadwal aktif, nil kalau record bukan dari jadwal
+func (s *maintenanceService) nextOccurrence(ctx context.Context, record *models.MaintenanceRecord, from time.Time) (*models.MaintenanceRecord, error) {
+	if record.ScheduleID == nil {
+		return nil, nil
+	}
+	schedule, err := s.repo.GetScheduleByID(ctx, *record.ScheduleID)
+	if err != nil {
+		return nil, translateError(err, "maintenance schedule")
+	}
+	if !schedule.IsActive {
+		return nil, nil
+	}
+	next := scheduledRecord(schedule, from.AddDate(0, 0, schedule.IntervalDays))
+	next.CreatedBy = record.CreatedBy
+	return next, nil
+}
+
+func (s *maintenanceService) checkTechnician(ctx context.Context, technicianID *int) error {
+	if technicianID == nil {
+		return nil
+	}
+	user, err := s.userRepo.GetByID(ctx, *technicianID)
+	if err != nil {
+		if errors.Is(translateError(err, "user"), ErrNotFound) {
+			return NewValidationError("technician not found",
+				FieldError{Field: "technician_id", Message: "does not exist"})
+		}
+		return translateError(err, "user")
+	}
+	if !user.IsActive || (user.Role != "technician" && user.Role != "admin") {
+		return NewValidationError("user cannot be assigned as technician",
+			FieldError{Field: "technician_id", Message: "must be an active technician or admin"})
+	}
+	return nil
+}
+
+func scheduledRecord(schedule *models.MaintenanceSchedule, dueDate time.Time) *models.MaintenanceRecord {
+	return &models.MaintenanceRecord{
+		ToolkitID:     schedule.ToolkitID,
+		ScheduleID:    &schedule.ID,
+		Type:          schedule.Type,
+		Status:        models.MaintenanceScheduled,
+		Quantity:      schedule.Quantity,
+		LocationID:    schedule.LocationID,
+		ScheduledDate: dueDate,
+		TechnicianID:  schedule.TechnicianID,
+		Notes:         schedule.Notes,
+	}
+}
+
+func invalidTransition(from, to string) error {
+	return NewConflictError("invalid_maintenance_status",
+		fmt.Sprintf("cannot change maintenance from %s to %s", from, to))
+}
+
+// maintenanceError record diubah request lain di antara baca dan update bersyarat
+func maintenanceError(err error) error {
+	if errors.Is(err, repositories.ErrMaintenanceChanged) {
+		return NewConflictError("maintenance_changed", "maintenance record was changed by another request, reload and try again")
+	}
+	return translateError(err, "maintenance")
+}
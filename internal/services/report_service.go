@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+)
+
+type ReportService interface {
+	Valuation(ctx context.Context, req *models.ValuationRequest) (*models.ValuationReport, error)
+}
+
+type reportService struct {
+	repo repositories.ReportRepository
+}
+
+func NewReportService(repo repositories.ReportRepository) ReportService {
+	return &reportService{repo: repo}
+}
+
+func (s *reportService) Valuation(ctx context.Context, req *models.ValuationRequest) (*models.ValuationReport, error) {
+	asOf := time.Now()
+	if req.AsOf != nil {
+		// Tanggal tanpa jam dianggap akhir hari
+		asOf = req.AsOf.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	periodStart := time.Date(asOf.Year(), time.January, 1, 0, 0, 0, 0, asOf.Location())
+	if req.PeriodStart != nil {
+		periodStart = *req.PeriodStart
+	}
+	if periodStart.After(asOf) {
+		return nil, NewValidationError("period_start must not be after as_of",
+			FieldError{Field: "period_start", Message: "must not be after as_of"})
+	}
+
+	toolkits, err := s.repo.GetValuedToolkits(ctx, asOf, req.CategoryID)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	adjustments, err := s.repo.GetAdjustmentsSince(ctx, periodStart)
+	if err != nil {
+		return nil, translateError(err, "stock adjustment")
+	}
+	byToolkit := make(map[int][]models.StockAdjustment)
+	for _, adjustment := range adjustments {
+		byToolkit[adjustment.ToolkitID] = append(byToolkit[adjustment.ToolkitID], adjustment)
+	}
+
+	report := &models.ValuationReport{
+		AsOf:        asOf,
+		PeriodStart: periodStart,
+		Toolkits:    []models.ToolkitValuation{},
+		Categories:  []models.ValuationTotals{},
+	}
+	categoryIndex := make(map[int]int)
+
+	for _, toolkit := range toolkits {
+		if toolkit.PurchaseDate == nil || toolkit.PurchasePrice <= 0 {
+			report.Unvalued++
+			continue
+		}
+		row := valueToolkit(toolkit, byToolkit[toolkit.ID], periodStart, asOf)
+		report.Toolkits = append(report.Toolkits, row)
+
+		idx, ok := categoryIndex[row.CategoryID]
+		if !ok {
+			idx = len(report.Categories)
+			categoryIndex[row.CategoryID] = idx
+			report.Categories = append(report.Categories, models.ValuationTotals{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+			})
+		}
+		addTotals(&report.Categories[idx], row)
+		addTotals(&report.Total, row)
+	}
+	return report, nil
+}
+
+func valueToolkit(toolkit models.Toolkit, adjustments []models.StockAdjustment, periodStart, asOf time.Time) models.ToolkitValuation {
+	method := toolkit.Category.DepreciationMethod
+	if method == "" {
+		method = models.DepreciationStraightLine
+	}
+	life := toolkit.Category.UsefulLifeMonths
+	if life <= 0 {
+		life = models.DefaultUsefulLifeMonths
+	}
+	salvage := toolkit.PurchasePrice * toolkit.Category.SalvagePercent / 100
+
+	// Quantity sekarang dikurangi perubahan setelah asOf = jumlah unit per asOf
+	units := toolkit.Quantity
+	row := models.ToolkitValuation{
+		ToolkitID:        toolkit.ID,
+		Name:             toolkit.Name,
+		SKU:              toolkit.SKU,
+		CategoryID:       toolkit.CategoryID,
+		CategoryName:     toolkit.Category.Name,
+		Method:           method,
+		UsefulLifeMonths: life,
+		PurchaseDate:     toolkit.PurchaseDate,
+		UnitCost:         toolkit.PurchasePrice,
+	}
+	for _, adjustment := range adjustments {
+		if adjustment.CreatedAt.After(asOf) {
+			units -= adjustment.QuantityChange
+			continue
+		}
+		if adjustment.QuantityChange > 0 && adjustment.Reason == models.AdjustmentAcquisition {
+			row.UnitsAcquired += adjustment.QuantityChange
+		}
+		if adjustment.QuantityChange < 0 && isWriteOff(adjustment.Reason) {
+			written := -adjustment.QuantityChange
+			months := monthsBetween(*toolkit.PurchaseDate, adjustment.CreatedAt)
+			row.UnitsWrittenOff += written
+			row.WrittenOffBookValue += float64(written) * depreciatedValue(toolkit.PurchasePrice, salvage, method, life, months)
+		}
+	}
+	if units < 0 {
+		units = 0
+	}
+	if !toolkit.PurchaseDate.Before(periodStart) {
+		row.UnitsAcquired = units
+	}
+	row.AcquiredInPeriod = row.UnitsAcquired > 0
+	row.AcquiredCost = roundMoney(toolkit.PurchasePrice * float64(row.UnitsAcquired))
+
+	unitBook := depreciatedValue(toolkit.PurchasePrice, salvage, method, life, monthsBetween(*toolkit.PurchaseDate, asOf))
+	row.Units = units
+	row.Cost = roundMoney(toolkit.PurchasePrice * float64(units))
+	row.BookValue = roundMoney(unitBook * float64(units))
+	row.AccumulatedDepreciation = roundMoney(row.Cost - row.BookValue)
+	row.WrittenOffBookValue = roundMoney(row.WrittenOffBookValue)
+	return row
+}
+
+// depreciatedValue nilai buku per unit setelah months bulan, tidak pernah di bawah salvage.
+// Declining balance memakai double-declining rate 2/umur per bulan dan habis ke salvage di akhir umur.
+func depreciatedValue(cost, salvage float64, method string, life, months int) float64 {
+	if months <= 0 {
+		return cost
+	}
+	if months >= life {
+		return salvage
+	}
+
+	var value float64
+	switch method {
+	case models.DepreciationDecliningBalance:
+		value = cost * math.Pow(1-2/float64(life), float64(months))
+	default:
+		value = cost - (cost-salvage)*float64(months)/float64(life)
+	}
+	return math.Max(value, salvage)
+}
+
+// monthsBetween jumlah bulan penuh dari from sampai to
+func monthsBetween(from, to time.Time) int {
+	if to.Before(from) {
+		return 0
+	}
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return months
+}
+
+func isWriteOff(reason string) bool {
+	switch reason {
+	case models.ResolutionWrittenOff, models.ResolutionChargedToDepartment, models.AdjustmentWriteOff:
+		return true
+	}
+	return false
+}
+
+func addTotals(totals *models.ValuationTotals, row models.ToolkitValuation) {
+	totals.Toolkits++
+	totals.Units += row.Units
+	totals.Cost = roundMoney(totals.Cost + row.Cost)
+	totals.AccumulatedDepreciation = roundMoney(totals.AccumulatedDepreciation + row.AccumulatedDepreciation)
+	totals.BookValue = roundMoney(totals.BookValue + row.BookValue)
+	totals.UnitsAcquired += row.UnitsAcquired
+	totals.AcquiredCost = roundMoney(totals.AcquiredCost + row.AcquiredCost)
+	totals.UnitsWrittenOff += row.UnitsWrittenOff
+	totals.WrittenOffBookValue = roundMoney(totals.WrittenOffBookValue + row.WrittenOffBookValue)
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"toolkit-management/internal/models"
+)
+
+func TestValueToolkitUsesManualStockChanges(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 12, 0, 0, 0, time.UTC) }
+	purchased := day(time.January, 1).AddDate(-1, 0, 0)
+	toolkit := models.Toolkit{
+		ID: 1, Quantity: 12, PurchaseDate: &purchased, PurchasePrice: 100,
+		Category: models.Category{UsefulLifeMonths: 60},
+	}
+	// Quantity sekarang 12: 10 awal, +4 dibeli Maret, -1 dihapus April, -1 dihapus setelah asOf
+	adjustments := []models.StockAdjustment{
+		{ToolkitID: 1, QuantityChange: 4, Reason: models.AdjustmentAcquisition, CreatedAt: day(time.March, 1)},
+		{ToolkitID: 1, QuantityChange: -1, Reason: models.AdjustmentWriteOff, CreatedAt: day(time.April, 1)},
+		{ToolkitID: 1, QuantityChange: -1, Reason: models.AdjustmentWriteOff, CreatedAt: day(time.July, 1)},
+	}
+
+	row := valueToolkit(toolkit, adjustments, day(time.January, 1), day(time.June, 30))
+	if row.Units != 13 {
+		t.Errorf("Units = %d, want 13 as of June", row.Units)
+	}
+	if !row.AcquiredInPeriod || row.UnitsAcquired != 4 || row.AcquiredCost != 400 {
+		t.Errorf("acquired = %v, %d units, %.2f; want 4 units for 400", row.AcquiredInPeriod, row.UnitsAcquired, row.AcquiredCost)
+	}
+	if row.UnitsWrittenOff != 1 {
+		t.Errorf("UnitsWrittenOff = %d, want 1 (the July write-off is after as_of)", row.UnitsWrittenOff)
+	}
+
+	row = valueToolkit(toolkit, adjustments, day(time.January, 1), day(time.February, 1))
+	if row.Units != 10 || row.AcquiredInPeriod {
+		t.Errorf("February: units %d, acquired %v; want 10 units and nothing acquired", row.Units, row.AcquiredInPeriod)
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+)
+
+// defaultScanPurpose purpose loan kalau scanner tidak mengirim apa-apa
+const defaultScanPurpose = "Checkout via scanner"
+
+// LoanPolicy default peminjaman untuk checkout lewat scanner
+type LoanPolicy struct {
+	DefaultDuration time.Duration
+	// RoleDurations override lama pinjam per role peminjam
+	RoleDurations map[string]time.Duration
+	// MaxActiveLoans 0 = tanpa batas
+	MaxActiveLoans int
+	BlockOverdue   bool
+}
+
+// DueDate due date default untuk peminjam dengan role tersebut
+func (p LoanPolicy) DueDate(role string, from time.Time) time.Time {
+	if d, ok := p.RoleDurations[role]; ok {
+		return from.Add(d)
+	}
+	return from.Add(p.DefaultDuration)
+}
+
+type ScanService interface {
+	Checkout(ctx context.Context, actor string, req *models.ScanCheckoutRequest) (*models.ScanResult, error)
+	Return(ctx context.Context, req *models.ScanReturnRequest) (*models.ScanResult, error)
+}
+
+type scanService struct {
+	loanService LoanService
+	loanRepo    repositories.LoanRepository
+	toolkitRepo repositories.ToolkitRepository
+	userRepo    repositories.UserRepository
+	policy      LoanPolicy
+}
+
+func NewScanService(loanService LoanService, loanRepo repositories.LoanRepository, toolkitRepo repositories.ToolkitRepository,
+	userRepo repositories.UserRepository, policy LoanPolicy) ScanService {
+	return &scanService{
+		loanService: loanService,
+		loanRepo:    loanRepo,
+		toolkitRepo: toolkitRepo,
+		userRepo:    userRepo,
+		policy:      policy,
+	}
+}
+
+func (s *scanService) Checkout(ctx context.Context, actor string, req *models.ScanCheckoutRequest) (*models.ScanResult, error) {
+	toolkit, err := s.item(ctx, req.ItemCode)
+	if err != nil {
+		return nil, err
+	}
+	if toolkit.Status == "maintenance" || toolkit.Status == "retired" {
+		return nil, NewConflictError("item_unavailable",
+			fmt.Sprintf("%s is %s and cannot be checked out", toolkit.SKU, toolkit.Status))
+	}
+
+	borrower, err := s.borrower(ctx, req.Borrower)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.eligible(ctx, borrower); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dueDate := s.policy.DueDate(borrower.Role, now)
+	if req.DueDate != nil {
+		if !req.DueDate.After(now) {
+			return nil, NewValidationError("due date must be in the future",
+				FieldError{Field: "due_date", Message: "must be in the future"})
+		}
+		dueDate = *req.DueDate
+	}
+	quantity := max(req.Quantity, 1)
+	purpose := strings.TrimSpace(req.Purpose)
+	if purpose == "" {
+		purpose = defaultScanPurpose
+	}
+
+	loan, err := s.loanService.Create(ctx, &models.LoanCreateRequest{
+		UserID:           borrower.ID,
+		ToolkitID:        toolkit.ID,
+		Quantity:         quantity,
+		Purpose:          purpose,
+		DueDate:          dueDate,
+		ApprovedBy:       actor,
+		Notes:            req.Notes,
+		ConditionChecked: req.ConditionChecked,
+		LocationID:       req.LocationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.result(ctx, models.ScanActionCheckout, loan, toolkit, borrower)
+	result.Message = fmt.Sprintf("%d x %s checked out to %s, due %s",
+		loan.Quantity, toolkit.SKU, borrower.Username, loan.DueDate.Format("2006-01-02"))
+	return result, nil
+}
+
+func (s *scanService) Return(ctx context.Context, req *models.ScanReturnRequest) (*models.ScanResult, error) {
+	toolkit, err := s.item(ctx, req.ItemCode)
+	if err != nil {
+		return nil, err
+	}
+	borrower, err := s.borrower(ctx, req.Borrower)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.loanRepo.GetActiveByUser(ctx, borrower.ID)
+	if err != nil {
+		return nil, translateError(err, "loan")
+	}
+	// Loan terlama ditutup dulu supaya yang overdue tidak tertinggal
+	var open *models.Loan
+	for _, loan := range active {
+		if loan.ToolkitID == toolkit.ID {
+			open = loan
+			break
+		}
+	}
+	if open == nil {
+		return nil, NewConflictError("no_active_loan",
+			fmt.Sprintf("%s has no active loan of %s", borrower.Username, toolkit.SKU))
+	}
+
+	now := time.Now()
+	overdue := open.Status == "overdue" || now.After(open.DueDate)
+	loan, err := s.loanService.Update(ctx, open.ID, &models.LoanUpdateRequest{
+		Status:           "returned",
+		ReturnDate:       &now,
+		ConditionReturn:  req.ConditionReturn,
+		Notes:            req.Notes,
+		ReturnLocationID: req.LocationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.result(ctx, models.ScanActionReturn, loan, toolkit, borrower)
+	result.Overdue = overdue
+	result.Message = fmt.Sprintf("%d x %s returned by %s", loan.Quantity, toolkit.SKU, borrower.Username)
+	if overdue {
+		result.Message += " (overdue)"
+	}
+	return result, nil
+}
+
+// item cari toolkit dari kode hasil scan. SKU diutamakan, serial number dipakai kalau tidak ada SKU yang cocok.
+func (s *scanService) item(ctx context.Context, code string) (*models.Toolkit, error) {
+	code = strings.TrimSpace(code)
+	toolkits, err := s.toolkitRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	for i := range toolkits {
+		if strings.EqualFold(toolkits[i].SKU, code) {
+			return &toolkits[i], nil
+		}
+	}
+	switch len(toolkits) {
+	case 0:
+		notFound := NewNotFoundError("item")
+		notFound.Message = fmt.Sprintf("no toolkit with SKU or serial number %q", code)
+		return nil, notFound
+	case 1:
+		return &toolkits[0], nil
+	default:
+		return nil, NewConflictError("ambiguous_item_code",
+			fmt.Sprintf("serial number %q matches more than one toolkit, scan the SKU instead", code))
+	}
+}
+
+// borrower cari peminjam dari nomor badge, lalu username, lalu email
+func (s *scanService) borrower(ctx context.Context, identifier string) (*models.User, error) {
+	identifier = strings.TrimSpace(identifier)
+	lookups := []func(context.Context, string) (*models.User, error){
+		s.userRepo.GetByBadgeNumber,
+		s.userRepo.GetByUsername,
+	}
+	if strings.Contains(identifier, "@") {
+		lookups = append(lookups, s.userRepo.GetByEmail)
+	}
+	for _, lookup := range lookups {
+		user, err := lookup(ctx, identifier)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, translateError(err, "user")
+		}
+	}
+	notFound := NewNotFoundError("borrower")
+	notFound.Message = fmt.Sprintf("no user with badge, username or email %q", identifier)
+	return nil, notFound
+}
+
+// eligible cek status akun dan loan aktif peminjam terhadap kebijakan
+func (s *scanService) eligible(ctx context.Context, borrower *models.User) error {
+	if !borrower.IsActive {
+		return NewConflictError("borrower_inactive",
+			fmt.Sprintf("%s is deactivated and cannot borrow", borrower.Username))
+	}
+
+	active, err := s.loanRepo.GetActiveByUser(ctx, borrower.ID)
+	if err != nil {
+		return translateError(err, "loan")
+	}
+	if s.policy.BlockOverdue {
+		now := time.Now()
+		for _, loan := range active {
+			if loan.Status == "overdue" || now.After(loan.DueDate) {
+				return NewConflictError("borrower_has_overdue_loans",
+					fmt.Sprintf("%s has overdue loans (%s due %s) and must return them first",
+						borrower.Username, loan.Toolkit.SKU, loan.DueDate.Format("2006-01-02")))
+			}
+		}
+	}
+	if s.policy.MaxActiveLoans > 0 && len(active) >= s.policy.MaxActiveLoans {
+		return NewConflictError("borrower_loan_limit_reached",
+			fmt.Sprintf("%s already has %d active loans (limit %d)", borrower.Username, len(active), s.policy.MaxActiveLoans))
+	}
+	return nil
+}
+
+func (s *scanService) result(ctx context.Context, action string, loan *models.Loan, toolkit *models.Toolkit, borrower *models.User) *models.ScanResult {
+	result := &models.ScanResult{
+		Action:    action,
+		LoanID:    loan.ID,
+		SKU:       toolkit.SKU,
+		ItemName:  toolkit.Name,
+		Borrower:  borrower.FullName,
+		Quantity:  loan.Quantity,
+		DueDate:   loan.DueDate,
+		Available: toolkit.Available,
+	}
+	if result.Borrower == "" {
+		result.Borrower = borrower.Username
+	}
+	// Stok sesudah transaksi, kalau gagal dibaca cukup pakai nilai sebelum
+	if updated, err := s.toolkitRepo.GetByID(ctx, toolkit.ID); err == nil {
+		result.Available = updated.Available
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+)
+
+const defaultSearchLimit = 10
+
+type SearchService interface {
+	Search(ctx context.Context, req *models.SearchRequest, userID int, isAdmin bool) (*models.SearchResponse, error)
+}
+
+type searchService struct {
+	repo repositories.SearchRepository
+}
+
+func NewSearchService(repo repositories.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+func (s *searchService) Search(ctx context.Context, req *models.SearchRequest, userID int, isAdmin bool) (*models.SearchResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	toolkits, err := s.repo.SearchToolkits(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.SearchCategories(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// User biasa hanya bisa cari pinjaman miliknya sendiri, data user hanya untuk admin
+	loanOwner := userID
+	var users []models.SearchHit
+	if isAdmin {
+		loanOwner = 0
+		users, err = s.repo.SearchUsers(ctx, query, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	loans, err := s.repo.SearchLoans(ctx, query, loanOwner, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SearchResponse{
+		Query:      query,
+		Toolkits:   nonNilHits(toolkits),
+		Categories: nonNilHits(categories),
+		Users:      users,
+		Loans:      nonNilHits(loans),
+	}, nil
+}
+
+func nonNilHits(hits []models.SearchHit) []models.SearchHit {
+	if hits == nil {
+		return []models.SearchHit{}
+	}
+	return hits
+}
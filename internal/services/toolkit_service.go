@@ -1,28 +1,35 @@
 package services
 
 import (
+	"context"
+	"errors"
+
 	"toolkit-management/internal/models"
 	. "toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
 )
 
 type ToolkitService interface {
-	Create(req *models.ToolkitCreateRequest) (*models.Toolkit, error)
-	GetByID(id int) (*models.Toolkit, error)
-	GetAll(filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error)
-	Update(id int, req *models.ToolkitUpdateRequest) (*models.Toolkit, error)
-	Delete(id int) error
-	UpdateStock(id int, req *models.ToolkitStockUpdateRequest) (*models.Toolkit, error)
+	Create(ctx context.Context, req *models.ToolkitCreateRequest) (*models.Toolkit, error)
+	GetByID(ctx context.Context, id int) (*models.Toolkit, error)
+	GetAll(ctx context.Context, filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error)
+	// Update perubahan quantity dicatat seperti UpdateStock
+	Update(ctx context.Context, id int, req *models.ToolkitUpdateRequest, actor *auth.JWTClaim) (*models.Toolkit, error)
+	Delete(ctx context.Context, id int) error
+	// UpdateStock tambah atau kurangi unit, dicatat sebagai acquisition atau write_off
+	UpdateStock(ctx context.Context, id int, req *models.ToolkitStockUpdateRequest, actor *auth.JWTClaim) (*models.Toolkit, error)
 }
 
 type toolkitService struct {
 	toolkitRepo ToolkitRepository
+	attachments AttachmentService
 }
 
-func NewToolkitService(repo ToolkitRepository) ToolkitService {
-	return &toolkitService{toolkitRepo: repo}
+func NewToolkitService(repo ToolkitRepository, attachments AttachmentService) ToolkitService {
+	return &toolkitService{toolkitRepo: repo, attachments: attachments}
 }
 
-func (s *toolkitService) Create(req *models.ToolkitCreateRequest) (*models.Toolkit, error) {
+func (s *toolkitService) Create(ctx context.Context, req *models.ToolkitCreateRequest) (*models.Toolkit, error) {
 	toolkit := &models.Toolkit{
 		Name:          req.Name,
 		SKU:           req.SKU,
@@ -42,23 +49,46 @@ func (s *toolkitService) Create(req *models.ToolkitCreateRequest) (*models.Toolk
 		Notes:         req.Notes,
 	}
 
-	return s.toolkitRepo.Create(toolkit)
+	result, err := s.toolkitRepo.Create(ctx, toolkit)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	return result, nil
 }
 
-func (s *toolkitService) GetByID(id int) (*models.Toolkit, error) {
-	return s.toolkitRepo.GetByID(id)
+func (s *toolkitService) GetByID(ctx context.Context, id int) (*models.Toolkit, error) {
+	toolkit, err := s.toolkitRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	return toolkit, nil
 }
 
-func (s *toolkitService) GetAll(filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error) {
-	return s.toolkitRepo.GetAll(filter)
+func (s *toolkitService) GetAll(ctx context.Context, filter *models.ToolkitFilterRequest) (*models.ToolkitListResponse, error) {
+	result, err := s.toolkitRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	return result, nil
 }
 
-func (s *toolkitService) Update(id int, req *models.ToolkitUpdateRequest) (*models.Toolkit, error) {
-	toolkit, err := s.toolkitRepo.GetByID(id)
+func (s *toolkitService) Update(ctx context.Context, id int, req *models.ToolkitUpdateRequest, actor *auth.JWTClaim) (*models.Toolkit, error) {
+	toolkit, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if req.Quantity != 0 && req.Quantity != toolkit.Quantity {
+		adjusted, err := s.UpdateStock(ctx, id, &models.ToolkitStockUpdateRequest{
+			QuantityChange: req.Quantity - toolkit.Quantity,
+			Reason:         "quantity updated",
+		}, actor)
+		if err != nil {
+			return nil, err
+		}
+		toolkit.Quantity, toolkit.Available = adjusted.Quantity, adjusted.Available
+	}
+
 	if req.Name != "" {
 		toolkit.Name = req.Name
 	}
@@ -71,9 +101,6 @@ func (s *toolkitService) Update(id int, req *models.ToolkitUpdateRequest) (*mode
 	if req.CategoryID != 0 {
 		toolkit.CategoryID = req.CategoryID
 	}
-	if req.Quantity != 0 {
-		toolkit.Quantity = req.Quantity
-	}
 	if req.Unit != "" {
 		toolkit.Unit = req.Unit
 	}
@@ -105,28 +132,49 @@ func (s *toolkitService) Update(id int, req *models.ToolkitUpdateRequest) (*mode
 		toolkit.Notes = req.Notes
 	}
 
-	return s.toolkitRepo.Update(toolkit)
+	result, err := s.toolkitRepo.Update(ctx, toolkit)
+	if err != nil {
+		return nil, translateError(err, "toolkit")
+	}
+	return result, nil
 }
 
-func (s *toolkitService) Delete(id int) error {
-	return s.toolkitRepo.Delete(id)
+func (s *toolkitService) Delete(ctx context.Context, id int) error {
+	// Catat file dulu, baris attachment ikut terhapus (cascade) bersama toolkit
+	attachments, err := s.attachments.GetByToolkit(ctx, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := translateError(s.toolkitRepo.Delete(ctx, id), "toolkit"); err != nil {
+		return err
+	}
+	s.attachments.RemoveFiles(ctx, attachments)
+	return nil
 }
 
-func (s *toolkitService) UpdateStock(id int, req *models.ToolkitStockUpdateRequest) (*models.Toolkit, error) {
-	toolkit, err := s.toolkitRepo.GetByID(id)
-	if err != nil {
-		return nil, err
+func (s *toolkitService) UpdateStock(ctx context.Context, id int, req *models.ToolkitStockUpdateRequest, actor *auth.JWTClaim) (*models.Toolkit, error) {
+	reason := models.AdjustmentAcquisition
+	if req.QuantityChange < 0 {
+		reason = models.AdjustmentWriteOff
+	}
+	notes := req.Reason
+	if req.Notes != "" {
+		notes += ": " + req.Notes
 	}
 
-	toolkit.Quantity += req.QuantityChange
-	toolkit.Available += req.QuantityChange
-
-	if toolkit.Quantity < 0 {
-		toolkit.Quantity = 0
+	result, err := s.toolkitRepo.AdjustStock(ctx, &models.StockAdjustment{
+		ToolkitID:      id,
+		QuantityChange: req.QuantityChange,
+		Reason:         reason,
+		Notes:          notes,
+		CreatedBy:      &actor.UserID,
+	})
+	if errors.Is(err, ErrInsufficientAvailable) {
+		return nil, NewValidationError("units on loan, placed at a location or in transit cannot be removed",
+			FieldError{Field: "quantity_change", Message: "must not remove more than the available unassigned units"})
 	}
-	if toolkit.Available < 0 {
-		toolkit.Available = 0
+	if err != nil {
+		return nil, translateError(err, "toolkit")
 	}
-
-	return s.toolkitRepo.Update(toolkit)
+	return result, nil
 }
\ No newline at end of file
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+type TransferService interface {
+	Create(ctx context.Context, req *models.TransferCreateRequest, actor *auth.JWTClaim) (*models.Transfer, error)
+	GetByID(ctx context.Context, id int) (*models.Transfer, error)
+	GetAll(ctx context.Context, filter *models.TransferFilterRequest) ([]*models.Transfer, error)
+	// Update dan Delete hanya untuk transfer yang masih draft
+	Update(ctx context.Context, id int, req *models.TransferUpdateRequest) (*models.Transfer, error)
+	Delete(ctx context.Context, id int) error
+
+	// Dispatch kirim transfer: stok keluar dari lokasi asal dan jadi in_transit
+	Dispatch(ctx context.Context, id int, actor *auth.JWTClaim) (*models.Transfer, error)
+	// Receive terima transfer di lokasi tujuan, selisih jumlah dicatat sebagai discrepancy
+	Receive(ctx context.Context, id int, req *models.TransferReceiveRequest, actor *auth.JWTClaim) (*models.Transfer, error)
+}
+
+type transferService struct {
+	repo         repositories.TransferRepository
+	locationRepo repositories.LocationRepository
+	toolkitRepo  repositories.ToolkitRepository
+}
+
+func NewTransferService(repo repositories.TransferRepository, locationRepo repositories.LocationRepository, toolkitRepo repositories.ToolkitRepository) TransferService {
+	return &transferService{repo: repo, locationRepo: locationRepo, toolkitRepo: toolkitRepo}
+}
+
+func (s *transferService) Create(ctx context.Context, req *models.TransferCreateRequest, actor *auth.JWTClaim) (*models.Transfer, error) {
+	if err := s.checkLocations(ctx, req.SourceLocationID, req.DestinationLocationID); err != nil {
+		return nil, err
+	}
+	lines, err := s.lines(ctx, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		SourceLocationID:      req.SourceLocationID,
+		DestinationLocationID: req.DestinationLocationID,
+		Status:                models.TransferDraft,
+		Notes:                 req.Notes,
+		CreatedBy:             actor.UserID,
+		Lines:                 lines,
+	}
+	if _, err := s.repo.Create(ctx, transfer); err != nil {
+		return nil, translateError(err, "transfer")
+	}
+	return s.GetByID(ctx, transfer.ID)
+}
+
+func (s *transferService) GetByID(ctx context.Context, id int) (*models.Transfer, error) {
+	transfer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "transfer")
+	}
+	transfer.HasDiscrepancy = hasDiscrepancy(transfer)
+	return transfer, nil
+}
+
+func (s *transferService) GetAll(ctx context.Context, filter *models.TransferFilterRequest) ([]*models.Transfer, error) {
+	transfers, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "transfer")
+	}
+	for _, transfer := range transfers {
+		transfer.HasDiscrepancy = hasDiscrepancy(transfer)
+	}
+	return transfers, nil
+}
+
+func (s *transferService) Update(ctx context.Context, id int, req *models.TransferUpdateRequest) (*models.Transfer, error) {
+	transfer, err := s.draft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SourceLocationID != 0 {
+		transfer.SourceLocationID = req.SourceLocationID
+	}
+	if req.DestinationLocationID != 0 {
+		transfer.DestinationLocationID = req.DestinationLocationID
+	}
+	if req.Notes != "" {
+		transfer.Notes = req.Notes
+	}
+	if err := s.checkLocations(ctx, transfer.SourceLocationID, transfer.DestinationLocationID); err != nil {
+		return nil, err
+	}
+
+	var lines []models.TransferLine
+	if req.Lines != nil {
+		if lines, err = s.lines(ctx, req.Lines); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.repo.Update(ctx, transfer, lines); err != nil {
+		return nil, transferError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *transferService) Delete(ctx context.Context, id int) error {
+	if _, err := s.draft(ctx, id); err != nil {
+		return err
+	}
+	return transferError(s.repo.Delete(ctx, id))
+}
+
+func (s *transferService) Dispatch(ctx context.Context, id int, actor *auth.JWTClaim) (*models.Transfer, error) {
+	transfer, err := s.draft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSourceStock(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Dispatch(ctx, transfer, actor.UserID); err != nil {
+		// Stok berubah di antara pengecekan dan transaksi: hitung ulang untuk pesan error
+		if errors.Is(err, repositories.ErrInsufficientAvailable) {
+			if checkErr := s.checkSourceStock(ctx, transfer); checkErr != nil {
+				return nil, checkErr
+			}
+			return nil, NewConflictError("insufficient_quantity", "toolkit units are no longer available to dispatch")
+		}
+		return nil, transferError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *transferService) Receive(ctx context.Context, id int, req *models.TransferReceiveRequest, actor *auth.JWTClaim) (*models.Transfer, error) {
+	transfer, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferInTransit {
+		return nil, NewConflictError("transfer_not_in_transit", fmt.Sprintf("transfer is %s and cannot be received", transfer.Status))
+	}
+
+	// Line yang tidak disebut dianggap diterima lengkap
+	receipts := make([]repositories.TransferReceipt, len(transfer.Lines))
+	index := make(map[int]int, len(transfer.Lines))
+	for i := range transfer.Lines {
+		line := &transfer.Lines[i]
+		receipts[i] = repositories.TransferReceipt{Line: line, Received: line.Quantity}
+		index[line.ID] = i
+	}
+	seen := make(map[int]bool, len(req.Lines))
+	for i, item := range req.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+		idx, ok := index[item.LineID]
+		if !ok {
+			return nil, NewValidationError("line does not belong to this transfer",
+				FieldError{Field: field + ".line_id", Message: "must be a line of this transfer"})
+		}
+		if seen[item.LineID] {
+			return nil, NewValidationError("duplicate line in receipt",
+				FieldError{Field: field + ".line_id", Message: "is listed more than once"})
+		}
+		seen[item.LineID] = true
+
+		shipped := receipts[idx].Line.Quantity
+		if *item.ReceivedQuantity > shipped {
+			return nil, NewValidationError("cannot receive more units than were dispatched",
+				FieldError{Field: field + ".received_quantity", Message: fmt.Sprintf("must be at most %d", shipped)})
+		}
+		if *item.ReceivedQuantity < shipped && item.Notes == "" {
+			return nil, NewValidationError("discrepancy must be explained",
+				FieldError{Field: field + ".notes", Message: "is required when fewer units are received"})
+		}
+		receipts[idx].Received = *item.ReceivedQuantity
+		receipts[idx].Notes = item.Notes
+	}
+
+	transfer.Notes = req.Notes
+	if err := s.repo.Receive(ctx, transfer, receipts, actor.UserID); err != nil {
+		return nil, transferError(err)
+	}
+	return s.GetByID(ctx, id)
+}
+
+// draft transfer yang masih bisa diubah, dihapus atau dikirim
+func (s *transferService) draft(ctx context.Context, id int) (*models.Transfer, error) {
+	transfer, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferDraft {
+		return nil, NewConflictError("transfer_not_draft", fmt.Sprintf("transfer is %s and can no longer be changed", transfer.Status))
+	}
+	return transfer, nil
+}
+
+// checkLocations asal dan tujuan harus ada dan berbeda, tujuan harus aktif
+func (s *transferService) checkLocations(ctx context.Context, sourceID, destinationID int) error {
+	if sourceID == destinationID {
+		return NewValidationError("source and destination must differ",
+			FieldError{Field: "destination_location_id", Message: "must be a different location than the source"})
+	}
+	if _, err := s.locationRepo.GetByID(ctx, sourceID); err != nil {
+		return locationFieldError(err, "source_location_id")
+	}
+	destination, err := s.locationRepo.GetByID(ctx, destinationID)
+	if err != nil {
+		return locationFieldError(err, "destination_location_id")
+	}
+	if !destination.IsActive {
+		return NewValidationError("destination location is deactivated",
+			FieldError{Field: "destination_location_id", Message: "must be an active location"})
+	}
+	return nil
+}
+
+// lines validasi line transfer: toolkit harus ada dan tidak boleh dobel
+func (s *transferService) lines(ctx context.Context, reqs []models.TransferLineRequest) ([]models.TransferLine, error) {
+	lines := make([]models.TransferLine, 0, len(reqs))
+	seen := make(map[int]bool, len(reqs))
+	for i, req := range reqs {
+		field := fmt.Sprintf("lines[%d].toolkit_id", i)
+		if seen[req.ToolkitID] {
+			return nil, NewValidationError("duplicate toolkit in transfer lines",
+				FieldError{Field: field, Message: "is listed more than once"})
+		}
+		seen[req.ToolkitID] = true
+
+		if _, err := s.toolkitRepo.GetByID(ctx, req.ToolkitID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, NewValidationError("toolkit not found",
+					FieldError{Field: field, Message: "must be an existing toolkit"})
+			}
+			return nil, translateError(err, "toolkit")
+		}
+		lines = append(lines, models.TransferLine{ToolkitID: req.ToolkitID, Quantity: req.Quantity})
+	}
+	return lines, nil
+}
+
+// checkSourceStock semua line harus tersedia di lokasi asal, kekurangan dilaporkan per line
+func (s *transferService) checkSourceStock(ctx context.Context, transfer *models.Transfer) error {
+	stock, err := s.locationRepo.Stock(ctx, transfer.SourceLocationID, &models.LocationStockFilterRequest{})
+	if err != nil {
+		return translateError(err, "location")
+	}
+	available := make(map[int]int, len(stock))
+	for _, line := range stock {
+		available[line.ToolkitID] = line.Available
+	}
+
+	var shortage *Error
+	for i, line := range transfer.Lines {
+		if available[line.ToolkitID] >= line.Quantity {
+			continue
+		}
+		if shortage == nil {
+			shortage = NewInsufficientStockError(available[line.ToolkitID], line.Quantity)
+			shortage.Message = "insufficient stock at source location"
+			shortage.Fields = nil
+		}
+		sku := fmt.Sprintf("toolkit %d", line.ToolkitID)
+		if line.Toolkit != nil {
+			sku = line.Toolkit.SKU
+		}
+		shortage.Fields = append(shortage.Fields, FieldError{
+			Field:   fmt.Sprintf("lines[%d]", i),
+			Message: fmt.Sprintf("%s needs %d, only %d available", sku, line.Quantity, available[line.ToolkitID]),
+		})
+	}
+	if shortage != nil {
+		return shortage
+	}
+	return nil
+}
+
+func locationFieldError(err error, field string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NewValidationError("location not found",
+			FieldError{Field: field, Message: "must be an existing location"})
+	}
+	return translateError(err, "location")
+}
+
+func transferError(err error) error {
+	if errors.Is(err, repositories.ErrTransferChanged) {
+		return NewConflictError("transfer_changed", "transfer was changed by another request, reload and try again")
+	}
+	return translateError(err, "transfer")
+}
+
+// hasDiscrepancy ada line yang diterima kurang dari yang dikirim
+func hasDiscrepancy(transfer *models.Transfer) bool {
+	for _, line := range transfer.Lines {
+		if line.ReceivedQuantity != nil && *line.ReceivedQuantity != line.Quantity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10
+	// maxChallengeAttempts kode salah per challenge login sebelum challenge dibatalkan
+	maxChallengeAttempts = 5
+	challengeIDBytes     = 16
+)
+
+// TwoFactorPolicy RequiredRoles wajib enrolment 2FA sebelum bisa login dengan password
+type TwoFactorPolicy struct {
+	RequiredRoles []string
+	ChallengeTTL  time.Duration
+}
+
+type TwoFactorService interface {
+	Status(ctx context.Context, actor *auth.JWTClaim) (*models.TwoFactorStatus, error)
+	Setup(ctx context.Context, actor *auth.JWTClaim) (*models.TwoFactorSetupResponse, error)
+	Enable(ctx context.Context, actor *auth.JWTClaim, code string) (*models.TwoFactorEnableResponse, error)
+	Disable(ctx context.Context, actor *auth.JWTClaim, req *models.TwoFactorDisableRequest) error
+	Verify(ctx context.Context, req *models.TwoFactorVerifyRequest) (*models.LoginResponse, error)
+	// BeginLogin dipanggil setelah password valid: token sesi, atau challenge langkah kedua
+	BeginLogin(ctx context.Context, user *models.User) (*models.LoginResponse, error)
+}
+
+type twoFactorService struct {
+	repo           repositories.TwoFactorRepository
+	userRepo       repositories.UserRepository
+	authSvc        *auth.AuthService
+	totp           *auth.TOTP
+	policy         TwoFactorPolicy
+	authenticators []Authenticator
+}
+
+// NewTwoFactorService authenticators dipakai untuk re-autentikasi password saat mematikan 2FA
+func NewTwoFactorService(repo repositories.TwoFactorRepository, userRepo repositories.UserRepository, authSvc *auth.AuthService,
+	totp *auth.TOTP, policy TwoFactorPolicy, authenticators ...Authenticator) TwoFactorService {
+	return &twoFactorService{
+		repo:           repo,
+		userRepo:       userRepo,
+		authSvc:        authSvc,
+		totp:           totp,
+		policy:         policy,
+		authenticators: authenticators,
+	}
+}
+
+func (s *twoFactorService) Status(ctx context.Context, actor *auth.JWTClaim) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{Required: s.required(actor.Role)}
+
+	twoFactor, err := s.repo.GetByUserID(ctx, actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && twoFactor.EnabledAt == nil) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	status.RecoveryCodesRemaining = int(remaining)
+	return status, nil
+}
+
+// Setup buat secret baru. Belum aktif sampai dikonfirmasi dengan kode lewat Enable.
+func (s *twoFactorService) Setup(ctx context.Context, actor *auth.JWTClaim) (*models.TwoFactorSetupResponse, error) {
+	if actor.IsAPIToken() {
+		return nil, NewForbiddenError("API tokens cannot manage two-factor authentication")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, actor.UserID)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	if user.AuthProvider == "oidc" {
+		return nil, NewForbiddenError("two-factor authentication for single sign-on accounts is managed by the identity provider")
+	}
+
+	existing, err := s.repo.GetByUserID(ctx, user.ID)
+	if err == nil && existing.EnabledAt != nil {
+		return nil, NewConflictError("two_factor_already_enabled", "two-factor authentication is already enabled")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, translateError(err, "two-factor")
+	}
+
+	secret, uri, err := s.totp.Generate(user.Username)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.totp.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, &models.TwoFactor{UserID: user.ID, Secret: sealed}); err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+
+	return &models.TwoFactorSetupResponse{Secret: secret, ProvisioningURI: uri}, nil
+}
+
+func (s *twoFactorService) Enable(ctx context.Context, actor *auth.JWTClaim, code string) (*models.TwoFactorEnableResponse, error) {
+	if actor.IsAPIToken() {
+		return nil, NewForbiddenError("API tokens cannot manage two-factor authentication")
+	}
+
+	twoFactor, err := s.repo.GetByUserID(ctx, actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NewValidationError("two-factor setup has not been started")
+	}
+	if err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, NewConflictError("two_factor_already_enabled", "two-factor authentication is already enabled")
+	}
+
+	secret, err := s.totp.Open(twoFactor.Secret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := s.totp.Validate(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, NewValidationError("invalid two-factor code",
+			FieldError{Field: "code", Message: "does not match the authenticator app"})
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(ctx, actor.UserID, time.Now(), step, hashes); err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+
+	response := &models.TwoFactorEnableResponse{RecoveryCodes: codes}
+	// Enrolment wajib di tengah login: langsung lanjutkan login
+	if actor.Purpose == auth.PurposeTwoFactorSetup {
+		user, err := s.userRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return nil, translateError(err, "user")
+		}
+		if response.Login, err = issueLogin(s.authSvc, user); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// Disable butuh password dan kode 2FA, supaya sesi yang dibajak tidak bisa mematikannya
+func (s *twoFactorService) Disable(ctx context.Context, actor *auth.JWTClaim, req *models.TwoFactorDisableRequest) error {
+	if actor.IsAPIToken() {
+		return NewForbiddenError("API tokens cannot manage two-factor authentication")
+	}
+	if s.required(actor.Role) {
+		return NewForbiddenError("two-factor authentication is required for role " + actor.Role)
+	}
+
+	if err := s.reauthenticate(ctx, actor, req.Password); err != nil {
+		return err
+	}
+
+	twoFactor, err := s.enabled(ctx, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCode(ctx, twoFactor, req.Code); err != nil {
+		return err
+	}
+
+	return translateError(s.repo.Delete(ctx, actor.UserID), "two-factor")
+}
+
+// Verify langkah kedua login
+func (s *twoFactorService) Verify(ctx context.Context, req *models.TwoFactorVerifyRequest) (*models.LoginResponse, error) {
+	claims, err := s.authSvc.ValidateChallengeToken(req.ChallengeToken, auth.PurposeTwoFactor)
+	if err != nil {
+		return nil, NewUnauthorizedError("invalid or expired challenge token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	if !user.IsActive {
+		return nil, NewUnauthorizedError("user is inactive")
+	}
+
+	twoFactor, err := s.enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Hanya challenge terakhir yang berlaku, dan hanya sampai maxChallengeAttempts kode salah
+	expired := NewUnauthorizedError("challenge is no longer valid, log in again")
+	if claims.ID == "" || claims.ID != twoFactor.ChallengeID {
+		return nil, expired
+	}
+	if err := s.checkCode(ctx, twoFactor, req.Code); err != nil {
+		if !errors.Is(err, ErrUnauthorized) {
+			return nil, err
+		}
+		active, failErr := s.repo.FailChallenge(ctx, user.ID, claims.ID, maxChallengeAttempts)
+		if failErr != nil {
+			return nil, translateError(failErr, "two-factor")
+		}
+		if !active {
+			return nil, expired
+		}
+		return nil, err
+	}
+
+	ended, err := s.repo.EndChallenge(ctx, user.ID, claims.ID)
+	if err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+	if !ended {
+		return nil, expired
+	}
+	return issueLogin(s.authSvc, user)
+}
+
+func (s *twoFactorService) BeginLogin(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	twoFactor, err := s.repo.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, translateError(err, "two-factor")
+	}
+
+	switch {
+	case err == nil && twoFactor.EnabledAt != nil:
+		challengeID, err := randomHex(challengeIDBytes)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.StartChallenge(ctx, user.ID, challengeID); err != nil {
+			return nil, translateError(err, "two-factor")
+		}
+		return s.challenge(user, auth.PurposeTwoFactor, "verify", challengeID)
+	case s.required(user.Role):
+		return s.challenge(user, auth.PurposeTwoFactorSetup, "setup", "")
+	}
+	return issueLogin(s.authSvc, user)
+}
+
+func (s *twoFactorService) challenge(user *models.User, purpose, step, challengeID string) (*models.LoginResponse, error) {
+	token, err := s.authSvc.GenerateChallengeToken(user.ID, user.Username, user.Role, purpose, challengeID, s.policy.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		TwoFactor: &models.TwoFactorChallenge{
+			Step:           step,
+			ChallengeToken: token,
+			ExpiresAt:      time.Now().Add(s.policy.ChallengeTTL),
+		},
+	}, nil
+}
+
+func (s *twoFactorService) enabled(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	twoFactor, err := s.repo.GetByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && twoFactor.EnabledAt == nil) {
+		return nil, NewConflictError("two_factor_not_enabled", "two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return nil, translateError(err, "two-factor")
+	}
+	return twoFactor, nil
+}
+
+// checkCode terima kode TOTP 6 digit atau recovery code, masing-masing hanya sekali pakai
+func (s *twoFactorService) checkCode(ctx context.Context, twoFactor *models.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	invalid := NewUnauthorizedError("invalid two-factor code")
+
+	if isDigits(code) {
+		secret, err := s.totp.Open(twoFactor.Secret)
+		if err != nil {
+			return err
+		}
+		step, ok := s.totp.Validate(secret, code, time.Now())
+		if !ok {
+			return invalid
+		}
+		consumed, err := s.repo.ConsumeStep(ctx, twoFactor.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return invalid
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, twoFactor.UserID, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return invalid
+	}
+	return nil
+}
+
+// reauthenticate password lewat authenticator yang sama dengan login (lokal/LDAP)
+func (s *twoFactorService) reauthenticate(ctx context.Context, actor *auth.JWTClaim, password string) error {
+	for _, authenticator := range s.authenticators {
+		user, err := authenticator.Authenticate(ctx, actor.Username, password)
+		if err == nil && user.ID == actor.UserID {
+			return nil
+		}
+		var domainErr *Error
+		if errors.As(err, &domainErr) {
+			return err
+		}
+	}
+	return NewUnauthorizedError("invalid password")
+}
+
+func (s *twoFactorService) required(role string) bool {
+	return slices.Contains(s.policy.RequiredRoles, role)
+}
+
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		buf := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))
+		// xxxx-xxxx-xxxx-xxxx supaya mudah dicatat
+		code := raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashRecoveryCode abaikan huruf besar/kecil, spasi dan tanda hubung
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+)
+
+// fakeEnrolledRepo satu user dengan 2FA aktif, challenge disimpan seperti di database
+type fakeEnrolledRepo struct {
+	repositories.TwoFactorRepository
+	twoFactor models.TwoFactor
+}
+
+func (r *fakeEnrolledRepo) GetByUserID(context.Context, int) (*models.TwoFactor, error) {
+	copied := r.twoFactor
+	return &copied, nil
+}
+
+func (r *fakeEnrolledRepo) ConsumeStep(_ context.Context, _ int, step int64) (bool, error) {
+	if r.twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	r.twoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeEnrolledRepo) UseRecoveryCode(context.Context, int, string, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeEnrolledRepo) StartChallenge(_ context.Context, _ int, challengeID string) error {
+	r.twoFactor.ChallengeID, r.twoFactor.FailedAttempts = challengeID, 0
+	return nil
+}
+
+func (r *fakeEnrolledRepo) FailChallenge(_ context.Context, _ int, challengeID string, maxAttempts int) (bool, error) {
+	if r.twoFactor.ChallengeID != challengeID {
+		return false, nil
+	}
+	r.twoFactor.FailedAttempts++
+	if r.twoFactor.FailedAttempts >= maxAttempts {
+		r.twoFactor.ChallengeID = ""
+		return false, nil
+	}
+	return true, nil
+}
+
+func (r *fakeEnrolledRepo) EndChallenge(_ context.Context, _ int, challengeID string) (bool, error) {
+	if r.twoFactor.ChallengeID != challengeID {
+		return false, nil
+	}
+	r.twoFactor.ChallengeID, r.twoFactor.FailedAttempts = "", 0
+	return true, nil
+}
+
+type twoFactorFixture struct {
+	svc    TwoFactorService
+	repo   *fakeEnrolledRepo
+	user   *models.User
+	secret string
+}
+
+func newTwoFactorFixture(t *testing.T) *twoFactorFixture {
+	t.Helper()
+	codes, err := auth.NewTOTP("toolkit", []byte("test-encryption-key"))
+	if err != nil {
+		t.Fatalf("NewTOTP: %v", err)
+	}
+	secret, _, err := codes.Generate("budi")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	sealed, err := codes.Seal(secret)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	enabledAt := time.Now().Add(-time.Hour)
+	user := &models.User{ID: 1, Username: "budi", Role: "user", IsActive: true}
+	repo := &fakeEnrolledRepo{twoFactor: models.TwoFactor{UserID: user.ID, Secret: sealed, EnabledAt: &enabledAt}}
+	authSvc := auth.NewAuthService(auth.AuthConfig{SecretKey: "test-secret"})
+	svc := NewTwoFactorService(repo, newFakeUserRepo(user), authSvc, codes, TwoFactorPolicy{ChallengeTTL: 5 * time.Minute})
+	return &twoFactorFixture{svc: svc, repo: repo, user: user, secret: secret}
+}
+
+func (f *twoFactorFixture) login(t *testing.T) string {
+	t.Helper()
+	result, err := f.svc.BeginLogin(context.Background(), f.user)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	if result.TwoFactor == nil || result.TwoFactor.Step != "verify" {
+		t.Fatalf("BeginLogin = %+v, want verify challenge", result)
+	}
+	return result.TwoFactor.ChallengeToken
+}
+
+func (f *twoFactorFixture) verify(token, code string) (*models.LoginResponse, error) {
+	return f.svc.Verify(context.Background(), &models.TwoFactorVerifyRequest{ChallengeToken: token, Code: code})
+}
+
+func (f *twoFactorFixture) code(t *testing.T) string {
+	t.Helper()
+	code, err := totp.GenerateCode(f.secret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+	return code
+}
+
+func TestVerifyInvalidatesChallengeAfterFailedAttempts(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	token := f.login(t)
+	wrong := "000000"
+	if wrong == f.code(t) {
+		wrong = "111111"
+	}
+
+	for attempt := 1; attempt <= maxChallengeAttempts; attempt++ {
+		if _, err := f.verify(token, wrong); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("attempt %d: err = %v, want unauthorized", attempt, err)
+		}
+	}
+	if f.repo.twoFactor.ChallengeID != "" {
+		t.Fatalf("challenge still active after %d failures", maxChallengeAttempts)
+	}
+
+	// Kode yang benar pun ditolak, harus login ulang dengan password
+	if _, err := f.verify(token, f.code(t)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized for the cancelled challenge", err)
+	}
+
+	result, err := f.verify(f.login(t), f.code(t))
+	if err != nil {
+		t.Fatalf("Verify with a new challenge: %v", err)
+	}
+	if result.Token == "" {
+		t.Error("no session token after a valid code")
+	}
+}
+
+func TestVerifyChallengeIsSingleUse(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	token := f.login(t)
+
+	if _, err := f.verify(token, f.code(t)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	f.repo.twoFactor.LastUsedStep = 0
+	if _, err := f.verify(token, f.code(t)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized when the challenge is reused", err)
+	}
+}
+
+func TestVerifyOnlyLatestChallenge(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	first := f.login(t)
+	second := f.login(t)
+
+	if _, err := f.verify(first, f.code(t)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized for a replaced challenge", err)
+	}
+	if _, err := f.verify(second, f.code(t)); err != nil {
+		t.Fatalf("Verify latest challenge: %v", err)
+	}
+}
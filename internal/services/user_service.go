@@ -1,38 +1,50 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+
 	"toolkit-management/internal/models"
 	. "toolkit-management/internal/repositories"
 	"toolkit-management/pkg/auth"
 )
 
 type UserService interface {
-	Create(req *models.UserCreateRequest) (*models.User, error)
-	GetByID(id int) (*models.User, error)
-	GetAll(filter *models.UserFilterRequest) (*models.UserListResponse, error)
-	Update(id int, req *models.UserUpdateRequest) (*models.User, error)
-	Delete(id int) error
-	Login(req *models.LoginRequest) (*models.LoginResponse, error)
+	Create(ctx context.Context, req *models.UserCreateRequest) (*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetAll(ctx context.Context, filter *models.UserFilterRequest) (*models.UserListResponse, error)
+	Update(ctx context.Context, id int, req *models.UserUpdateRequest) (*models.User, error)
+	Delete(ctx context.Context, id int) error
+	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	LoginExternal(ctx context.Context, identity *models.ExternalIdentity) (*models.LoginResponse, error)
 }
 
 type userService struct {
-	userRepo UserRepository
-	authSvc  *auth.AuthService
+	userRepo       UserRepository
+	authSvc        *auth.AuthService
+	twoFactor      TwoFactorService
+	authenticators []Authenticator
 }
 
-func NewUserService(repo UserRepository) UserService {
-	authConfig := auth.AuthConfig{
-		SecretKey:     "secrect-key-rahasia",
-		TokenDuration: 24 * time.Hour,
-	}
+// NewUserService authenticators dicoba berurutan saat Login. Tanpa authenticator,
+// login username/password ditolak (mis. production dengan SSO saja).
+func NewUserService(repo UserRepository, authSvc *auth.AuthService, twoFactor TwoFactorService, authenticators ...Authenticator) UserService {
 	return &userService{
-		userRepo: repo,
-		authSvc:  auth.NewAuthService(authConfig),
+		userRepo:       repo,
+		authSvc:        authSvc,
+		twoFactor:      twoFactor,
+		authenticators: authenticators,
 	}
 }
 
-func (s *userService) Create(req *models.UserCreateRequest) (*models.User, error) {
+func (s *userService) Create(ctx context.Context, req *models.UserCreateRequest) (*models.User, error) {
 	// Hash password
 	hashedPassword, err := models.HashPassword(req.Password)
 	if err != nil {
@@ -47,22 +59,35 @@ func (s *userService) Create(req *models.UserCreateRequest) (*models.User, error
 		Role:        req.Role,
 		Department:  req.Department,
 		PhoneNumber: req.PhoneNumber,
+		BadgeNumber: badgeNumber(req.BadgeNumber),
 		IsActive:    true,
 	}
 
-	return s.userRepo.Create(user)
+	result, err := s.userRepo.Create(ctx, user)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return result, nil
 }
 
-func (s *userService) GetByID(id int) (*models.User, error) {
-	return s.userRepo.GetByID(id)
+func (s *userService) GetByID(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
 }
 
-func (s *userService) GetAll(filter *models.UserFilterRequest) (*models.UserListResponse, error) {
-	return s.userRepo.GetAll(filter)
+func (s *userService) GetAll(ctx context.Context, filter *models.UserFilterRequest) (*models.UserListResponse, error) {
+	result, err := s.userRepo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return result, nil
 }
 
-func (s *userService) Update(id int, req *models.UserUpdateRequest) (*models.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) Update(ctx context.Context, id int, req *models.UserUpdateRequest) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -88,37 +113,181 @@ func (s *userService) Update(id int, req *models.UserUpdateRequest) (*models.Use
 	if req.PhoneNumber != "" {
 		user.PhoneNumber = req.PhoneNumber
 	}
+	if req.BadgeNumber != "" {
+		user.BadgeNumber = badgeNumber(req.BadgeNumber)
+	}
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
 
-	return s.userRepo.Update(user)
+	result, err := s.userRepo.Update(ctx, user)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return result, nil
 }
 
-func (s *userService) Delete(id int) error {
-	return s.userRepo.Delete(id)
+func (s *userService) Delete(ctx context.Context, id int) error {
+	return translateError(s.userRepo.Delete(ctx, id), "user")
 }
 
-func (s *userService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
-	user, err := s.userRepo.GetByUsername(req.Username)
+func (s *userService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	if len(s.authenticators) == 0 {
+		return nil, NewForbiddenError("password login is disabled, use single sign-on")
+	}
+
+	var backendErr error
+	for _, authenticator := range s.authenticators {
+		user, err := authenticator.Authenticate(ctx, req.Username, req.Password)
+		if err == nil {
+			return s.twoFactor.BeginLogin(ctx, user)
+		}
+
+		var domainErr *Error
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			continue
+		case errors.As(err, &domainErr):
+			return nil, err
+		}
+		// Backend lain tetap dicoba, mis. admin lokal saat direktori down
+		slog.WarnContext(ctx, "Authenticator unavailable", "authenticator", authenticator.Name(), "error", err)
+		backendErr = err
+	}
+
+	if backendErr != nil {
+		return nil, backendErr
+	}
+	return nil, NewUnauthorizedError("invalid username or password")
+}
+
+// LoginExternal login via IdP: cari user berdasarkan external ID, atau buat user baru
+// (just-in-time provisioning). Profil disinkronkan dari IdP setiap login. 2FA tetap diminta
+// untuk role yang mewajibkannya, sama seperti login password.
+func (s *userService) LoginExternal(ctx context.Context, identity *models.ExternalIdentity) (*models.LoginResponse, error) {
+	user, err := resolveExternalUser(ctx, s.userRepo, identity)
 	if err != nil {
 		return nil, err
 	}
+	return s.twoFactor.BeginLogin(ctx, user)
+}
+
+// resolveExternalUser dipakai bersama oleh OIDC dan LDAP
+func resolveExternalUser(ctx context.Context, repo UserRepository, identity *models.ExternalIdentity) (*models.User, error) {
+	if identity.Email == "" {
+		return nil, NewUnauthorizedError("identity provider did not return an email address")
+	}
+	externalID := identity.Provider + ":" + identity.Subject
+
+	user, err := repo.GetByExternalID(ctx, externalID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return provisionExternal(ctx, repo, identity, externalID)
+	}
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+
+	if !user.IsActive {
+		return nil, NewUnauthorizedError("user is inactive")
+	}
+	syncExternalProfile(user, identity)
+	if user, err = repo.Update(ctx, user); err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+// provisionExternal akun yang sudah ada dengan email yang sama tidak ditautkan otomatis:
+// siapa pun yang menguasai email itu di IdP bisa mengambil alih akun lokal atau admin.
+func provisionExternal(ctx context.Context, repo UserRepository, identity *models.ExternalIdentity, externalID string) (*models.User, error) {
+	if !identity.EmailVerified {
+		return nil, NewUnauthorizedError("identity provider has not verified the email address")
+	}
+	_, err := repo.GetByEmail(ctx, identity.Email)
+	if err == nil {
+		return nil, NewConflictError("account_exists", "an account with this email already exists and is not linked to this sign-in")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, translateError(err, "user")
+	}
 
-	// Check password
-	if err := models.CheckPassword(user.Password, req.Password); err != nil {
+	username, err := availableUsername(ctx, repo, identity.Username, externalID)
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.authSvc.GenerateToken(user.ID, user.Username, user.Role)
+	user := &models.User{
+		Username:     username,
+		Role:         identity.DefaultRole,
+		IsActive:     true,
+		AuthProvider: identity.Provider,
+		ExternalID:   &externalID,
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	syncExternalProfile(user, identity)
+
+	created, err := repo.Create(ctx, user)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return created, nil
+}
+
+// availableUsername pakai username dari IdP, tambah suffix dari external ID kalau sudah dipakai
+func availableUsername(ctx context.Context, repo UserRepository, candidate, externalID string) (string, error) {
+	if candidate == "" {
+		candidate = "user"
+	}
+	_, err := repo.GetByUsername(ctx, candidate)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return candidate, nil
+	}
+	if err != nil {
+		return "", translateError(err, "user")
+	}
+	sum := sha256.Sum256([]byte(externalID))
+	return candidate + "-" + hex.EncodeToString(sum[:3]), nil
+}
+
+// syncExternalProfile AuthProvider dan ExternalID hanya diisi saat provisioning.
+// Role hanya diubah kalau ada group yang cocok.
+func syncExternalProfile(user *models.User, identity *models.ExternalIdentity) {
+	now := time.Now()
+	user.LastLogin = &now
+	user.Email = identity.Email
+	if identity.FullName != "" {
+		user.FullName = identity.FullName
+	}
+	if identity.Role != "" {
+		user.Role = identity.Role
+	}
+	if identity.Department != "" {
+		user.Department = identity.Department
+	}
+}
+
+// issueLogin terbitkan token sesi setelah semua langkah login selesai
+func issueLogin(authSvc *auth.AuthService, user *models.User) (*models.LoginResponse, error) {
+	token, err := authSvc.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		return nil, err
 	}
 
+	expiresAt := time.Now().Add(authSvc.TokenDuration())
 	return &models.LoginResponse{
 		Token:     token,
-		User:      *user,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		User:      user,
+		ExpiresAt: &expiresAt,
 	}, nil
 }
+
+// badgeNumber kosong disimpan NULL supaya unique index tidak bentrok
+func badgeNumber(badge string) *string {
+	badge = strings.TrimSpace(badge)
+	if badge == "" {
+		return nil
+	}
+	return &badge
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+	"toolkit-management/internal/repositories"
+	"toolkit-management/pkg/auth"
+	"toolkit-management/pkg/auth/oidctest"
+)
+
+// fakeUserRepo user di memori, method lain dari interface tidak dipakai
+type fakeUserRepo struct {
+	repositories.UserRepository
+	users map[int]*models.User
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: map[int]*models.User{}}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) find(match func(*models.User) bool) (*models.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, id int) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) GetByExternalID(_ context.Context, externalID string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ExternalID != nil && *u.ExternalID == externalID })
+}
+
+func (r *fakeUserRepo) Create(_ context.Context, user *models.User) (*models.User, error) {
+	user.ID = len(r.users) + 1
+	copied := *user
+	r.users[user.ID] = &copied
+	return user, nil
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user *models.User) (*models.User, error) {
+	copied := *user
+	r.users[user.ID] = &copied
+	return user, nil
+}
+
+// fakeTwoFactorRepo belum ada user yang enrol 2FA
+type fakeTwoFactorRepo struct {
+	repositories.TwoFactorRepository
+}
+
+func (fakeTwoFactorRepo) GetByUserID(context.Context, int) (*models.TwoFactor, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func newTestUserService(repo *fakeUserRepo) UserService {
+	authSvc := auth.NewAuthService(auth.AuthConfig{SecretKey: "test-secret"})
+	twoFactor := NewTwoFactorService(fakeTwoFactorRepo{}, repo, authSvc, nil,
+		TwoFactorPolicy{RequiredRoles: []string{"admin"}, ChallengeTTL: 5 * time.Minute})
+	return NewUserService(repo, authSvc, twoFactor)
+}
+
+// oidcLogin jalankan authorization code flow ke IdP palsu, hasilnya seperti OIDCHandler.Callback
+func oidcLogin(t *testing.T, claims map[string]any) *models.ExternalIdentity {
+	t.Helper()
+	idp := oidctest.NewServer("toolkit", "secret")
+	t.Cleanup(idp.Close)
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://toolkit.test/api/auth/oidc/callback",
+		Groups:       auth.GroupMapping{Roles: map[string]string{"toolkit-admins": "admin"}},
+		StateKey:     []byte("test-state-key"),
+	})
+	ctx := context.Background()
+
+	redirectURL, cookie, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := idp.Authorize(redirectURL, claims)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	identity, err := provider.Exchange(ctx, cookie, state, code)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	return &models.ExternalIdentity{
+		Provider:      "oidc",
+		Subject:       identity.Subject,
+		Username:      identity.Username,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		FullName:      identity.FullName,
+		Role:          identity.Role,
+		DefaultRole:   identity.DefaultRole,
+		Department:    identity.Department,
+	}
+}
+
+func TestLoginExternalProvisionsUser(t *testing.T) {
+	repo := newFakeUserRepo()
+	identity := oidcLogin(t, map[string]any{"sub": "u-1", "email": "budi@example.com", "email_verified": true, "name": "Budi"})
+
+	result, err := newTestUserService(repo).LoginExternal(context.Background(), identity)
+	if err != nil {
+		t.Fatalf("LoginExternal: %v", err)
+	}
+	if result.Token == "" || result.TwoFactor != nil {
+		t.Fatalf("expected a session token, got %+v", result)
+	}
+	user := repo.users[result.User.ID]
+	if user.Role != "user" || user.AuthProvider != "oidc" || user.ExternalID == nil || *user.ExternalID != "oidc:u-1" {
+		t.Errorf("provisioned user = role %q provider %q external %v", user.Role, user.AuthProvider, user.ExternalID)
+	}
+}
+
+func TestLoginExternalDoesNotLinkExistingAccount(t *testing.T) {
+	admin := &models.User{ID: 1, Username: "admin", Email: "admin@example.com", Role: "admin", AuthProvider: "local", IsActive: true}
+	repo := newFakeUserRepo(admin)
+	identity := oidcLogin(t, map[string]any{"sub": "attacker", "email": "admin@example.com", "email_verified": true})
+
+	_, err := newTestUserService(repo).LoginExternal(context.Background(), identity)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if stored := repo.users[1]; stored.ExternalID != nil || stored.AuthProvider != "local" {
+		t.Errorf("local account was linked: %+v", stored)
+	}
+	if len(repo.users) != 1 {
+		t.Errorf("users = %d, want no new account", len(repo.users))
+	}
+}
+
+func TestLoginExternalRejectsUnverifiedEmail(t *testing.T) {
+	repo := newFakeUserRepo()
+	identity := oidcLogin(t, map[string]any{"sub": "u-1", "email": "budi@example.com"})
+
+	if _, err := newTestUserService(repo).LoginExternal(context.Background(), identity); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want unauthorized", err)
+	}
+}
+
+func TestLoginExternalKeepsRoleWithoutGroupMatch(t *testing.T) {
+	externalID := "oidc:u-1"
+	repo := newFakeUserRepo(&models.User{
+		ID: 1, Username: "budi", Email: "budi@example.com", Role: "technician",
+		AuthProvider: "oidc", ExternalID: &externalID, IsActive: true,
+	})
+	identity := oidcLogin(t, map[string]any{"sub": "u-1", "email": "budi@example.com", "groups": []string{"guests"}})
+
+	result, err := newTestUserService(repo).LoginExternal(context.Background(), identity)
+	if err != nil {
+		t.Fatalf("LoginExternal: %v", err)
+	}
+	if user := repo.users[1]; user.Role != "technician" || user.AuthProvider != "oidc" {
+		t.Errorf("user = role %q provider %q, want technician/oidc", user.Role, user.AuthProvider)
+	}
+	if result.User.LastLogin == nil {
+		t.Error("LastLogin not updated")
+	}
+}
+
+func TestLoginExternalRequiresTwoFactor(t *testing.T) {
+	repo := newFakeUserRepo()
+	identity := oidcLogin(t, map[string]any{
+		"sub": "u-1", "email": "budi@example.com", "email_verified": true, "groups": []string{"toolkit-admins"},
+	})
+
+	result, err := newTestUserService(repo).LoginExternal(context.Background(), identity)
+	if err != nil {
+		t.Fatalf("LoginExternal: %v", err)
+	}
+	if result.Token != "" {
+		t.Error("admin received a session token without two-factor")
+	}
+	if result.TwoFactor == nil || result.TwoFactor.Step != "setup" {
+		t.Errorf("two-factor challenge = %+v, want setup step", result.TwoFactor)
+	}
+}
+
+func TestResolveExternalUserDirectoryEmailDoesNotTakeOver(t *testing.T) {
+	repo := newFakeUserRepo(&models.User{ID: 1, Username: "admin", Email: "admin@example.com", Role: "admin", AuthProvider: "local", IsActive: true})
+
+	// Identitas seperti dari ldapAuthenticator: email direktori dianggap terverifikasi
+	_, err := resolveExternalUser(context.Background(), repo, &models.ExternalIdentity{
+		Provider: "ldap", Subject: "cn=intruder", Username: "intruder", Email: "admin@example.com", EmailVerified: true,
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if stored := repo.users[1]; stored.ExternalID != nil || stored.AuthProvider != "local" {
+		t.Errorf("local account was taken over: %+v", stored)
+	}
+}
@@ -1,150 +1,342 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"toolkit-management/config"
 	"toolkit-management/internal/handlers"
+	"toolkit-management/internal/health"
+	"toolkit-management/internal/jobs"
+	"toolkit-management/internal/metrics"
+	"toolkit-management/internal/middleware"
+	"toolkit-management/internal/ratelimit"
 	"toolkit-management/internal/repositories"
 	"toolkit-management/internal/services"
 	"toolkit-management/pkg/auth"
 	"toolkit-management/pkg/database"
+	"toolkit-management/pkg/logger"
+	"toolkit-management/pkg/server"
+	"toolkit-management/pkg/storage"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Structured logger, dipakai juga oleh gorm dan access log
+	appLogger := logger.New(logger.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	slog.SetDefault(appLogger)
+	slog.Info("Configuration loaded", "config", cfg)
 
 	// Set Mode Gin
-	if cfg.Environment != "development" {
+	if !cfg.IsDevelopment() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Init Database
+	// Init Database (migrate + seed)
 	db := database.InitDB(cfg)
 
-	//seed user admin
-	database.SeedAdminUser(db)
-
-	if cfg.Environment == "development" {
-		database.SeedTestData(db)
+	// Metrics
+	appMetrics := metrics.New()
+	if sqlDB, err := db.DB(); err == nil {
+		appMetrics.RegisterDB(sqlDB, cfg.Database.Name)
 	}
 
 	// Init Auth Service
-	authConfig := auth.AuthConfig{
-		SecretKey:     "secrect-key-rahasia",
-		TokenDuration: time.Hour * time.Duration(24),
-	}
-	authService := auth.NewAuthService(authConfig)
+	authService := auth.NewAuthService(auth.AuthConfig{
+		SecretKey:     cfg.Auth.JWTSecret,
+		TokenDuration: cfg.Auth.TokenDuration.Duration(),
+		Issuer:        cfg.Auth.Issuer,
+	})
 
 	// init repo & service
 	userRepo := repositories.NewUserRepository(db)
 	toolkitRepo := repositories.NewToolkitRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
 	loanRepo := repositories.NewLoanRepository(db)
+	bundleRepo := repositories.NewBundleRepository(db)
+	locationRepo := repositories.NewLocationRepository(db)
+	transferRepo := repositories.NewTransferRepository(db)
+	maintenanceRepo := repositories.NewMaintenanceRepository(db)
+	inspectionRepo := repositories.NewInspectionRepository(db)
+	incidentRepo := repositories.NewIncidentRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	statsRepo := repositories.NewStatsRepository(db)
+	apiTokenRepo := repositories.NewAPITokenRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+
+	// Background jobs
+	scheduler := jobs.NewScheduler()
+	inventoryCollector := metrics.NewInventoryCollector(appMetrics, statsRepo)
+	scheduler.Every("inventory_metrics", cfg.Metrics.RefreshInterval.Duration(), inventoryCollector.Refresh)
+
+	// Rate limit per route group & role
+	rateLimitStore := ratelimit.NewMemoryStore()
+	scheduler.Every("ratelimit_cleanup", time.Minute, rateLimitStore.Cleanup)
+	var rateLimitPolicies []ratelimit.Policy
+	for name, group := range cfg.RateLimit.Groups {
+		policy := ratelimit.Policy{
+			Name:    name,
+			Default: ratelimit.Limit{RequestsPerMinute: group.RequestsPerMinute, Burst: group.Burst},
+			Roles:   map[string]ratelimit.Limit{},
+		}
+		for role, rule := range group.Roles {
+			policy.Roles[role] = ratelimit.Limit{RequestsPerMinute: rule.RequestsPerMinute, Burst: rule.Burst}
+		}
+		rateLimitPolicies = append(rateLimitPolicies, policy)
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore, cfg.RateLimit.Enabled, rateLimitPolicies...)
+	scheduler.Start(context.Background())
+
+	// Readiness checks
+	healthChecker := health.NewChecker(cfg.Health.CheckTimeout.Duration())
+	healthChecker.Register("database", database.CheckConnection(db))
+	healthChecker.Register("migrations", database.CheckMigrations(db))
+	healthChecker.Register("scheduler", scheduler.Check)
 
-	userService := services.NewUserService(userRepo)
-	toolkitService := services.NewToolkitService(toolkitRepo)
+	// Login username/password: user lokal dulu, lalu direktori LDAP
+	var authenticators []services.Authenticator
+	if cfg.Auth.LocalLoginEnabled {
+		authenticators = append(authenticators, services.NewLocalAuthenticator(userRepo))
+	}
+	if cfg.Auth.LDAP.Enabled {
+		ldapDirectory := auth.NewLDAPDirectory(auth.LDAPConfig{
+			URL:                 cfg.Auth.LDAP.URL,
+			StartTLS:            cfg.Auth.LDAP.StartTLS,
+			InsecureSkipVerify:  cfg.Auth.LDAP.InsecureSkipVerify,
+			BindDN:              cfg.Auth.LDAP.BindDN,
+			BindPassword:        cfg.Auth.LDAP.BindPassword,
+			BaseDN:              cfg.Auth.LDAP.BaseDN,
+			UserFilter:          cfg.Auth.LDAP.UserFilter,
+			GroupBaseDN:         cfg.Auth.LDAP.GroupBaseDN,
+			GroupFilter:         cfg.Auth.LDAP.GroupFilter,
+			IDAttribute:         cfg.Auth.LDAP.Attributes.ID,
+			UsernameAttribute:   cfg.Auth.LDAP.Attributes.Username,
+			EmailAttribute:      cfg.Auth.LDAP.Attributes.Email,
+			NameAttribute:       cfg.Auth.LDAP.Attributes.FullName,
+			DepartmentAttribute: cfg.Auth.LDAP.Attributes.Department,
+			GroupAttribute:      cfg.Auth.LDAP.Attributes.Groups,
+			Groups: auth.GroupMapping{
+				Roles:       cfg.Auth.LDAP.Groups.Roles,
+				Departments: cfg.Auth.LDAP.Groups.Departments,
+				DefaultRole: cfg.Auth.LDAP.Groups.DefaultRole,
+			},
+			Timeout: cfg.Auth.LDAP.Timeout.Duration(),
+		})
+		authenticators = append(authenticators, services.NewLDAPAuthenticator(ldapDirectory, userRepo))
+	}
+
+	// Two-factor (TOTP) untuk login password
+	totp, err := auth.NewTOTP(cfg.Auth.TwoFactor.Issuer, []byte(cfg.Auth.TwoFactor.EncryptionKey))
+	if err != nil {
+		slog.Error("Invalid two-factor configuration", "error", err)
+		os.Exit(1)
+	}
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, authService, totp, services.TwoFactorPolicy{
+		RequiredRoles: cfg.Auth.TwoFactor.RequiredRoles,
+		ChallengeTTL:  cfg.Auth.TwoFactor.ChallengeTTL.Duration(),
+	}, authenticators...)
+
+	// Storage foto & dokumen toolkit
+	fileStorage, err := openStorage(context.Background(), cfg.Storage)
+	if err != nil {
+		slog.Error("Failed to initialize file storage", "driver", cfg.Storage.Driver, "error", err)
+		os.Exit(1)
+	}
+	attachmentService := services.NewAttachmentService(attachmentRepo, toolkitRepo, fileStorage, services.AttachmentPolicy{
+		MaxPhotoSize:    int64(cfg.Storage.MaxPhotoSizeMB) << 20,
+		MaxDocumentSize: int64(cfg.Storage.MaxDocumentSizeMB) << 20,
+		ThumbnailSize:   cfg.Storage.ThumbnailSize,
+	})
+
+	userService := services.NewUserService(userRepo, authService, twoFactorService, authenticators...)
+	toolkitService := services.NewToolkitService(toolkitRepo, attachmentService)
 	categoryService := services.NewCategoryService(categoryRepo)
-	loanService := services.NewLoanService(loanRepo, toolkitRepo)
+	inspectionPolicy := services.InspectionPolicy{Required: cfg.Loans.RequireInspection}
+	loanService := services.NewLoanService(loanRepo, toolkitRepo, incidentRepo, locationRepo, inspectionRepo, inspectionPolicy)
+	maintenanceService := services.NewMaintenanceService(maintenanceRepo, toolkitRepo, userRepo)
+	inspectionService := services.NewInspectionService(inspectionRepo, loanRepo, toolkitRepo, categoryRepo)
+	incidentService := services.NewIncidentService(incidentRepo, loanRepo, toolkitRepo)
+	reportService := services.NewReportService(reportRepo)
+	labelService := services.NewLabelService(toolkitRepo)
+	bundleService := services.NewBundleService(bundleRepo, toolkitRepo, inspectionRepo, inspectionPolicy)
+	locationService := services.NewLocationService(locationRepo, toolkitRepo)
+	transferService := services.NewTransferService(transferRepo, locationRepo, toolkitRepo)
+	scanService := services.NewScanService(loanService, loanRepo, toolkitRepo, userRepo, loanPolicy(cfg.Loans))
+	searchService := services.NewSearchService(searchRepo)
+	apiTokenService := services.NewAPITokenService(apiTokenRepo,
+		cfg.Auth.APITokenDefaultTTL.Duration(), cfg.Auth.APITokenMaxTTL.Duration())
+	authService.UseAPITokens(apiTokenService)
 
 	// init handler
 	userHandler := handlers.NewUserHandler(userService)
 	toolkitHandler := handlers.NewToolkitHandler(toolkitService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	loanHandler := handlers.NewLoanHandler(loanService)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+	inspectionHandler := handlers.NewInspectionHandler(inspectionService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	bundleHandler := handlers.NewBundleHandler(bundleService)
+	locationHandler := handlers.NewLocationHandler(locationService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	scanHandler := handlers.NewScanHandler(scanService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+
+	// Single sign-on (OIDC authorization code + PKCE)
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.Auth.OIDC.Enabled {
+		stateKey := []byte(cfg.Auth.OIDC.StateKey)
+		if len(stateKey) == 0 {
+			stateKey = auth.DeriveStateKey([]byte(cfg.Auth.JWTSecret))
+		}
+		oidcProvider := auth.NewOIDCProvider(auth.OIDCConfig{
+			IssuerURL:    cfg.Auth.OIDC.IssuerURL,
+			ClientID:     cfg.Auth.OIDC.ClientID,
+			ClientSecret: cfg.Auth.OIDC.ClientSecret,
+			RedirectURL:  cfg.Auth.OIDC.RedirectURL,
+			Scopes:       cfg.Auth.OIDC.Scopes,
+			GroupsClaim:  cfg.Auth.OIDC.GroupsClaim,
+			Groups: auth.GroupMapping{
+				Roles:       cfg.Auth.OIDC.Groups.Roles,
+				Departments: cfg.Auth.OIDC.Groups.Departments,
+				DefaultRole: cfg.Auth.OIDC.Groups.DefaultRole,
+			},
+			StateKey: stateKey,
+		})
+		oidcHandler = handlers.NewOIDCHandler(oidcProvider, userService, cfg.Auth.OIDC.PostLoginRedirect, !cfg.IsDevelopment())
+	}
+	healthHandler := handlers.NewHealthHandler(healthChecker, scheduler, func() gin.H {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil
+		}
+		return gin.H{"db_pool": sqlDB.Stats()}
+	})
 
 	// Setup Router
-	router := gin.Default()
+	router := gin.New()
+	// Tanpa proxy tepercaya X-Forwarded-For diabaikan, jadi client tidak bisa memalsukan IP-nya
+	var trustedProxies []string
+	if len(cfg.Server.TrustedProxies) > 0 {
+		trustedProxies = cfg.Server.TrustedProxies
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		slog.Error("Invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(appLogger))
+	router.Use(middleware.Metrics(appMetrics))
+	router.Use(gin.Recovery())
+	router.Use(middleware.ErrorHandler())
 
 	// Config CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		AllowCredentials: true,
-		MaxAge:           24 * time.Hour,
-	}))
-
-	// Define route
-	api := router.Group("/api")
-	{
-		// Public routes
-		api.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{
-				"status": "ok",
-				"message": "Service is healthy",
-			})
+	corsMiddleware, err := middleware.CORS(middleware.CORSOptions{
+		AllowOrigins:     cfg.CORS.AllowOrigins,
+		AllowMethods:     cfg.CORS.AllowMethods,
+		AllowHeaders:     cfg.CORS.AllowHeaders,
+		ExposeHeaders:    cfg.CORS.ExposeHeaders,
+		AllowCredentials: *cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge.Duration(),
+	})
+	if err != nil {
+		slog.Error("Invalid CORS configuration", "error", err)
+		os.Exit(1)
+	}
+	router.Use(corsMiddleware)
+
+	registerRoutes(router, routeDeps{
+		authService: authService,
+		rateLimiter: rateLimiter,
+		metrics:     appMetrics.Handler(),
+
+		userHandler:        userHandler,
+		toolkitHandler:     toolkitHandler,
+		categoryHandler:    categoryHandler,
+		loanHandler:        loanHandler,
+		maintenanceHandler: maintenanceHandler,
+		inspectionHandler:  inspectionHandler,
+		incidentHandler:    incidentHandler,
+		reportHandler:      reportHandler,
+		attachmentHandler:  attachmentHandler,
+		labelHandler:       labelHandler,
+		bundleHandler:      bundleHandler,
+		locationHandler:    locationHandler,
+		transferHandler:    transferHandler,
+		scanHandler:        scanHandler,
+		searchHandler:      searchHandler,
+		apiTokenHandler:    apiTokenHandler,
+		twoFactorHandler:   twoFactorHandler,
+		oidcHandler:        oidcHandler,
+		healthHandler:      healthHandler,
+	})
+
+	// SIGTERM/SIGINT -> drain request, stop worker, tutup pool DB
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(server.Config{
+		Addr:              cfg.Server.Addr(),
+		ReadTimeout:       cfg.Server.ReadTimeout.Duration(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Duration(),
+		WriteTimeout:      cfg.Server.WriteTimeout.Duration(),
+		IdleTimeout:       cfg.Server.IdleTimeout.Duration(),
+	}, router)
+
+	slog.Info("Starting server", "port", cfg.Server.Port, "environment", cfg.Environment)
+	err = server.Run(ctx, srv, cfg.Server.ShutdownTimeout.Duration(),
+		scheduler.Stop,
+		func(context.Context) error { return database.Close(db) },
+	)
+	if err != nil {
+		slog.Error("Server stopped with error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// openStorage backend file upload sesuai storage.driver
+func openStorage(ctx context.Context, cfg config.StorageConfig) (storage.Storage, error) {
+	if cfg.Driver == config.StorageDriverS3 {
+		return storage.NewS3(ctx, storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			Region:    cfg.S3.Region,
+			UseSSL:    cfg.S3.UseSSL,
 		})
-		api.POST("/auth/login", userHandler.Login)
-
-		// Protected routes
-		protected := api.Group("")
-		protected.Use(authService.RequireAuth())
-		{
-			// Current user
-			protected.GET("/auth/me", userHandler.GetCurrentUser)
-
-			// User routes - Admin only
-			users := protected.Group("/users")
-			users.Use(authService.RequireAdmin())
-			{
-				users.POST("", userHandler.Create)
-				users.GET("", userHandler.GetAll)
-				users.POST("/search", userHandler.GetAll)
-				users.GET("/:id", userHandler.GetByID)
-				users.PUT("/:id", userHandler.Update)
-				users.DELETE("/:id", userHandler.Delete)
-			}
-
-			// Toolkit routes - Admin & user
-			toolkits := protected.Group("/toolkits")
-			{
-				// Admin only
-				toolkitsAdmin := toolkits.Group("")
-				toolkitsAdmin.Use(authService.RequireAdmin())
-				{
-					toolkitsAdmin.POST("", toolkitHandler.Create)
-					toolkitsAdmin.PUT("/:id", toolkitHandler.Update)
-					toolkitsAdmin.DELETE("/:id", toolkitHandler.Delete)
-					toolkitsAdmin.PATCH("/:id/stock", toolkitHandler.UpdateStock)
-				}
-
-				// All authenticated users
-				toolkits.GET("", toolkitHandler.GetAll)
-				toolkits.POST("/search", toolkitHandler.GetAll)
-				toolkits.GET("/:id", toolkitHandler.GetByID)
-			}
-
-			// Category routes - Admin only
-			categories := protected.Group("/categories")
-			categories.Use(authService.RequireAdmin())
-			{
-				categories.POST("", categoryHandler.Create)
-				categories.GET("", categoryHandler.GetAll)
-				categories.GET("/:id", categoryHandler.GetByID)
-				categories.PUT("/:id", categoryHandler.Update)
-				categories.DELETE("/:id", categoryHandler.Delete)
-				categories.GET("/tree", categoryHandler.GetTree)
-			}
-
-			// Loan routes
-			loans := protected.Group("/loans")
-			{
-				loans.POST("", loanHandler.Create)
-				loans.GET("", loanHandler.GetAll)
-				loans.GET("/:id", loanHandler.GetByID)
-				loans.PUT("/:id", loanHandler.Update)
-				loans.DELETE("/:id", loanHandler.Delete)
-			}
-		}
 	}
+	return storage.NewLocal(cfg.Local.Dir)
+}
 
-	log.Printf("Starting server on port %s...", cfg.ServerPort)
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+// loanPolicy kebijakan peminjaman dari config
+func loanPolicy(cfg config.LoanConfig) services.LoanPolicy {
+	roles := make(map[string]time.Duration, len(cfg.RoleDurations))
+	for role, d := range cfg.RoleDurations {
+		roles[role] = d.Duration()
+	}
+	return services.LoanPolicy{
+		DefaultDuration: cfg.DefaultDuration.Duration(),
+		RoleDurations:   roles,
+		MaxActiveLoans:  cfg.MaxActiveLoans,
+		BlockOverdue:    cfg.BlockOverdue,
 	}
 }
@@ -0,0 +1,48 @@
+package auth
+
+import "strings"
+
+// rolePriority kalau user ada di beberapa group, role dengan prioritas tertinggi menang
+var rolePriority = map[string]int{"user": 1, "technician": 2, "admin": 3}
+
+// GroupMapping mapping group dari IdP/directory ke role dan department lokal.
+// Nama group dicocokkan case-insensitive.
+type GroupMapping struct {
+	Roles       map[string]string
+	Departments map[string]string
+	DefaultRole string
+}
+
+// Resolve role dan department untuk daftar group. Role dan department kosong kalau tidak ada
+// group yang cocok, supaya role yang diatur admin tidak ditimpa default.
+func (m GroupMapping) Resolve(groups []string) (role, department string) {
+	for _, group := range groups {
+		if r, ok := lookupFold(m.Roles, group); ok && rolePriority[r] > rolePriority[role] {
+			role = r
+		}
+		if d, ok := lookupFold(m.Departments, group); ok && department == "" {
+			department = d
+		}
+	}
+	return role, department
+}
+
+// Default role untuk user baru yang tidak cocok dengan group mana pun
+func (m GroupMapping) Default() string {
+	if m.DefaultRole == "" {
+		return "user"
+	}
+	return m.DefaultRole
+}
+
+func lookupFold(m map[string]string, key string) (string, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -1,38 +1,62 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("secrect-key-rahasia")
+// APITokenPrefix penanda personal API token di header Authorization
+const APITokenPrefix = "tkm_"
+
+// Purpose token sementara di tengah login dua langkah. Token dengan purpose
+// hanya diterima RequireAuth kalau route mengizinkannya secara eksplisit.
+const (
+	PurposeTwoFactor      = "2fa"
+	PurposeTwoFactorSetup = "2fa_setup"
+)
 
 type JWTClaim struct {
 	UserID   int    `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Purpose  string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
+
+	// Terisi kalau request diautentikasi dengan API token, bukan JWT
+	TokenID int      `json:"-"`
+	Scopes  []string `json:"-"`
+}
+
+// APITokenAuthenticator validasi personal API token, diimplementasi di layer service
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, token, clientIP string) (*JWTClaim, error)
 }
 
 type AuthConfig struct {
 	SecretKey     string
 	TokenDuration time.Duration
+	Issuer        string
 }
 
 type AuthService struct {
-	config AuthConfig
+	config    AuthConfig
+	apiTokens APITokenAuthenticator
 }
 
 func NewAuthService(config AuthConfig) *AuthService {
-	if config.SecretKey == "" {
-		config.SecretKey = "secrect-key-rahasia"
-	}
 	if config.TokenDuration == 0 {
 		config.TokenDuration = 24 * time.Hour
 	}
+	if config.Issuer == "" {
+		config.Issuer = "toolkit-management"
+	}
 
 	return &AuthService{
 		config: config,
@@ -40,14 +64,38 @@ func NewAuthService(config AuthConfig) *AuthService {
 }
 
 func (s *AuthService) GenerateToken(userID int, username, role string) (string, error) {
+	return s.signToken(userID, username, role, "", "", s.config.TokenDuration)
+}
+
+// GenerateChallengeToken token berumur pendek untuk langkah kedua login (verifikasi / setup 2FA).
+// challengeID (claim jti) dipakai untuk membatalkan challenge di server, boleh kosong.
+func (s *AuthService) GenerateChallengeToken(userID int, username, role, purpose, challengeID string, ttl time.Duration) (string, error) {
+	return s.signToken(userID, username, role, purpose, challengeID, ttl)
+}
+
+// ValidateChallengeToken hanya menerima token dengan purpose yang diminta
+func (s *AuthService) ValidateChallengeToken(tokenString, purpose string) (*JWTClaim, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+func (s *AuthService) signToken(userID int, username, role, purpose, id string, ttl time.Duration) (string, error) {
 	claims := &JWTClaim{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Purpose:  purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.TokenDuration)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "toolkit-management",
+			Issuer:    s.config.Issuer,
+			ID:        id,
 		},
 	}
 
@@ -55,6 +103,16 @@ func (s *AuthService) GenerateToken(userID int, username, role string) (string,
 	return token.SignedString([]byte(s.config.SecretKey))
 }
 
+// UseAPITokens aktifkan autentikasi API token di RequireAuth
+func (s *AuthService) UseAPITokens(authenticator APITokenAuthenticator) {
+	s.apiTokens = authenticator
+}
+
+// TokenDuration masa berlaku token yang diterbitkan
+func (s *AuthService) TokenDuration() time.Duration {
+	return s.config.TokenDuration
+}
+
 func (s *AuthService) ValidateToken(tokenString string) (*JWTClaim, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(s.config.SecretKey), nil
@@ -85,12 +143,13 @@ func GetCurrentUser(c *gin.Context) (*JWTClaim, error) {
 	return claims, nil
 }
 
-func (s *AuthService) RequireAuth() gin.HandlerFunc {
+// RequireAuth terima JWT sesi atau API token. allowedPurposes mengizinkan token
+// sementara tertentu, mis. token setup 2FA di endpoint enrolment.
+func (s *AuthService) RequireAuth(allowedPurposes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			c.JSON(401, gin.H{"success": false, "error": "Authorization header required"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, "Authorization header required")
 			return
 		}
 
@@ -98,10 +157,18 @@ func (s *AuthService) RequireAuth() gin.HandlerFunc {
 			tokenString = tokenString[7:]
 		}
 
+		if strings.HasPrefix(tokenString, APITokenPrefix) && s.apiTokens != nil {
+			s.authenticateAPIToken(c, tokenString)
+			return
+		}
+
 		claims, err := s.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(401, gin.H{"success": false, "error": "Invalid token"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+		if claims.Purpose != "" && !slices.Contains(allowedPurposes, claims.Purpose) {
+			abortWithError(c, http.StatusUnauthorized, "Invalid token")
 			return
 		}
 
@@ -110,13 +177,52 @@ func (s *AuthService) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// authenticateAPIToken validasi API token lalu cek scope read/write sesuai method
+func (s *AuthService) authenticateAPIToken(c *gin.Context, token string) {
+	claims, err := s.apiTokens.AuthenticateAPIToken(c.Request.Context(), token, c.ClientIP())
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Invalid or expired API token")
+		return
+	}
+
+	required := "write"
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		required = "read"
+	}
+	if !claims.HasScope(required) {
+		abortWithError(c, http.StatusForbidden, "API token is missing the '"+required+"' scope")
+		return
+	}
+
+	c.Set("user", claims)
+	c.Next()
+}
+
+// IsAPIToken true kalau claims berasal dari API token
+func (c *JWTClaim) IsAPIToken() bool {
+	return c.TokenID != 0
+}
+
+// HasScope cek scope API token. Sesi JWT biasa dianggap punya semua scope.
+func (c *JWTClaim) HasScope(scope string) bool {
+	if !c.IsAPIToken() {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // Check role
 func (s *AuthService) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, err := GetCurrentUser(c)
 		if err != nil {
-			c.JSON(401, gin.H{"success": false, "error": "Unauthorized"})
-			c.Abort()
+			abortWithError(c, http.StatusUnauthorized, "Unauthorized")
 			return
 		}
 
@@ -127,8 +233,7 @@ func (s *AuthService) RequireRole(roles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(403, gin.H{"success": false, "error": "Insufficient permissions"})
-		c.Abort()
+		abortWithError(c, http.StatusForbidden, "Insufficient permissions")
 	}
 }
 
@@ -136,3 +241,15 @@ func (s *AuthService) RequireRole(roles ...string) gin.HandlerFunc {
 func (s *AuthService) RequireAdmin() gin.HandlerFunc {
 	return s.RequireRole("admin")
 }
+
+// Check admin atau technician
+func (s *AuthService) RequireStaff() gin.HandlerFunc {
+	return s.RequireRole("admin", "technician")
+}
+
+// abortWithError set status dan push error, dirender jadi problem+json oleh middleware.ErrorHandler
+func abortWithError(c *gin.Context, status int, message string) {
+	c.Status(status)
+	_ = c.Error(errors.New(message))
+	c.Abort()
+}
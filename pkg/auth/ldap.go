@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials username tidak dikenal atau password salah
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+type LDAPConfig struct {
+	// URL ldap://host:389 atau ldaps://host:636
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN service account untuk mencari DN user. Kosong = anonymous bind.
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter %s diganti username (sudah di-escape), mis. (sAMAccountName=%s) untuk AD
+	UserFilter string
+	// GroupBaseDN + GroupFilter untuk direktori tanpa memberOf; %s diganti DN user
+	GroupBaseDN string
+	GroupFilter string
+
+	IDAttribute         string
+	UsernameAttribute   string
+	EmailAttribute      string
+	NameAttribute       string
+	DepartmentAttribute string
+	GroupAttribute      string
+
+	Groups  GroupMapping
+	Timeout time.Duration
+}
+
+// LDAPIdentity user yang berhasil bind ke direktori
+type LDAPIdentity struct {
+	DN       string
+	ID       string
+	Username string
+	Email    string
+	FullName string
+	Groups   []string
+	// Role kosong kalau tidak ada group yang cocok, DefaultRole dipakai untuk user baru
+	Role        string
+	DefaultRole string
+	Department  string
+}
+
+// ldapConn subset *ldap.Conn yang dipakai, supaya bisa diganti fake
+type ldapConn interface {
+	Bind(username, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAPDirectory autentikasi bind: cari DN user dengan service account, lalu bind ulang
+// sebagai user tersebut untuk memverifikasi password.
+type LDAPDirectory struct {
+	config LDAPConfig
+	dial   func(timeout time.Duration) (ldapConn, error)
+}
+
+func NewLDAPDirectory(config LDAPConfig) *LDAPDirectory {
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	if config.IDAttribute == "" {
+		config.IDAttribute = "entryUUID"
+	}
+	if config.UsernameAttribute == "" {
+		config.UsernameAttribute = "uid"
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	if config.NameAttribute == "" {
+		config.NameAttribute = "cn"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	d := &LDAPDirectory{config: config}
+	d.dial = d.dialLDAP
+	return d
+}
+
+func (d *LDAPDirectory) dialLDAP(timeout time.Duration) (ldapConn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: d.config.InsecureSkipVerify}
+	if u, err := url.Parse(d.config.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	conn.SetTimeout(timeout)
+
+	if d.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// Authenticate mengembalikan ErrInvalidCredentials kalau user tidak ada, ambigu, atau password salah.
+// Error lain berarti direktori tidak bisa dihubungi atau salah konfigurasi. Timeout mengikuti
+// deadline ctx kalau lebih pendek dari config, dan koneksi ditutup kalau ctx dibatalkan.
+func (d *LDAPDirectory) Authenticate(ctx context.Context, username, password string) (*LDAPIdentity, error) {
+	// Password kosong = unauthenticated bind, yang di banyak server dianggap sukses
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	timeout := d.config.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		return nil, context.DeadlineExceeded
+	}
+
+	conn, err := d.dial(timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	identity, err := d.authenticate(conn, username, password, timeout)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return identity, err
+}
+
+func (d *LDAPDirectory) authenticate(conn ldapConn, username, password string, timeout time.Duration) (*LDAPIdentity, error) {
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+
+	entry, err := d.findUser(conn, username, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user bind: %w", err)
+	}
+
+	// Pencarian group pakai service account lagi, hak akses user biasa sering terbatas
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+	groups, err := d.groups(conn, entry, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.identity(entry, username, groups), nil
+}
+
+func (d *LDAPDirectory) findUser(conn ldapConn, username string, timeout time.Duration) (*ldap.Entry, error) {
+	attributes := []string{
+		d.config.IDAttribute, d.config.UsernameAttribute, d.config.EmailAttribute,
+		d.config.NameAttribute, d.config.GroupAttribute,
+	}
+	if d.config.DepartmentAttribute != "" {
+		attributes = append(attributes, d.config.DepartmentAttribute)
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, timeLimit(timeout), false,
+		fmt.Sprintf(d.config.UserFilter, ldap.EscapeFilter(username)),
+		attributes, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user search: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+// groups nama group (CN) dan DN lengkapnya, supaya mapping bisa pakai salah satu
+func (d *LDAPDirectory) groups(conn ldapConn, entry *ldap.Entry, timeout time.Duration) ([]string, error) {
+	dns := entry.GetEqualFoldAttributeValues(d.config.GroupAttribute)
+
+	if d.config.GroupBaseDN != "" && d.config.GroupFilter != "" {
+		result, err := conn.Search(ldap.NewSearchRequest(
+			d.config.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+			0, timeLimit(timeout), false,
+			fmt.Sprintf(d.config.GroupFilter, ldap.EscapeFilter(entry.DN)),
+			[]string{"cn"}, nil,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("ldap group search: %w", err)
+		}
+		for _, group := range result.Entries {
+			dns = append(dns, group.DN)
+		}
+	}
+
+	groups := make([]string, 0, len(dns)*2)
+	for _, dn := range dns {
+		if cn := commonName(dn); cn != "" {
+			groups = append(groups, cn)
+		}
+		groups = append(groups, dn)
+	}
+	return groups, nil
+}
+
+func (d *LDAPDirectory) identity(entry *ldap.Entry, username string, groups []string) *LDAPIdentity {
+	identity := &LDAPIdentity{
+		DN:       entry.DN,
+		ID:       attributeID(entry.GetEqualFoldRawAttributeValue(d.config.IDAttribute)),
+		Username: entry.GetEqualFoldAttributeValue(d.config.UsernameAttribute),
+		Email:    entry.GetEqualFoldAttributeValue(d.config.EmailAttribute),
+		FullName: entry.GetEqualFoldAttributeValue(d.config.NameAttribute),
+		Groups:   groups,
+	}
+	if identity.ID == "" {
+		identity.ID = strings.ToLower(entry.DN)
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+
+	identity.Role, identity.Department = d.config.Groups.Resolve(groups)
+	identity.DefaultRole = d.config.Groups.Default()
+	// Atribut department di direktori lebih spesifik daripada mapping group
+	if d.config.DepartmentAttribute != "" {
+		if department := entry.GetEqualFoldAttributeValue(d.config.DepartmentAttribute); department != "" {
+			identity.Department = department
+		}
+	}
+	return identity
+}
+
+// timeLimit batas waktu search dalam detik, minimal 1 karena 0 berarti tanpa batas
+func timeLimit(timeout time.Duration) int {
+	return max(int(timeout/time.Second), 1)
+}
+
+// attributeID objectGUID (AD) berupa binary, di-hex supaya bisa disimpan sebagai string
+func attributeID(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	if utf8.Valid(raw) {
+		return string(raw)
+	}
+	return hex.EncodeToString(raw)
+}
+
+func commonName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return ""
+}
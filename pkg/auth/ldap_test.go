@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	testServiceDN = "cn=svc-toolkit,ou=services,dc=example,dc=com"
+	testUserDN    = "uid=budi,ou=people,dc=example,dc=com"
+	testGroupBase = "ou=groups,dc=example,dc=com"
+)
+
+// fakeLDAP direktori di memori. block membuat Search menunggu sampai koneksi ditutup.
+type fakeLDAP struct {
+	mu        sync.Mutex
+	passwords map[string]string
+	users     []*ldap.Entry
+	groups    []*ldap.Entry
+	block     chan struct{}
+
+	binds    []string
+	searches []*ldap.SearchRequest
+	closed   bool
+}
+
+func (f *fakeLDAP) Bind(dn, password string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.binds = append(f.binds, dn)
+	if want, ok := f.passwords[dn]; !ok || want != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (f *fakeLDAP) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	f.mu.Lock()
+	f.searches = append(f.searches, req)
+	block := f.block
+	f.mu.Unlock()
+
+	if block != nil {
+		<-block
+		return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection closed"))
+	}
+	if req.BaseDN == testGroupBase {
+		return &ldap.SearchResult{Entries: f.groups}, nil
+	}
+	return &ldap.SearchResult{Entries: f.users}, nil
+}
+
+func (f *fakeLDAP) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed && f.block != nil {
+		close(f.block)
+	}
+	f.closed = true
+	return nil
+}
+
+func newFakeLDAP() *fakeLDAP {
+	return &fakeLDAP{
+		passwords: map[string]string{testServiceDN: "svc-secret", testUserDN: "s3cret"},
+		users: []*ldap.Entry{ldap.NewEntry(testUserDN, map[string][]string{
+			"entryUUID": {"6f1c0a52-1111-4d3c-9a53-0d8f1f0b7a10"},
+			"uid":       {"budi"},
+			"mail":      {"budi@example.com"},
+			"cn":        {"Budi Santoso"},
+			"memberOf":  {"cn=NOC,ou=groups,dc=example,dc=com"},
+		})},
+		groups: []*ldap.Entry{ldap.NewEntry("cn=Toolkit-Admins,ou=groups,dc=example,dc=com", nil)},
+	}
+}
+
+func newTestDirectory(conn *fakeLDAP) *LDAPDirectory {
+	d := NewLDAPDirectory(LDAPConfig{
+		BindDN:       testServiceDN,
+		BindPassword: "svc-secret",
+		BaseDN:       "dc=example,dc=com",
+		GroupBaseDN:  testGroupBase,
+		GroupFilter:  "(member=%s)",
+		Groups: GroupMapping{
+			Roles:       map[string]string{"noc": "technician", "toolkit-admins": "admin"},
+			Departments: map[string]string{"NOC": "Network Operations"},
+		},
+	})
+	d.dial = func(time.Duration) (ldapConn, error) { return conn, nil }
+	return d
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	conn := newFakeLDAP()
+	identity, err := newTestDirectory(conn).Authenticate(context.Background(), "budi", "s3cret")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	want := &LDAPIdentity{
+		DN:       testUserDN,
+		ID:       "6f1c0a52-1111-4d3c-9a53-0d8f1f0b7a10",
+		Username: "budi",
+		Email:    "budi@example.com",
+		FullName: "Budi Santoso",
+		Groups: []string{
+			"NOC", "cn=NOC,ou=groups,dc=example,dc=com",
+			"Toolkit-Admins", "cn=Toolkit-Admins,ou=groups,dc=example,dc=com",
+		},
+		// memberOf dan GroupFilter digabung, role dengan prioritas tertinggi menang
+		Role:        "admin",
+		DefaultRole: "user",
+		Department:  "Network Operations",
+	}
+	if !reflect.DeepEqual(identity, want) {
+		t.Errorf("identity = %+v\nwant %+v", identity, want)
+	}
+
+	// Service account → user → service account lagi untuk group
+	if wantBinds := []string{testServiceDN, testUserDN, testServiceDN}; !reflect.DeepEqual(conn.binds, wantBinds) {
+		t.Errorf("binds = %v, want %v", conn.binds, wantBinds)
+	}
+	if len(conn.searches) != 2 {
+		t.Fatalf("searches = %d, want user and group search", len(conn.searches))
+	}
+	if filter := conn.searches[0].Filter; filter != "(uid=budi)" {
+		t.Errorf("user filter = %q", filter)
+	}
+	if filter := conn.searches[1].Filter; filter != "(member="+ldap.EscapeFilter(testUserDN)+")" {
+		t.Errorf("group filter = %q", filter)
+	}
+	if !conn.closed {
+		t.Error("connection not closed")
+	}
+}
+
+func TestLDAPAuthenticateUserSearch(t *testing.T) {
+	other := ldap.NewEntry("uid=budi,ou=contractors,dc=example,dc=com", map[string][]string{"uid": {"budi"}})
+
+	tests := []struct {
+		name    string
+		entries []*ldap.Entry
+		wantErr error
+	}{
+		{name: "no entry", entries: nil, wantErr: ErrInvalidCredentials},
+		{name: "one entry", entries: newFakeLDAP().users},
+		{name: "ambiguous", entries: append(newFakeLDAP().users, other), wantErr: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newFakeLDAP()
+			conn.users = tt.entries
+			_, err := newTestDirectory(conn).Authenticate(context.Background(), "budi", "s3cret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLDAPAuthenticateWrongPassword(t *testing.T) {
+	conn := newFakeLDAP()
+	_, err := newTestDirectory(conn).Authenticate(context.Background(), "budi", "wrong")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrInvalidCredentials", err)
+	}
+	if len(conn.searches) != 1 {
+		t.Errorf("searches = %d, group search must not run after a failed bind", len(conn.searches))
+	}
+}
+
+func TestLDAPAuthenticateServiceBindFails(t *testing.T) {
+	conn := newFakeLDAP()
+	conn.passwords[testServiceDN] = "rotated"
+	_, err := newTestDirectory(conn).Authenticate(context.Background(), "budi", "s3cret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want a directory error so other authenticators are tried", err)
+	}
+}
+
+func TestLDAPAuthenticateContext(t *testing.T) {
+	t.Run("deadline shortens search time limit", func(t *testing.T) {
+		conn := newFakeLDAP()
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+
+		if _, err := newTestDirectory(conn).Authenticate(ctx, "budi", "s3cret"); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		if limit := conn.searches[0].TimeLimit; limit != 2 {
+			t.Errorf("TimeLimit = %d, want 2 from ctx deadline", limit)
+		}
+	})
+
+	t.Run("expired context does not dial", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d := newTestDirectory(newFakeLDAP())
+		d.dial = func(time.Duration) (ldapConn, error) {
+			t.Error("dialed with a cancelled context")
+			return nil, errors.New("unreachable")
+		}
+		if _, err := d.Authenticate(ctx, "budi", "s3cret"); !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("cancel aborts a hanging search", func(t *testing.T) {
+		conn := newFakeLDAP()
+		conn.block = make(chan struct{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := newTestDirectory(conn).Authenticate(ctx, "budi", "s3cret"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/oauth2"
+)
+
+var ErrInvalidOIDCState = errors.New("invalid or expired OIDC login state")
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	Groups       GroupMapping
+	// StateKey kunci HMAC untuk cookie state login
+	StateKey []byte
+	StateTTL time.Duration
+}
+
+// DeriveStateKey kunci state turunan secret lain lewat HKDF-SHA256 dengan label tetap, supaya
+// cookie state tidak ditandatangani dengan kunci yang sama dengan access token
+func DeriveStateKey(secret []byte) []byte {
+	key := make([]byte, sha256.Size)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte("toolkit-management oidc state v1")), key)
+	return key
+}
+
+// OIDCIdentity identitas user dari ID token yang sudah diverifikasi
+type OIDCIdentity struct {
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	FullName      string
+	Groups        []string
+	// Role kosong kalau tidak ada group yang cocok, DefaultRole dipakai untuk user baru
+	Role        string
+	DefaultRole string
+	Department  string
+}
+
+// OIDCLoginState disimpan di cookie selama redirect ke IdP
+type OIDCLoginState struct {
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// OIDCProvider authorization code flow + PKCE. Discovery dilakukan saat pertama dipakai
+// supaya API tetap bisa start walau IdP sedang down.
+type OIDCProvider struct {
+	config OIDCConfig
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.StateTTL == 0 {
+		config.StateTTL = 10 * time.Minute
+	}
+	return &OIDCProvider{config: config}
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.config.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.config.Scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.config.ClientID})
+	return p.oauth, p.verifier, nil
+}
+
+// AuthCodeURL membuat state baru dan URL redirect ke IdP. Cookie berisi state yang ditandatangani.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context) (redirectURL, cookie string, err error) {
+	conf, _, err := p.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state := OIDCLoginState{
+		State:     randomToken(),
+		Nonce:     randomToken(),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().Add(p.config.StateTTL).Unix(),
+	}
+	cookie, err = p.signState(state)
+	if err != nil {
+		return "", "", err
+	}
+
+	redirectURL = conf.AuthCodeURL(state.State,
+		oauth2.S256ChallengeOption(state.Verifier),
+		oidc.Nonce(state.Nonce),
+	)
+	return redirectURL, cookie, nil
+}
+
+// Exchange tukar code dengan token, verifikasi ID token + nonce, lalu mapping group
+func (p *OIDCProvider) Exchange(ctx context.Context, cookie, state, code string) (*OIDCIdentity, error) {
+	loginState, err := p.parseState(cookie)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(loginState.State), []byte(state)) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	conf, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(loginState.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("oidc code exchange: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id_token: %w", err)
+	}
+	if !hmac.Equal([]byte(idToken.Nonce), []byte(loginState.Nonce)) {
+		return nil, errors.New("oidc: id_token nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("oidc: decode claims: %w", err)
+	}
+	return p.identity(idToken.Subject, claims), nil
+}
+
+func (p *OIDCProvider) identity(subject string, claims map[string]any) *OIDCIdentity {
+	str := func(key string) string {
+		v, _ := claims[key].(string)
+		return v
+	}
+
+	identity := &OIDCIdentity{
+		Subject:  subject,
+		Username: str("preferred_username"),
+		Email:    str("email"),
+		FullName: str("name"),
+		Groups:   stringList(claims[p.config.GroupsClaim]),
+	}
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+
+	if identity.Username == "" {
+		identity.Username, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if identity.FullName == "" {
+		identity.FullName = strings.TrimSpace(str("given_name") + " " + str("family_name"))
+	}
+	identity.Role, identity.Department = p.config.Groups.Resolve(identity.Groups)
+	identity.DefaultRole = p.config.Groups.Default()
+	return identity
+}
+
+func (p *OIDCProvider) signState(state OIDCLoginState) (string, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + p.mac(encoded), nil
+}
+
+func (p *OIDCProvider) parseState(cookie string) (*OIDCLoginState, error) {
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(p.mac(encoded))) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	var state OIDCLoginState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	if time.Now().Unix() > state.ExpiresAt {
+		return nil, ErrInvalidOIDCState
+	}
+	return &state, nil
+}
+
+func (p *OIDCProvider) mac(data string) string {
+	h := hmac.New(sha256.New, p.config.StateKey)
+	h.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// StateTTL umur cookie state
+func (p *OIDCProvider) StateTTL() time.Duration {
+	return p.config.StateTTL
+}
+
+// stringList claim group bisa array atau satu string
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		list := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func randomToken() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"toolkit-management/pkg/auth/oidctest"
+)
+
+func newTestOIDCProvider(idp *oidctest.Server) *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://toolkit.test/api/auth/oidc/callback",
+		Groups: GroupMapping{
+			Roles:       map[string]string{"Toolkit-Admins": "admin", "NOC": "technician"},
+			Departments: map[string]string{"NOC": "Network Operations"},
+			DefaultRole: "user",
+		},
+		StateKey: []byte("test-state-key"),
+	})
+}
+
+func TestOIDCExchange(t *testing.T) {
+	idp := oidctest.NewServer("toolkit", "secret")
+	defer idp.Close()
+	provider := newTestOIDCProvider(idp)
+	ctx := context.Background()
+
+	redirectURL, cookie, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := idp.Authorize(redirectURL, map[string]any{
+		"sub":            "u-1",
+		"email":          "budi@example.com",
+		"email_verified": true,
+		"given_name":     "Budi",
+		"family_name":    "Santoso",
+		"groups":         []string{"noc", "toolkit-admins"},
+	})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	identity, err := provider.Exchange(ctx, cookie, state, code)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := OIDCIdentity{
+		Subject:       "u-1",
+		Username:      "budi",
+		Email:         "budi@example.com",
+		EmailVerified: true,
+		FullName:      "Budi Santoso",
+		Role:          "admin",
+		DefaultRole:   "user",
+		Department:    "Network Operations",
+	}
+	if len(identity.Groups) != 2 {
+		t.Errorf("groups = %v, want both groups from the claim", identity.Groups)
+	}
+	identity.Groups = nil
+	if !reflect.DeepEqual(*identity, want) {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+
+	// Code sekali pakai
+	if _, err := provider.Exchange(ctx, cookie, state, code); err == nil {
+		t.Error("Exchange accepted a code that was already redeemed")
+	}
+}
+
+func TestOIDCExchangeNoGroupMatch(t *testing.T) {
+	idp := oidctest.NewServer("toolkit", "secret")
+	defer idp.Close()
+	provider := newTestOIDCProvider(idp)
+	ctx := context.Background()
+
+	redirectURL, cookie, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := idp.Authorize(redirectURL, map[string]any{"sub": "u-2", "email": "sari@example.com", "groups": "guests"})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	identity, err := provider.Exchange(ctx, cookie, state, code)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if identity.Role != "" || identity.Department != "" {
+		t.Errorf("role/department = %q/%q, want empty when no group matches", identity.Role, identity.Department)
+	}
+	if identity.DefaultRole != "user" {
+		t.Errorf("DefaultRole = %q, want user", identity.DefaultRole)
+	}
+	if identity.EmailVerified {
+		t.Error("EmailVerified = true without email_verified claim")
+	}
+}
+
+func TestOIDCExchangeRejectsState(t *testing.T) {
+	idp := oidctest.NewServer("toolkit", "secret")
+	defer idp.Close()
+	provider := newTestOIDCProvider(idp)
+	ctx := context.Background()
+
+	redirectURL, cookie, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, _, err := idp.Authorize(redirectURL, map[string]any{"sub": "u-1", "email": "budi@example.com"})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := provider.Exchange(ctx, cookie, "other-state", code); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("mismatched state: err = %v, want ErrInvalidOIDCState", err)
+	}
+	if _, err := provider.Exchange(ctx, cookie+"x", "", code); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("tampered cookie: err = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestDeriveStateKey(t *testing.T) {
+	secret := []byte("jwt-signing-secret-with-enough-length")
+	key := DeriveStateKey(secret)
+	if len(key) != 32 || bytes.Equal(key, secret) {
+		t.Fatalf("key = %x, want 32 bytes different from the JWT secret", key)
+	}
+	if !bytes.Equal(key, DeriveStateKey(secret)) {
+		t.Error("key is not stable across restarts")
+	}
+	if bytes.Equal(key, DeriveStateKey([]byte("rotated-jwt-signing-secret-value!"))) {
+		t.Error("different secrets give the same key")
+	}
+}
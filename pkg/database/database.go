@@ -1,35 +1,44 @@
 package database
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
 	"toolkit-management/config"
-	"toolkit-management/internal/models"
+	"toolkit-management/pkg/logger"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func InitDB(cfg *config.Config) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(cfg.GetDBConnectionString()), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{
+		Logger: logger.NewGormLogger(slog.Default(), cfg.Database.SlowQueryThreshold.Duration()),
 	})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to connect to database", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("Failed to get database pool", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime.Duration())
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime.Duration())
+
 	// Auto migrate the schemas
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Toolkit{},
-		&models.Loan{},
-		&models.Category{},
-	)
+	err = Migrate(db)
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		fatal("Failed to migrate database", err)
 	}
 
+	if err := migrateSearch(db); err != nil {
+		fatal("Failed to migrate search indexes", err)
+	}
+	markMigrated()
+
 	// Seed default admin user
 	SeedAdminUser(db)
 
@@ -37,6 +46,25 @@ func InitDB(cfg *config.Config) *gorm.DB {
 		SeedTestData(db)
 	}
 
-	log.Println("Database connected, migrated, and seeded successfully")
+	slog.Info("Database connected, migrated, and seeded successfully")
 	return db
 }
+
+// Migrate AutoMigrate semua model, juga dipakai test repository dengan database sungguhan
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(migratedModels...)
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Close menutup connection pool, dipanggil saat graceful shutdown
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"toolkit-management/internal/models"
+)
+
+// migratedModels semua model yang di-AutoMigrate, juga dipakai cek readiness
+var migratedModels = []interface{}{
+	&models.User{},
+	&models.Toolkit{},
+	&models.Loan{},
+	&models.Category{},
+	&models.APIToken{},
+	&models.TwoFactor{},
+	&models.RecoveryCode{},
+	&models.MaintenanceRecord{},
+	&models.MaintenanceSchedule{},
+	&models.ChecklistItem{},
+	&models.Inspection{},
+	&models.InspectionResult{},
+	&models.Incident{},
+	&models.IncidentEvidence{},
+	&models.StockAdjustment{},
+	&models.Attachment{},
+	&models.Bundle{},
+	&models.BundleComponent{},
+	&models.BundleLoan{},
+	&models.Location{},
+	&models.ToolkitStock{},
+	&models.Transfer{},
+	&models.TransferLine{},
+}
+
+var migrationState struct {
+	sync.RWMutex
+	completedAt time.Time
+}
+
+func markMigrated() {
+	migrationState.Lock()
+	defer migrationState.Unlock()
+	migrationState.completedAt = time.Now()
+}
+
+// MigratedAt waktu migrasi selesai di proses ini, zero kalau belum
+func MigratedAt() time.Time {
+	migrationState.RLock()
+	defer migrationState.RUnlock()
+	return migrationState.completedAt
+}
+
+// CheckMigrations readiness check: migrasi proses ini sudah selesai dan semua tabel/kolom ada
+func CheckMigrations(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if MigratedAt().IsZero() {
+			return errors.New("migrations have not completed")
+		}
+
+		migrator := db.WithContext(ctx).Migrator()
+		for _, model := range migratedModels {
+			if !migrator.HasTable(model) {
+				return fmt.Errorf("table for %T is missing", model)
+			}
+		}
+		if !migrator.HasColumn(&models.Toolkit{}, "search_vector") {
+			return errors.New("toolkits.search_vector is missing")
+		}
+		return nil
+	}
+}
+
+// CheckConnection readiness check: ping database
+func CheckConnection(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
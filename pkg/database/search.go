@@ -0,0 +1,40 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// searchMigrations menyiapkan full-text search (tsvector) dan trigram index.
+// Kolom generated tidak ada di struct model supaya AutoMigrate tidak menyentuhnya.
+var searchMigrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+	`ALTER TABLE toolkits ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(sku, '') || ' ' || coalesce(brand, '') || ' ' ||
+				coalesce(model, '') || ' ' || coalesce(serial_number, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+		) STORED`,
+	`ALTER TABLE toolkits ADD COLUMN IF NOT EXISTS search_text text
+		GENERATED ALWAYS AS (
+			coalesce(name, '') || ' ' || coalesce(sku, '') || ' ' || coalesce(brand, '') || ' ' ||
+			coalesce(model, '') || ' ' || coalesce(serial_number, '')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_toolkits_search_vector ON toolkits USING gin (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_toolkits_search_text_trgm ON toolkits USING gin (search_text gin_trgm_ops)`,
+
+	`CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING gin (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON users USING gin (full_name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_loans_purpose_trgm ON loans USING gin (purpose gin_trgm_ops)`,
+}
+
+func migrateSearch(db *gorm.DB) error {
+	for _, stmt := range searchMigrations {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
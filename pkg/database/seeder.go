@@ -1,7 +1,7 @@
 package database
 
 import (
-	"log"
+	"log/slog"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -13,17 +13,17 @@ import (
 func SeedAdminUser(db *gorm.DB) {
 	var userCount int64
 	if err := db.Model(&models.User{}).Count(&userCount).Error; err != nil {
-		log.Printf("Error during initial user count check: %v", err)
+		slog.Error("Error during initial user count check", "error", err)
 		return
 	}
 
 	if userCount == 0 {
-		log.Println("Admin seed: Creating default admin user.")
+		slog.Info("Admin seed: Creating default admin user.")
 
 		// Hash password
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
 		if err != nil {
-			log.Printf("Admin seed: Failed to hash password: %v", err)
+			slog.Error("Admin seed: Failed to hash password", "error", err)
 			return
 		}
 
@@ -39,14 +39,14 @@ func SeedAdminUser(db *gorm.DB) {
 		}
 
 		if err := db.Create(adminUser).Error; err != nil {
-			log.Printf("Admin seed: Failed to create admin user: %v", err)
+			slog.Error("Admin seed: Failed to create admin user", "error", err)
 			return
 		}
 
-		log.Println("Admin seed: Default admin user created successfully.")
+		slog.Info("Admin seed: Default admin user created successfully.")
 
 	} else {
-		log.Println("Admin seed: Skipping, users found in database.")
+		slog.Info("Admin seed: Skipping, users found in database.")
 	}
 }
 
@@ -55,7 +55,7 @@ func SeedTestData(db *gorm.DB) {
 	db.Model(&models.User{}).Count(&userCount)
 
 	if userCount > 0 {
-		log.Println("Test data seed: Creating sample demo user.")
+		slog.Info("Test data seed: Creating sample demo user.")
 
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("user123"), bcrypt.DefaultCost)
 
@@ -75,14 +75,14 @@ func SeedTestData(db *gorm.DB) {
 
 		if result.Error == gorm.ErrRecordNotFound {
 			if err := db.Create(sampleUser).Error; err != nil {
-				log.Printf("Test data seed: Failed to create demo user: %v", err)
+				slog.Error("Test data seed: Failed to create demo user", "error", err)
 				return
 			}
-			log.Println("Test data seed: Sample demo user created successfully.")
+			slog.Info("Test data seed: Sample demo user created successfully.")
 		} else if result.Error != nil {
-			log.Printf("Test data seed: Error checking for existing demo user: %v", result.Error)
+			slog.Error("Test data seed: Error checking for existing demo user", "error", result.Error)
 		} else {
-			log.Println("Test data seed: Sample demo user already exists, skipping.")
+			slog.Info("Test data seed: Sample demo user already exists, skipping.")
 		}
 	}
 }
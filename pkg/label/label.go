@@ -0,0 +1,297 @@
+// Package label merender label toolkit siap cetak (PNG per label, PDF untuk banyak label)
+// berisi QR code atau barcode Code128 plus nama, kategori dan kode item.
+package label
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Symbology jenis kode yang dicetak
+const (
+	SymbologyQR      = "qr"
+	SymbologyCode128 = "code128"
+)
+
+const (
+	DefaultPreset = "62x29"
+	DefaultDPI    = 300
+
+	mmPerInch = 25.4
+	// Quiet zone minimal per standar: 4 modul untuk QR, 10 modul untuk Code128
+	qrQuietZone      = 4
+	code128QuietZone = 10
+)
+
+var (
+	// ErrTooSmall kode tidak muat di label pada DPI yang diminta
+	ErrTooSmall = errors.New("label: code does not fit on the label at this size and DPI")
+	// ErrEncode isi label tidak bisa di-encode dengan symbology yang diminta
+	ErrEncode = errors.New("label: code cannot be encoded")
+	// ErrPageTooSmall ukuran label lebih besar dari lembar A4
+	ErrPageTooSmall = errors.New("label: label does not fit on an A4 sheet")
+)
+
+// Size ukuran label dalam milimeter
+type Size struct {
+	WidthMM  float64 `json:"width_mm"`
+	HeightMM float64 `json:"height_mm"`
+}
+
+// Presets ukuran label printer yang umum dipakai
+var Presets = map[string]Size{
+	"50x25":   {50, 25},   // Zebra/TSC 2" x 1"
+	"57x32":   {57, 32},   // Dymo 11354
+	"62x29":   {62, 29},   // Brother DK-11209
+	"62x100":  {62, 100},  // Brother DK-11202
+	"89x36":   {89, 36},   // Dymo 99012
+	"100x50":  {100, 50},  // Zebra/TSC 4" x 2"
+	"102x152": {102, 152}, // 4" x 6"
+}
+
+// PresetNames nama preset terurut, untuk pesan validasi
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Item isi satu label
+type Item struct {
+	// Code nilai yang di-encode ke QR/Code128 dan dicetak di bawah teks
+	Code     string
+	Title    string
+	Subtitle string
+}
+
+type Options struct {
+	Size      Size
+	DPI       int
+	Symbology string
+}
+
+func (o Options) pixels() (int, int) {
+	return mmToPixels(o.Size.WidthMM, o.DPI), mmToPixels(o.Size.HeightMM, o.DPI)
+}
+
+// PixelCount jumlah pixel satu label yang dirender pada ukuran dan DPI ini
+func (o Options) PixelCount() int {
+	width, height := o.pixels()
+	return width * height
+}
+
+func mmToPixels(mm float64, dpi int) int {
+	return int(mm / mmPerInch * float64(dpi))
+}
+
+// Render satu label sebagai gambar hitam putih seukuran label pada DPI yang diminta.
+// Label melebar dengan QR: kode di kiri, teks di kanan. Selain itu kode di atas, teks di bawah.
+func Render(item Item, opts Options) (image.Image, error) {
+	width, height := opts.pixels()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	margin := min(width, height) / 20
+	area := image.Rect(margin, margin, width-margin, height-margin)
+
+	code, quiet, err := encode(item.Code, opts.Symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	var codeArea, textArea image.Rectangle
+	switch {
+	case opts.Symbology == SymbologyQR && area.Dx() > area.Dy():
+		codeArea = image.Rect(area.Min.X, area.Min.Y, area.Min.X+area.Dy(), area.Max.Y)
+		textArea = image.Rect(codeArea.Max.X+margin, area.Min.Y, area.Max.X, area.Max.Y)
+	case opts.Symbology == SymbologyQR:
+		side := min(area.Dx(), area.Dy()*3/5)
+		left := area.Min.X + (area.Dx()-side)/2
+		codeArea = image.Rect(left, area.Min.Y, left+side, area.Min.Y+side)
+		textArea = image.Rect(area.Min.X, codeArea.Max.Y, area.Max.X, area.Max.Y)
+	default:
+		codeArea = image.Rect(area.Min.X, area.Min.Y, area.Max.X, area.Min.Y+area.Dy()*9/20)
+		textArea = image.Rect(area.Min.X, codeArea.Max.Y+margin/2, area.Max.X, area.Max.Y)
+	}
+
+	if err := drawCode(img, code, quiet, codeArea, opts.Symbology == SymbologyQR); err != nil {
+		return nil, err
+	}
+	if err := drawText(img, item, textArea, opts.DPI); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// encode semua kegagalan dibungkus ErrEncode karena penyebabnya selalu input
+func encode(content, symbology string) (barcode.Barcode, int, error) {
+	if content == "" {
+		return nil, 0, fmt.Errorf("%w: code is empty", ErrEncode)
+	}
+	var (
+		code  barcode.Barcode
+		quiet int
+		err   error
+	)
+	switch symbology {
+	case SymbologyQR:
+		code, err = qr.Encode(content, qr.M, qr.Auto)
+		quiet = qrQuietZone
+	case SymbologyCode128:
+		code, err = code128.Encode(content)
+		quiet = code128QuietZone
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown symbology %q", ErrEncode, symbology)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrEncode, err)
+	}
+	return code, quiet, nil
+}
+
+// drawCode gambar kode dengan lebar modul bilangan bulat supaya tetap tajam untuk scanner
+func drawCode(img *image.Gray, code barcode.Barcode, quiet int, area image.Rectangle, square bool) error {
+	modulesX := code.Bounds().Dx()
+	modulesY := code.Bounds().Dy()
+
+	scale := area.Dx() / (modulesX + 2*quiet)
+	if square {
+		scale = min(scale, area.Dy()/(modulesY+2*quiet))
+	}
+	if scale < 1 {
+		return ErrTooSmall
+	}
+
+	codeWidth := modulesX * scale
+	codeHeight := area.Dy()
+	if square {
+		codeHeight = modulesY * scale
+	}
+	left := area.Min.X + (area.Dx()-codeWidth)/2
+	top := area.Min.Y + (area.Dy()-codeHeight)/2
+
+	for mx := 0; mx < modulesX; mx++ {
+		for my := 0; my < modulesY; my++ {
+			if !isDark(code.At(code.Bounds().Min.X+mx, code.Bounds().Min.Y+my)) {
+				continue
+			}
+			cell := image.Rect(left+mx*scale, top, left+(mx+1)*scale, top+codeHeight)
+			if square {
+				cell = image.Rect(left+mx*scale, top+my*scale, left+(mx+1)*scale, top+(my+1)*scale)
+			}
+			draw.Draw(img, cell, image.Black, image.Point{}, draw.Src)
+		}
+	}
+	return nil
+}
+
+func isDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 128
+}
+
+var (
+	fontsOnce sync.Once
+	fontsErr  error
+	regular   *opentype.Font
+	bold      *opentype.Font
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		regular, fontsErr = opentype.Parse(goregular.TTF)
+		if fontsErr == nil {
+			bold, fontsErr = opentype.Parse(gobold.TTF)
+		}
+	})
+	return fontsErr
+}
+
+// drawText tiga baris: judul tebal, subjudul, kode. Teks yang kepanjangan dipotong dengan elipsis.
+func drawText(img *image.Gray, item Item, area image.Rectangle, dpi int) error {
+	if err := loadFonts(); err != nil {
+		return err
+	}
+
+	lines := []struct {
+		text string
+		font *opentype.Font
+	}{
+		{item.Title, bold},
+		{item.Subtitle, regular},
+		{item.Code, regular},
+	}
+
+	lineHeight := area.Dy() / len(lines)
+	if lineHeight <= 0 {
+		return ErrTooSmall
+	}
+	// Tinggi huruf ~70% tinggi baris, dikonversi ke point untuk DPI label.
+	// Baris kode harus terbaca utuh, jadi ukuran huruf diperkecil sampai kode muat.
+	points := float64(lineHeight) * 0.7 * 72 / float64(dpi)
+	codeWidth, err := measure(regular, item.Code, points, dpi)
+	if err != nil {
+		return err
+	}
+	if codeWidth > area.Dx() {
+		points = points * float64(area.Dx()) / float64(codeWidth)
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line.text) == "" {
+			continue
+		}
+		face, err := opentype.NewFace(line.font, &opentype.FaceOptions{Size: points, DPI: float64(dpi), Hinting: font.HintingFull})
+		if err != nil {
+			return err
+		}
+		drawer := &font.Drawer{Dst: img, Src: image.Black, Face: face}
+		text := fit(drawer, line.text, area.Dx())
+		baseline := area.Min.Y + i*lineHeight + (lineHeight+face.Metrics().Ascent.Ceil()-face.Metrics().Descent.Ceil())/2
+		drawer.Dot = fixed.P(area.Min.X, baseline)
+		drawer.DrawString(text)
+		face.Close()
+	}
+	return nil
+}
+
+func measure(f *opentype.Font, text string, points float64, dpi int) (int, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: points, DPI: float64(dpi), Hinting: font.HintingFull})
+	if err != nil {
+		return 0, err
+	}
+	defer face.Close()
+	return font.MeasureString(face, text).Ceil(), nil
+}
+
+func fit(drawer *font.Drawer, text string, width int) string {
+	if drawer.MeasureString(text).Ceil() <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if drawer.MeasureString(candidate).Ceil() <= width {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Layout halaman PDF
+const (
+	// LayoutRoll satu halaman per label, untuk printer label roll
+	LayoutRoll = "roll"
+	// LayoutA4 label disusun grid di kertas A4 (lembar label stiker)
+	LayoutA4 = "a4"
+)
+
+const (
+	a4WidthMM  = 210
+	a4HeightMM = 297
+	a4MarginMM = 10
+	a4GapMM    = 2
+)
+
+// WritePNG render satu label sebagai PNG
+func WritePNG(w io.Writer, item Item, opts Options) error {
+	img, err := Render(item, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WritePDF render semua item ke satu dokumen PDF. Tiap label ditempel sebagai gambar
+// pada DPI yang diminta supaya hasilnya identik dengan PNG.
+func WritePDF(w io.Writer, items []Item, opts Options, layout string) error {
+	size := opts.Size
+	var pdf *gofpdf.Fpdf
+	columns, rows := 1, 1
+
+	switch layout {
+	case LayoutA4:
+		columns = int((a4WidthMM - 2*a4MarginMM + a4GapMM) / (size.WidthMM + a4GapMM))
+		rows = int((a4HeightMM - 2*a4MarginMM + a4GapMM) / (size.HeightMM + a4GapMM))
+		if columns < 1 || rows < 1 {
+			return fmt.Errorf("%w: %gx%g mm", ErrPageTooSmall, size.WidthMM, size.HeightMM)
+		}
+		pdf = gofpdf.New(gofpdf.OrientationPortrait, "mm", "A4", "")
+	default:
+		orientation := gofpdf.OrientationPortrait
+		if size.WidthMM > size.HeightMM {
+			orientation = gofpdf.OrientationLandscape
+		}
+		pdf = gofpdf.NewCustom(&gofpdf.InitType{
+			OrientationStr: orientation,
+			UnitStr:        "mm",
+			Size:           gofpdf.SizeType{Wd: size.WidthMM, Ht: size.HeightMM},
+		})
+	}
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+
+	perPage := columns * rows
+	// Copies label yang sama cukup dirender dan disimpan sekali di PDF
+	registered := make(map[Item]string)
+	for i, item := range items {
+		name, ok := registered[item]
+		if !ok {
+			img, err := Render(item, opts)
+			if err != nil {
+				return fmt.Errorf("label %d (%s): %w", i+1, item.Code, err)
+			}
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				return err
+			}
+			name = fmt.Sprintf("label-%d", len(registered))
+			pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, &buf)
+			registered[item] = name
+		}
+
+		slot := i % perPage
+		if slot == 0 {
+			pdf.AddPage()
+		}
+		x, y := 0.0, 0.0
+		if layout == LayoutA4 {
+			x = a4MarginMM + float64(slot%columns)*(size.WidthMM+a4GapMM)
+			y = a4MarginMM + float64(slot/columns)*(size.HeightMM+a4GapMM)
+		}
+
+		pdf.ImageOptions(name, x, y, size.WidthMM, size.HeightMM, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	if len(items) == 0 {
+		pdf.AddPage()
+	}
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
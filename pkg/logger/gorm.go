@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger adapter gorm logger.Interface ke slog.
+// Semua query di-log di level debug, query lambat (>= SlowThreshold) di level warn.
+type GormLogger struct {
+	logger        *slog.Logger
+	level         gormlogger.LogLevel
+	SlowThreshold time.Duration
+}
+
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{logger: logger, level: gormlogger.Info, SlowThreshold: slowThreshold}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.SlowThreshold > 0 && elapsed >= l.SlowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+
+	// Hindari render SQL kalau tidak akan di-log
+	if !failed && !slow && !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+
+	switch {
+	case failed && l.level >= gormlogger.Error:
+		l.logger.ErrorContext(ctx, "database query failed", append(attrs, slog.String("error", err.Error()))...)
+	case slow && l.level >= gormlogger.Warn:
+		l.logger.WarnContext(ctx, "slow database query", append(attrs, slog.Duration("threshold", l.SlowThreshold))...)
+	case l.level >= gormlogger.Info:
+		l.logger.DebugContext(ctx, "database query", attrs...)
+	}
+}
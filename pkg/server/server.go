@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// New http.Server dengan timeout eksplisit (router.Run tidak set timeout sama sekali)
+func New(cfg Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// Run listen di srv.Addr lalu block sampai ctx dibatalkan (SIGTERM/SIGINT) atau server error.
+// Setelah itu server di-drain: request in-flight diselesaikan dulu, baru cleanup dijalankan
+// berurutan (stop worker, tutup pool DB). Semua dibatasi shutdownTimeout.
+func Run(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, cleanups ...func(context.Context) error) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, srv, listener, shutdownTimeout, cleanups...)
+}
+
+// Serve sama dengan Run tapi dengan listener yang sudah dibuat
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, shutdownTimeout time.Duration, cleanups ...func(context.Context) error) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("HTTP server listening", "addr", listener.Addr().String())
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		runErr = err
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining in-flight requests", "timeout", shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if runErr != nil {
+		errs = append(errs, runErr)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	for _, cleanup := range cleanups {
+		if err := cleanup(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		slog.Info("Server stopped gracefully")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeDrainsInFlightRequest(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+	srv := New(Config{ReadHeaderTimeout: time.Second}, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cleaned := false
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, srv, listener, 5*time.Second, func(context.Context) error {
+			cleaned = true
+			return nil
+		})
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	res := <-response
+	if res.err != nil {
+		t.Fatalf("in-flight request failed during shutdown: %v", res.err)
+	}
+	if res.status != http.StatusOK || res.body != "done" {
+		t.Fatalf("got %d %q, want 200 \"done\"", res.status, res.body)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Serve returned %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after shutdown")
+	}
+	if !cleaned {
+		t.Fatal("cleanup was not run")
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	srv := New(Config{}, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- Serve(ctx, srv, listener, 50*time.Millisecond) }()
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		if err == nil {
+			t.Fatal("Serve returned nil although the request outlived the shutdown timeout")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not honour the shutdown timeout")
+	}
+}
@@ -0,0 +1,21 @@
+// Package storage menyimpan file upload (foto & dokumen toolkit) di backend yang bisa diganti:
+// filesystem lokal atau object storage S3-compatible (AWS S3, MinIO).
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound object dengan key tersebut tidak ada
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage backend penyimpanan file. Key berupa path relatif dengan "/", mis. "toolkits/12/ab12.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open caller wajib menutup reader
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete key yang sudah tidak ada bukan error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PaginationModeOffset = "offset"
+	PaginationModeCursor = "cursor"
+
+	cursorDirNext = "next"
+	cursorDirPrev = "prev"
+
+	cursorOrderAsc  = "asc"
+	cursorOrderDesc = "desc"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor menyimpan posisi keyset: kolom dan urutan sort, nilai kolom + ID baris terakhir
+type Cursor struct {
+	Column    string `json:"c"`
+	Order     string `json:"o"`
+	Kind      string `json:"k"`
+	Value     any    `json:"v"`
+	ID        int    `json:"id"`
+	Direction string `json:"d"`
+}
+
+// Matches cursor dibuat untuk sort yang sama (kolom dan urutan) dengan req
+func (c *Cursor) Matches(req CursorRequest) bool {
+	return c.Column == req.Column && c.Order == sortOrder(req.Desc)
+}
+
+func sortOrder(desc bool) string {
+	if desc {
+		return cursorOrderDesc
+	}
+	return cursorOrderAsc
+}
+
+type CursorPaginationResponse struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	TotalItems *int64 `json:"total_items,omitempty"`
+}
+
+// CursorRequest parameter keyset pagination dari request
+type CursorRequest struct {
+	Cursor   string
+	PageSize int
+	Column   string
+	Desc     bool
+}
+
+// IsCursorMode true jika request minta keyset pagination
+func IsCursorMode(mode, cursor string) bool {
+	return mode == PaginationModeCursor || cursor != ""
+}
+
+// EncodeCursor membuat cursor opaque (base64 JSON)
+func EncodeCursor(column string, desc bool, value any, id int, direction string) string {
+	c := Cursor{Column: column, Order: sortOrder(desc), ID: id, Direction: direction}
+
+	switch v := value.(type) {
+	case time.Time:
+		c.Kind, c.Value = "time", v.UTC().Format(time.RFC3339Nano)
+	case string:
+		c.Kind, c.Value = "string", v
+	case int:
+		c.Kind, c.Value = "int", v
+	case int64:
+		c.Kind, c.Value = "int", v
+	case float64:
+		c.Kind, c.Value = "float", v
+	case bool:
+		c.Kind, c.Value = "bool", v
+	default:
+		c.Kind, c.Value = "string", fmt.Sprint(v)
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor membaca cursor dan mengembalikan nilai dengan tipe aslinya
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Column == "" || (c.Direction != cursorDirNext && c.Direction != cursorDirPrev) ||
+		(c.Order != cursorOrderAsc && c.Order != cursorOrderDesc) {
+		return nil, ErrInvalidCursor
+	}
+
+	switch c.Kind {
+	case "time":
+		s, ok := c.Value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		c.Value = t
+	case "int":
+		f, ok := c.Value.(float64)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		c.Value = int64(f)
+	case "float":
+		if _, ok := c.Value.(float64); !ok {
+			return nil, ErrInvalidCursor
+		}
+	case "string":
+		if _, ok := c.Value.(string); !ok {
+			return nil, ErrInvalidCursor
+		}
+	case "bool":
+		if _, ok := c.Value.(bool); !ok {
+			return nil, ErrInvalidCursor
+		}
+	default:
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// KeysetPaginate scope untuk GORM, ambil pageSize+1 baris supaya bisa tahu masih ada halaman berikutnya.
+// Kolom sudah harus divalidasi oleh repository (whitelist), jangan pernah dari input mentah.
+func KeysetPaginate(req CursorRequest, cursor *Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		pageSize := normalizePageSize(req.PageSize)
+
+		// Arah scan dibalik untuk prev page, hasil dibalik lagi di BuildCursorPage
+		desc := req.Desc
+		if cursor != nil && cursor.Direction == cursorDirPrev {
+			desc = !desc
+		}
+
+		if cursor != nil {
+			op := ">"
+			if desc {
+				op = "<"
+			}
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", req.Column, op), cursor.Value, cursor.ID)
+		}
+
+		dir := "ASC"
+		if desc {
+			dir = "DESC"
+		}
+		return db.Order(fmt.Sprintf("%s %s, id %s", req.Column, dir, dir)).Limit(pageSize + 1)
+	}
+}
+
+// BuildCursorPage memotong hasil query keyset dan membuat next/prev cursor
+func BuildCursorPage[T any](items []T, req CursorRequest, cursor *Cursor, key func(T) (any, int)) ([]T, CursorPaginationResponse) {
+	pageSize := normalizePageSize(req.PageSize)
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+
+	backward := cursor != nil && cursor.Direction == cursorDirPrev
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	resp := CursorPaginationResponse{PageSize: pageSize}
+	if backward {
+		resp.HasPrev = hasMore
+		resp.HasNext = true
+	} else {
+		resp.HasNext = hasMore
+		resp.HasPrev = cursor != nil
+	}
+
+	if len(items) > 0 {
+		if resp.HasNext {
+			v, id := key(items[len(items)-1])
+			resp.NextCursor = EncodeCursor(req.Column, req.Desc, v, id, cursorDirNext)
+		}
+		if resp.HasPrev {
+			v, id := key(items[0])
+			resp.PrevCursor = EncodeCursor(req.Column, req.Desc, v, id, cursorDirPrev)
+		}
+	}
+
+	return items, resp
+}
+
+// ResolveSort memilih kolom sort dari whitelist, fallback ke defaultColumn
+func ResolveSort(sortBy, sortOrder string, allowed map[string]string, defaultColumn string) (string, bool) {
+	column, ok := allowed[sortBy]
+	if !ok {
+		column = defaultColumn
+	}
+	return column, sortOrder == "desc"
+}
+
+func normalizePageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return 10
+	}
+	return pageSize
+}
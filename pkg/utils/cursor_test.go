@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 17, 8, 30, 0, 123456789, time.FixedZone("WIB", 7*3600))
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "time", value: at, want: at.UTC()},
+		{name: "string", value: "Obeng plus", want: "Obeng plus"},
+		{name: "int", value: 42, want: int64(42)},
+		{name: "int64", value: int64(1) << 40, want: int64(1) << 40},
+		{name: "float", value: 12.5, want: 12.5},
+		{name: "bool", value: true, want: true},
+	}
+	for _, tt := range tests {
+		for _, direction := range []string{cursorDirNext, cursorDirPrev} {
+			t.Run(tt.name+"/"+direction, func(t *testing.T) {
+				cursor, err := DecodeCursor(EncodeCursor("created_at", true, tt.value, 7, direction))
+				if err != nil {
+					t.Fatalf("DecodeCursor: %v", err)
+				}
+				if cursor.Column != "created_at" || cursor.Order != "desc" || cursor.ID != 7 || cursor.Direction != direction {
+					t.Errorf("cursor = %+v", cursor)
+				}
+				if !reflect.DeepEqual(cursor.Value, tt.want) {
+					t.Errorf("value = %#v, want %#v", cursor.Value, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeCursorRejectsTampered(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	valid := EncodeCursor("name", false, "Tang", 3, cursorDirNext)
+
+	tests := map[string]string{
+		"empty":             "",
+		"not base64":        "%%%",
+		"truncated":         valid[:len(valid)-4],
+		"not json":          encode("name,Tang,3"),
+		"missing column":    encode(`{"o":"asc","k":"string","v":"x","id":1,"d":"next"}`),
+		"unknown direction": encode(`{"c":"name","o":"asc","k":"string","v":"x","id":1,"d":"sideways"}`),
+		"unknown kind":      encode(`{"c":"name","o":"asc","k":"uuid","v":"x","id":1,"d":"next"}`),
+		"time not a string": encode(`{"c":"created_at","o":"asc","k":"time","v":12,"id":1,"d":"next"}`),
+		"time not RFC3339":  encode(`{"c":"created_at","o":"asc","k":"time","v":"yesterday","id":1,"d":"next"}`),
+		"int as string":     encode(`{"c":"quantity","o":"asc","k":"int","v":"1 OR 1=1","id":1,"d":"next"}`),
+		"float as bool":     encode(`{"c":"price","o":"asc","k":"float","v":true,"id":1,"d":"next"}`),
+		"string as object":  encode(`{"c":"name","o":"asc","k":"string","v":{"a":1},"id":1,"d":"next"}`),
+		"bool as number":    encode(`{"c":"is_active","o":"asc","k":"bool","v":1,"id":1,"d":"next"}`),
+		"id not a number":   encode(`{"c":"name","o":"asc","k":"string","v":"x","id":"1","d":"next"}`),
+		"missing direction": encode(`{"c":"name","o":"asc","k":"string","v":"x","id":1}`),
+		"missing order":     encode(`{"c":"name","k":"string","v":"x","id":1,"d":"next"}`),
+		"unknown order":     encode(`{"c":"name","o":"random","k":"string","v":"x","id":1,"d":"next"}`),
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestCursorMatchesSortOrder(t *testing.T) {
+	cursor, err := DecodeCursor(EncodeCursor("name", false, "Tang", 3, cursorDirNext))
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	tests := []struct {
+		name string
+		req  CursorRequest
+		want bool
+	}{
+		{name: "same column and order", req: CursorRequest{Column: "name"}, want: true},
+		{name: "other column", req: CursorRequest{Column: "created_at"}, want: false},
+		{name: "asc cursor replayed on desc", req: CursorRequest{Column: "name", Desc: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cursor.Matches(tt.req); got != tt.want {
+				t.Errorf("Matches(%+v) = %v, want %v", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+func dryRunDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db
+}
+
+type pageItem struct {
+	ID   int
+	Name string
+}
+
+func TestKeysetPaginateSQL(t *testing.T) {
+	db := dryRunDB(t)
+	req := CursorRequest{PageSize: 20, Column: "name"}
+
+	tests := []struct {
+		name   string
+		desc   bool
+		cursor *Cursor
+		want   string
+	}{
+		// pageSize+1 baris untuk mengetahui ada halaman berikutnya
+		{name: "first page", want: `ORDER BY name ASC, id ASC LIMIT $1`},
+		{name: "next", cursor: &Cursor{Value: "m", ID: 9, Direction: cursorDirNext},
+			want: `WHERE (name, id) > ($1, $2) ORDER BY name ASC, id ASC LIMIT $3`},
+		{name: "prev scans backwards", cursor: &Cursor{Value: "m", ID: 9, Direction: cursorDirPrev},
+			want: `WHERE (name, id) < ($1, $2) ORDER BY name DESC, id DESC LIMIT $3`},
+		{name: "desc next", desc: true, cursor: &Cursor{Value: "m", ID: 9, Direction: cursorDirNext},
+			want: `WHERE (name, id) < ($1, $2) ORDER BY name DESC, id DESC LIMIT $3`},
+		{name: "desc prev", desc: true, cursor: &Cursor{Value: "m", ID: 9, Direction: cursorDirPrev},
+			want: `WHERE (name, id) > ($1, $2) ORDER BY name ASC, id ASC LIMIT $3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := req
+			r.Desc = tt.desc
+			stmt := db.Scopes(KeysetPaginate(r, tt.cursor)).Find(&[]pageItem{}).Statement
+			if sql := stmt.SQL.String(); !strings.HasSuffix(sql, tt.want) {
+				t.Errorf("sql = %s\nwant suffix %s", sql, tt.want)
+			}
+			if tt.cursor != nil && !reflect.DeepEqual(stmt.Vars, []any{"m", 9, 21}) {
+				t.Errorf("vars = %v", stmt.Vars)
+			}
+		})
+	}
+}
+
+// keysetPage jalankan logika KeysetPaginate pada slice yang sudah urut (name, id) ASC
+func keysetPage(rows []pageItem, req CursorRequest, cursor *Cursor) []pageItem {
+	after := func(row pageItem) bool {
+		value := cursor.Value.(string)
+		return row.Name > value || (row.Name == value && row.ID > cursor.ID)
+	}
+	var page []pageItem
+	if cursor == nil || cursor.Direction == cursorDirNext {
+		for _, row := range rows {
+			if cursor == nil || after(row) {
+				page = append(page, row)
+			}
+		}
+	} else {
+		for i := len(rows) - 1; i >= 0; i-- {
+			if row := rows[i]; !after(row) && (row.Name != cursor.Value.(string) || row.ID != cursor.ID) {
+				page = append(page, row)
+			}
+		}
+	}
+	return page[:min(len(page), req.PageSize+1)]
+}
+
+func TestBuildCursorPageBoundaries(t *testing.T) {
+	var rows []pageItem
+	for i := 1; i <= 7; i++ {
+		// Nama kembar supaya id ikut menentukan urutan
+		rows = append(rows, pageItem{ID: i, Name: fmt.Sprintf("item-%d", (i+1)/2)})
+	}
+	req := CursorRequest{PageSize: 3, Column: "name"}
+	key := func(item pageItem) (any, int) { return item.Name, item.ID }
+
+	page := func(token string) ([]pageItem, CursorPaginationResponse) {
+		t.Helper()
+		var cursor *Cursor
+		if token != "" {
+			var err error
+			if cursor, err = DecodeCursor(token); err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+		}
+		return BuildCursorPage(keysetPage(rows, req, cursor), req, cursor, key)
+	}
+	ids := func(items []pageItem) []int {
+		out := make([]int, len(items))
+		for i, item := range items {
+			out[i] = item.ID
+		}
+		return out
+	}
+
+	first, resp := page("")
+	if !reflect.DeepEqual(ids(first), []int{1, 2, 3}) || resp.HasPrev || !resp.HasNext || resp.PrevCursor != "" {
+		t.Fatalf("first page = %v %+v", ids(first), resp)
+	}
+
+	second, resp := page(resp.NextCursor)
+	if !reflect.DeepEqual(ids(second), []int{4, 5, 6}) || !resp.HasPrev || !resp.HasNext {
+		t.Fatalf("second page = %v %+v", ids(second), resp)
+	}
+	prevCursor := resp.PrevCursor
+
+	last, resp := page(resp.NextCursor)
+	if !reflect.DeepEqual(ids(last), []int{7}) || !resp.HasPrev || resp.HasNext || resp.NextCursor != "" {
+		t.Fatalf("last page = %v %+v", ids(last), resp)
+	}
+
+	back, resp := page(resp.PrevCursor)
+	if !reflect.DeepEqual(ids(back), []int{4, 5, 6}) || !resp.HasPrev || !resp.HasNext {
+		t.Fatalf("back from last page = %v %+v", ids(back), resp)
+	}
+
+	start, resp := page(prevCursor)
+	if !reflect.DeepEqual(ids(start), []int{1, 2, 3}) || resp.HasPrev || resp.PrevCursor != "" || !resp.HasNext {
+		t.Fatalf("back to first page = %v %+v", ids(start), resp)
+	}
+}
+
+func TestBuildCursorPageEmpty(t *testing.T) {
+	items, resp := BuildCursorPage([]pageItem{}, CursorRequest{Column: "name"}, nil,
+		func(item pageItem) (any, int) { return item.Name, item.ID })
+	if len(items) != 0 || resp.HasNext || resp.HasPrev || resp.NextCursor != "" || resp.PageSize != 10 {
+		t.Errorf("items = %v, resp = %+v", items, resp)
+	}
+}
+
+const (
+	benchRows     = 200_000
+	benchPageSize = 50
+)
+
+type benchItem struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+func (benchItem) TableName() string { return "bench_pagination_items" }
+
+// benchDB tabel contoh di database yang ditunjuk BENCH_DATABASE_DSN, mis.
+//
+//	BENCH_DATABASE_DSN="host=localhost user=postgres dbname=toolkit_bench sslmode=disable" \
+//		go test ./pkg/utils -run '^$' -bench Pagination
+func benchDB(b *testing.B) *gorm.DB {
+	b.Helper()
+	dsn := os.Getenv("BENCH_DATABASE_DSN")
+	if dsn == "" {
+		b.Skip("BENCH_DATABASE_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		b.Fatalf("gorm.Open: %v", err)
+	}
+
+	statements := []string{
+		`DROP TABLE IF EXISTS bench_pagination_items`,
+		`CREATE TABLE bench_pagination_items (id serial PRIMARY KEY, name text NOT NULL, created_at timestamptz NOT NULL)`,
+		fmt.Sprintf(`INSERT INTO bench_pagination_items (name, created_at)
+			SELECT 'item ' || g, now() - g * interval '1 minute' FROM generate_series(1, %d) g`, benchRows),
+		`CREATE INDEX ON bench_pagination_items (created_at, id)`,
+		`ANALYZE bench_pagination_items`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			b.Fatalf("seed: %v", err)
+		}
+	}
+	b.Cleanup(func() { db.Exec(`DROP TABLE IF EXISTS bench_pagination_items`) })
+	return db
+}
+
+// BenchmarkPagination halaman yang sama lewat OFFSET dan lewat keyset. Biaya OFFSET naik
+// sebanding dengan dalamnya halaman, keyset tetap karena langsung mulai dari index.
+func BenchmarkPagination(b *testing.B) {
+	db := benchDB(b)
+	req := CursorRequest{PageSize: benchPageSize, Column: "created_at", Desc: true}
+
+	for _, page := range []int{1, 100, 1000, benchRows / benchPageSize} {
+		b.Run(fmt.Sprintf("offset/page=%d", page), func(b *testing.B) {
+			for range b.N {
+				var items []benchItem
+				if err := db.Order("created_at DESC, id DESC").Scopes(Paginate(page, benchPageSize)).Find(&items).Error; err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		// Cursor yang menunjuk baris terakhir halaman sebelumnya, seperti next_cursor
+		var cursor *Cursor
+		if page > 1 {
+			var last benchItem
+			if err := db.Order("created_at DESC, id DESC").Offset((page-1)*benchPageSize - 1).First(&last).Error; err != nil {
+				b.Fatal(err)
+			}
+			decoded, err := DecodeCursor(EncodeCursor(req.Column, req.Desc, last.CreatedAt, last.ID, cursorDirNext))
+			if err != nil {
+				b.Fatal(err)
+			}
+			cursor = decoded
+		}
+		b.Run(fmt.Sprintf("keyset/page=%d", page), func(b *testing.B) {
+			for range b.N {
+				var items []benchItem
+				if err := db.Scopes(KeysetPaginate(req, cursor)).Find(&items).Error; err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
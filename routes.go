@@ -0,0 +1,305 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/config"
+	"toolkit-management/internal/handlers"
+	"toolkit-management/internal/middleware"
+	"toolkit-management/internal/openapi"
+	"toolkit-management/pkg/auth"
+)
+
+// routeDeps handler dan middleware yang dipakai registerRoutes
+type routeDeps struct {
+	authService *auth.AuthService
+	rateLimiter *middleware.RateLimiter
+	metrics     http.Handler
+
+	userHandler        *handlers.UserHandler
+	toolkitHandler     *handlers.ToolkitHandler
+	categoryHandler    *handlers.CategoryHandler
+	loanHandler        *handlers.LoanHandler
+	maintenanceHandler *handlers.MaintenanceHandler
+	inspectionHandler  *handlers.InspectionHandler
+	incidentHandler    *handlers.IncidentHandler
+	reportHandler      *handlers.ReportHandler
+	attachmentHandler  *handlers.AttachmentHandler
+	labelHandler       *handlers.LabelHandler
+	bundleHandler      *handlers.BundleHandler
+	locationHandler    *handlers.LocationHandler
+	transferHandler    *handlers.TransferHandler
+	scanHandler        *handlers.ScanHandler
+	searchHandler      *handlers.SearchHandler
+	apiTokenHandler    *handlers.APITokenHandler
+	twoFactorHandler   *handlers.TwoFactorHandler
+	healthHandler      *handlers.HealthHandler
+	// oidcHandler nil kalau SSO tidak aktif
+	oidcHandler *handlers.OIDCHandler
+}
+
+// registerRoutes daftarkan semua route API. Setiap route di sini harus ada di spec OpenAPI
+// (lihat TestRoutesDocumented).
+func registerRoutes(router *gin.Engine, d routeDeps) {
+	router.GET("/metrics", gin.WrapH(d.metrics))
+	router.GET("/livez", d.healthHandler.Liveness)
+	router.GET("/readyz", d.healthHandler.Readiness)
+
+	// Define route
+	api := router.Group("/api")
+	{
+		// Public routes
+		api.GET("/health", d.healthHandler.Readiness)
+		api.POST("/auth/login", d.rateLimiter.Group(config.RateLimitGroupAuth), d.userHandler.Login)
+		api.POST("/auth/2fa/verify", d.rateLimiter.Group(config.RateLimitGroupAuth), d.twoFactorHandler.Verify)
+		if d.oidcHandler != nil {
+			api.GET("/auth/oidc/login", d.rateLimiter.Group(config.RateLimitGroupAuth), d.oidcHandler.Login)
+			api.GET("/auth/oidc/callback", d.rateLimiter.Group(config.RateLimitGroupAuth), d.oidcHandler.Callback)
+		}
+		api.GET("/openapi.json", openapi.SpecHandler)
+		api.GET("/docs", openapi.DocsHandler)
+
+		// Enrolment 2FA: sesi biasa, atau token setup dari login kalau 2FA wajib untuk role user
+		twoFactorSetup := api.Group("/auth/2fa")
+		twoFactorSetup.Use(d.authService.RequireAuth(auth.PurposeTwoFactorSetup), d.rateLimiter.Group(config.RateLimitGroupAPI))
+		{
+			twoFactorSetup.POST("/setup", d.twoFactorHandler.Setup)
+			twoFactorSetup.POST("/enable", d.twoFactorHandler.Enable)
+		}
+
+		// Protected routes
+		protected := api.Group("")
+		protected.Use(d.authService.RequireAuth(), d.rateLimiter.Group(config.RateLimitGroupAPI))
+		{
+			// Current user
+			protected.GET("/auth/me", d.userHandler.GetCurrentUser)
+
+			// Personal API tokens
+			protected.POST("/auth/tokens", d.apiTokenHandler.Create)
+			protected.GET("/auth/tokens", d.apiTokenHandler.List)
+			protected.DELETE("/auth/tokens/:id", d.apiTokenHandler.Revoke)
+
+			// Two-factor authentication
+			protected.GET("/auth/2fa", d.twoFactorHandler.Status)
+			protected.POST("/auth/2fa/disable", d.twoFactorHandler.Disable)
+
+			// Global search
+			protected.GET("/search", d.rateLimiter.Group(config.RateLimitGroupSearch), d.searchHandler.Search)
+
+			// Detailed health - Admin only
+			protected.GET("/admin/health", d.authService.RequireAdmin(), d.healthHandler.Detail)
+
+			// User routes - Admin only
+			users := protected.Group("/users")
+			users.Use(d.authService.RequireAdmin())
+			{
+				users.POST("", d.userHandler.Create)
+				users.GET("", d.userHandler.GetAll)
+				users.POST("/search", d.rateLimiter.Group(config.RateLimitGroupSearch), d.userHandler.GetAll)
+				users.GET("/:id", d.userHandler.GetByID)
+				users.PUT("/:id", d.userHandler.Update)
+				users.DELETE("/:id", d.userHandler.Delete)
+			}
+
+			// Toolkit routes - Admin & user
+			toolkits := protected.Group("/toolkits")
+			{
+				// Admin only
+				toolkitsAdmin := toolkits.Group("")
+				toolkitsAdmin.Use(d.authService.RequireAdmin())
+				{
+					toolkitsAdmin.POST("", d.toolkitHandler.Create)
+					toolkitsAdmin.PUT("/:id", d.toolkitHandler.Update)
+					toolkitsAdmin.DELETE("/:id", d.toolkitHandler.Delete)
+					toolkitsAdmin.PATCH("/:id/stock", d.toolkitHandler.UpdateStock)
+					toolkitsAdmin.GET("/:id/stock-adjustments", d.incidentHandler.GetAdjustments)
+					toolkitsAdmin.POST("/:id/attachments", d.attachmentHandler.Upload)
+				}
+
+				// All authenticated users
+				toolkits.GET("", d.toolkitHandler.GetAll)
+				toolkits.POST("/search", d.rateLimiter.Group(config.RateLimitGroupSearch), d.toolkitHandler.GetAll)
+				toolkits.GET("/:id", d.toolkitHandler.GetByID)
+				toolkits.GET("/:id/attachments", d.attachmentHandler.GetByToolkit)
+				toolkits.GET("/:id/availability", d.locationHandler.ToolkitAvailability)
+
+				// Label cetak - Admin & technician
+				toolkits.GET("/:id/label", d.authService.RequireStaff(), d.labelHandler.Toolkit)
+				toolkits.POST("/labels", d.authService.RequireStaff(), d.labelHandler.Batch)
+			}
+
+			// Foto & dokumen toolkit
+			attachments := protected.Group("/attachments")
+			{
+				attachments.GET("/:id", d.attachmentHandler.GetByID)
+				attachments.GET("/:id/content", d.attachmentHandler.Content)
+				attachments.DELETE("/:id", d.authService.RequireAdmin(), d.attachmentHandler.Delete)
+			}
+
+			// Category routes - Admin only
+			categories := protected.Group("/categories")
+			categories.Use(d.authService.RequireAdmin())
+			{
+				categories.POST("", d.categoryHandler.Create)
+				categories.GET("", d.categoryHandler.GetAll)
+				categories.GET("/:id", d.categoryHandler.GetByID)
+				categories.PUT("/:id", d.categoryHandler.Update)
+				categories.DELETE("/:id", d.categoryHandler.Delete)
+				categories.GET("/tree", d.categoryHandler.GetTree)
+				categories.POST("/:id/checklist", d.inspectionHandler.CreateChecklistItem)
+			}
+
+			// Checklist dibaca semua user yang melakukan inspeksi
+			protected.GET("/categories/:id/checklist", d.inspectionHandler.GetChecklist)
+
+			checklistItems := protected.Group("/checklist-items")
+			checklistItems.Use(d.authService.RequireAdmin())
+			{
+				checklistItems.PUT("/:id", d.inspectionHandler.UpdateChecklistItem)
+				checklistItems.DELETE("/:id", d.inspectionHandler.DeleteChecklistItem)
+			}
+
+			// Loan routes
+			loans := protected.Group("/loans")
+			{
+				loans.POST("", d.loanHandler.Create)
+				loans.GET("", d.loanHandler.GetAll)
+				loans.GET("/:id", d.loanHandler.GetByID)
+				loans.PUT("/:id", d.loanHandler.Update)
+				loans.DELETE("/:id", d.loanHandler.Delete)
+				loans.POST("/:id/inspections", d.inspectionHandler.Create)
+				loans.GET("/:id/inspections", d.inspectionHandler.GetByLoan)
+				loans.POST("/:id/incidents", d.incidentHandler.Create)
+			}
+
+			// Bundle (kit beberapa toolkit) - definisi dikelola admin, checkout seperti loan biasa
+			bundles := protected.Group("/bundles")
+			{
+				bundles.GET("", d.bundleHandler.GetAll)
+				bundles.GET("/:id", d.bundleHandler.GetByID)
+				bundles.POST("/:id/checkout", d.bundleHandler.Checkout)
+
+				bundlesAdmin := bundles.Group("")
+				bundlesAdmin.Use(d.authService.RequireAdmin())
+				{
+					bundlesAdmin.POST("", d.bundleHandler.Create)
+					bundlesAdmin.PUT("/:id", d.bundleHandler.Update)
+					bundlesAdmin.DELETE("/:id", d.bundleHandler.Delete)
+				}
+			}
+
+			bundleLoans := protected.Group("/bundle-loans")
+			{
+				bundleLoans.GET("", d.bundleHandler.GetLoans)
+				bundleLoans.GET("/:id", d.bundleHandler.GetLoanByID)
+				bundleLoans.POST("/:id/return", d.bundleHandler.Return)
+			}
+
+			// Lokasi gudang/rak/bin dan stok per lokasi - struktur & penempatan dikelola admin
+			locations := protected.Group("/locations")
+			{
+				locations.GET("", d.locationHandler.GetAll)
+				locations.GET("/:id", d.locationHandler.GetByID)
+				locations.GET("/:id/stock", d.locationHandler.Stock)
+
+				locationsAdmin := locations.Group("")
+				locationsAdmin.Use(d.authService.RequireAdmin())
+				{
+					locationsAdmin.POST("", d.locationHandler.Create)
+					locationsAdmin.PUT("/:id", d.locationHandler.Update)
+					locationsAdmin.DELETE("/:id", d.locationHandler.Delete)
+					locationsAdmin.PUT("/:id/stock/:toolkit_id", d.locationHandler.SetStock)
+				}
+			}
+
+			// Transfer stok antar lokasi - dikirim & diterima oleh admin/technician
+			transfers := protected.Group("/transfers")
+			{
+				transfers.GET("", d.transferHandler.GetAll)
+				transfers.GET("/:id", d.transferHandler.GetByID)
+
+				transfersStaff := transfers.Group("")
+				transfersStaff.Use(d.authService.RequireStaff())
+				{
+					transfersStaff.POST("", d.transferHandler.Create)
+					transfersStaff.PUT("/:id", d.transferHandler.Update)
+					transfersStaff.DELETE("/:id", d.transferHandler.Delete)
+					transfersStaff.POST("/:id/dispatch", d.transferHandler.Dispatch)
+					transfersStaff.POST("/:id/receive", d.transferHandler.Receive)
+				}
+			}
+
+			// Checkout & return dari handheld scanner - Admin & technician
+			scan := protected.Group("/scan")
+			scan.Use(d.authService.RequireStaff())
+			{
+				scan.POST("/checkout", d.scanHandler.Checkout)
+				scan.POST("/return", d.scanHandler.Return)
+			}
+
+			// Damage & loss incidents
+			incidents := protected.Group("/incidents")
+			{
+				// Staff, pelapor atau peminjam, dicek di service
+				incidents.POST("/:id/evidence", d.incidentHandler.AddEvidence)
+
+				incidentsStaff := incidents.Group("")
+				incidentsStaff.Use(d.authService.RequireStaff())
+				{
+					incidentsStaff.GET("", d.incidentHandler.GetAll)
+					incidentsStaff.GET("/:id", d.incidentHandler.GetByID)
+					incidentsStaff.POST("/:id/assess", d.incidentHandler.Assess)
+				}
+
+				// Resolusi berdampak ke stok dan biaya - Admin only
+				incidents.POST("/:id/resolve", d.authService.RequireAdmin(), d.incidentHandler.Resolve)
+			}
+
+			// Reports - Admin only
+			reports := protected.Group("/reports")
+			reports.Use(d.authService.RequireAdmin())
+			{
+				reports.GET("/valuation", d.reportHandler.Valuation)
+			}
+
+			// Inspection review - Admin & technician
+			inspections := protected.Group("/inspections")
+			inspections.Use(d.authService.RequireStaff())
+			{
+				inspections.GET("", d.inspectionHandler.GetAll)
+				inspections.GET("/:id", d.inspectionHandler.GetByID)
+				inspections.POST("/:id/review", d.inspectionHandler.Review)
+			}
+
+			// Maintenance routes - Admin & technician
+			toolkitsMaintenance := protected.Group("/toolkits/:id")
+			toolkitsMaintenance.Use(d.authService.RequireStaff())
+			{
+				toolkitsMaintenance.POST("/maintenance", d.maintenanceHandler.Create)
+				toolkitsMaintenance.GET("/maintenance", d.maintenanceHandler.GetByToolkit)
+				toolkitsMaintenance.POST("/maintenance-schedules", d.maintenanceHandler.CreateSchedule)
+				toolkitsMaintenance.GET("/maintenance-schedules", d.maintenanceHandler.GetSchedules)
+			}
+
+			maintenance := protected.Group("/maintenance")
+			maintenance.Use(d.authService.RequireStaff())
+			{
+				maintenance.GET("", d.maintenanceHandler.GetAll)
+				maintenance.GET("/due", d.maintenanceHandler.Due)
+				maintenance.GET("/:id", d.maintenanceHandler.GetByID)
+				maintenance.POST("/:id/start", d.maintenanceHandler.Start)
+				maintenance.POST("/:id/complete", d.maintenanceHandler.Complete)
+				maintenance.POST("/:id/cancel", d.maintenanceHandler.Cancel)
+			}
+
+			maintenanceSchedules := protected.Group("/maintenance-schedules")
+			maintenanceSchedules.Use(d.authService.RequireStaff())
+			{
+				maintenanceSchedules.PUT("/:id", d.maintenanceHandler.UpdateSchedule)
+				maintenanceSchedules.DELETE("/:id", d.maintenanceHandler.DeleteSchedule)
+			}
+		}
+	}
+}
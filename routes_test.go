@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-management/internal/handlers"
+	"toolkit-management/internal/metrics"
+	"toolkit-management/internal/middleware"
+	"toolkit-management/internal/openapi"
+	"toolkit-management/internal/ratelimit"
+	"toolkit-management/pkg/auth"
+)
+
+// TestRoutesDocumented setiap route yang didaftarkan harus ada di spec OpenAPI
+func TestRoutesDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerRoutes(router, routeDeps{
+		authService: auth.NewAuthService(auth.AuthConfig{SecretKey: "test"}),
+		rateLimiter: middleware.NewRateLimiter(ratelimit.NewMemoryStore(), false),
+		metrics:     metrics.New().Handler(),
+		// Handler tidak dipanggil, cukup untuk mendaftarkan route; OIDC ikut didaftarkan
+		oidcHandler: &handlers.OIDCHandler{},
+	})
+
+	if len(router.Routes()) == 0 {
+		t.Fatal("no routes registered")
+	}
+	if missing := openapi.MissingRoutes(router.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from OpenAPI spec:\n%v", missing)
+	}
+}